@@ -1,6 +1,7 @@
 package main
 
 import (
+	"database/sql"
 	"errors"
 	"flag"
 	"fmt"
@@ -9,18 +10,24 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	// Драйвер для выполнения миграций PostgreSQL
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	// Драйвер для выполнения миграций SQLite
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite3"
 	// Драйвер для получения миграций из файлов
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"github.com/orenvadi/auth-grpc/internal/storage/sqlite"
 )
 
 func main() {
 	var (
+		storageDriver   string
 		storageDSN      string
 		migrationsPath  string
 		migrationsTable string
 	)
 
-	flag.StringVar(&storageDSN, "storage-dsn", "", "PostgreSQL DSN")
+	flag.StringVar(&storageDriver, "storage-driver", "postgres", "postgres or sqlite")
+	flag.StringVar(&storageDSN, "storage-dsn", "", "PostgreSQL DSN or SQLite database file")
 	flag.StringVar(&migrationsPath, "migrations-path", "", "path to migrations")
 	flag.StringVar(&migrationsTable, "migrations-table", "migrations", "name of migrations table")
 	flag.Parse()
@@ -32,10 +39,21 @@ func main() {
 		panic("migrations-path is required")
 	}
 
-	m, err := migrate.New(
-		"file://"+migrationsPath,
-		fmt.Sprintf("postgres://%s?x-migrations-table=%s&sslmode=disable", storageDSN, migrationsTable),
+	var (
+		m   *migrate.Migrate
+		err error
 	)
+	switch storageDriver {
+	case "postgres":
+		m, err = migrate.New(
+			"file://"+migrationsPath,
+			fmt.Sprintf("postgres://%s?x-migrations-table=%s&sslmode=disable", storageDSN, migrationsTable),
+		)
+	case "sqlite":
+		m, err = newSQLiteMigrate(storageDSN, migrationsPath, migrationsTable)
+	default:
+		err = fmt.Errorf("unknown storage driver %q", storageDriver)
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -52,3 +70,19 @@ func main() {
 
 	fmt.Println("migrations applied")
 }
+
+// newSQLiteMigrate migrates through the driver of the storage, the
+// migrations use the functions it registers.
+func newSQLiteMigrate(path, migrationsPath, migrationsTable string) (*migrate.Migrate, error) {
+	db, err := sql.Open(sqlite.DriverName, path)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{MigrationsTable: migrationsTable})
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.NewWithDatabaseInstance("file://"+migrationsPath, "sqlite3", driver)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/app"
+	"github.com/orenvadi/auth-grpc/internal/config"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/services/admin"
+)
+
+const appsUsage = `usage:
+  sso apps create -name name [-public] [-redirect-uris uris] [-grant-types types] [-scopes scopes] [-token-ttl ttl] [-logo-uri uri]
+  sso apps list
+  sso apps update -id id [-name name] [-redirect-uris uris] [-grant-types types] [-scopes scopes] [-token-ttl ttl] [-logo-uri uri]
+  sso apps rotate-secret -id id
+  sso apps delete -id id
+  sso apps drop-plain-secrets -yes
+
+lists are comma separated`
+
+// cliAdminID is logged as the admin of changes made with the CLI, which
+// has direct access to the database instead of an admin token.
+const cliAdminID = 0
+
+// appsCommand manages apps through the admin service, like AdminService does.
+func appsCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(appsUsage)
+	}
+
+	cfg := config.MustLoad()
+
+	storage, err := app.NewStorage(cfg.Storage, cfg.Signing.Alg)
+	if err != nil {
+		return err
+	}
+	defer storage.Stop()
+
+	// stdout is left to the output of the command
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	service := admin.New(log, storage, storage, cfg.Signing.Alg == jwtn.AlgHS256)
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		return createApp(ctx, service, args[1:])
+	case "list":
+		return listApps(ctx, service)
+	case "update":
+		return updateApp(ctx, service, args[1:])
+	case "rotate-secret":
+		return rotateAppSecret(ctx, service, args[1:])
+	case "delete":
+		return deleteApp(ctx, service, args[1:])
+	case "drop-plain-secrets":
+		return dropPlainAppSecrets(ctx, service, cfg.Signing.LegacyHS256Until, args[1:])
+	}
+
+	return errors.New(appsUsage)
+}
+
+// appFlags binds the flags of the app metadata to app, so flags that are
+// not set keep its values.
+func appFlags(fs *flag.FlagSet, app *models.App) {
+	fs.StringVar(&app.Name, "name", app.Name, "name of the app")
+	fs.Func("redirect-uris", "redirect URIs of the authorization code flow", func(s string) error {
+		app.RedirectURIs = splitList(s)
+		return nil
+	})
+	fs.Func("grant-types", "allowed grant types: "+strings.Join(admin.GrantTypes, ", "), func(s string) error {
+		app.GrantTypes = strings.Join(splitList(s), " ")
+		return nil
+	})
+	fs.Func("scopes", "scopes of the client credentials grant", func(s string) error {
+		app.Scopes = splitList(s)
+		return nil
+	})
+	fs.Func("token-ttl", "access token TTL of the app, e.g. 15m, 0 keeps the default", func(s string) error {
+		ttl, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		app.TokenTTLSeconds = int64(ttl.Seconds())
+		return nil
+	})
+	fs.StringVar(&app.LogoURI, "logo-uri", app.LogoURI, "logo shown on the login page")
+}
+
+func createApp(ctx context.Context, service *admin.Service, args []string) error {
+	var app models.App
+
+	fs := flag.NewFlagSet("apps create", flag.ContinueOnError)
+	appFlags(fs, &app)
+	fs.BoolVar(&app.Public, "public", false, "the app can not keep a secret, e.g. a SPA or a mobile app, and authenticates with PKCE only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, secret, err := service.CreateApp(ctx, cliAdminID, app)
+	if err != nil {
+		return err
+	}
+
+	printApp(app)
+	if !app.Public {
+		fmt.Printf("secret:        %s\n\nthe secret is stored hashed, save it now\n", secret)
+	}
+
+	return nil
+}
+
+func listApps(ctx context.Context, service *admin.Service) error {
+	apps, err := service.ListApps(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tGRANT TYPES\tTOKEN TTL\tREDIRECT URIS")
+	for _, app := range apps {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			app.ID,
+			app.Name,
+			strings.ReplaceAll(app.GrantTypes, " ", ","),
+			tokenTTL(app),
+			strings.Join(app.RedirectURIs, ","),
+		)
+	}
+
+	return w.Flush()
+}
+
+func updateApp(ctx context.Context, service *admin.Service, args []string) error {
+	appID, rest, err := parseAppID("apps update", args)
+	if err != nil {
+		return err
+	}
+
+	app, err := service.App(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("apps update", flag.ContinueOnError)
+	appFlags(fs, &app)
+	if err = fs.Parse(rest); err != nil {
+		return err
+	}
+
+	app, err = service.UpdateApp(ctx, cliAdminID, app)
+	if err != nil {
+		return err
+	}
+
+	printApp(app)
+
+	return nil
+}
+
+func rotateAppSecret(ctx context.Context, service *admin.Service, args []string) error {
+	appID, _, err := parseAppID("apps rotate-secret", args)
+	if err != nil {
+		return err
+	}
+
+	secret, err := service.RotateAppSecret(ctx, cliAdminID, appID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("secret: %s\n\nthe secret is stored hashed, save it now\n", secret)
+
+	return nil
+}
+
+func deleteApp(ctx context.Context, service *admin.Service, args []string) error {
+	appID, _, err := parseAppID("apps delete", args)
+	if err != nil {
+		return err
+	}
+
+	return service.DeleteApp(ctx, cliAdminID, appID)
+}
+
+// dropPlainAppSecrets drops the plain app secrets once no token is signed
+// or verified with them anymore. It can not be undone, so it asks for -yes.
+func dropPlainAppSecrets(ctx context.Context, service *admin.Service, legacyHS256Until time.Time, args []string) error {
+	fs := flag.NewFlagSet("apps drop-plain-secrets", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "drop the secrets, they can not be restored")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if jwtn.LegacyAccepted(legacyHS256Until) {
+		return fmt.Errorf("legacy HS256 tokens are verified with the app secrets until %s", legacyHS256Until.Format(time.RFC3339))
+	}
+	if !*yes {
+		return errors.New("the plain app secrets can not be restored, apps signing HS256 tokens with them stop working; run again with -yes")
+	}
+
+	dropped, err := service.DropPlainAppSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("dropped the plain secrets of %d apps\n", dropped)
+
+	return nil
+}
+
+// parseAppID parses the -id flag, the remaining arguments are returned
+// for the flags of the subcommand.
+func parseAppID(name string, args []string) (int64, []string, error) {
+	if len(args) < 2 || args[0] != "-id" {
+		return 0, nil, fmt.Errorf("usage: sso %s -id id", name)
+	}
+
+	appID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || appID <= 0 {
+		return 0, nil, fmt.Errorf("invalid app id %q", args[1])
+	}
+
+	return appID, args[2:], nil
+}
+
+func printApp(app models.App) {
+	fmt.Printf("id:            %d\n", app.ID)
+	fmt.Printf("name:          %s\n", app.Name)
+	fmt.Printf("public:        %t\n", app.Public)
+	fmt.Printf("grant types:   %s\n", strings.ReplaceAll(app.GrantTypes, " ", ","))
+	fmt.Printf("redirect uris: %s\n", strings.Join(app.RedirectURIs, ","))
+	fmt.Printf("scopes:        %s\n", strings.Join(app.Scopes, ","))
+	fmt.Printf("token ttl:     %s\n", tokenTTL(app))
+	fmt.Printf("logo uri:      %s\n", app.LogoURI)
+}
+
+func tokenTTL(app models.App) string {
+	if app.TokenTTLSeconds == 0 {
+		return "default"
+	}
+
+	return (time.Duration(app.TokenTTLSeconds) * time.Second).String()
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/admin"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+func TestDropPlainAppSecrets(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	app, _, err := admin.New(log, s, s, true).CreateApp(ctx, cliAdminID, models.App{Name: "app"})
+	if err != nil {
+		t.Fatalf("create app: %v", err)
+	}
+
+	service := admin.New(log, s, s, false)
+
+	tests := []struct {
+		name        string
+		legacyUntil time.Time
+		args        []string
+		wantDropped bool
+	}{
+		{"legacy window open", time.Now().Add(time.Hour), []string{"-yes"}, false},
+		{"not confirmed", time.Time{}, nil, false},
+		{"confirmed", time.Now().Add(-time.Hour), []string{"-yes"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dropPlainAppSecrets(ctx, service, tt.legacyUntil, tt.args)
+			if (err == nil) != tt.wantDropped {
+				t.Fatalf("dropPlainAppSecrets() error = %v, want dropped %v", err, tt.wantDropped)
+			}
+
+			stored, err := s.App(ctx, app.ID)
+			if err != nil {
+				t.Fatalf("stored app: %v", err)
+			}
+			if dropped := stored.Secret == ""; dropped != tt.wantDropped {
+				t.Fatalf("secret dropped = %v, want %v", dropped, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestParseAppID(t *testing.T) {
+	appID, rest, err := parseAppID("apps update", []string{"-id", "7", "-name", "app"})
+	if err != nil || appID != 7 || !slices.Equal(rest, []string{"-name", "app"}) {
+		t.Fatalf("parseAppID() = %d, %v, %v", appID, rest, err)
+	}
+
+	for _, args := range [][]string{nil, {"-id"}, {"-name", "app"}, {"-id", "app"}, {"-id", "0"}, {"-id", "-1"}} {
+		if _, _, err = parseAppID("apps update", args); err == nil {
+			t.Errorf("parseAppID(%q) accepted", args)
+		}
+	}
+}
+
+func TestAppFlagsKeepUnsetValues(t *testing.T) {
+	app := models.App{
+		Name:            "app",
+		GrantTypes:      models.GrantAuthorizationCode,
+		TokenTTLSeconds: 900,
+		LogoURI:         "https://app.example.com/logo.png",
+		RedirectURIs:    []string{"https://app.example.com/callback"},
+		Scopes:          []string{"docs:read"},
+	}
+
+	fs := flag.NewFlagSet("apps update", flag.ContinueOnError)
+	appFlags(fs, &app)
+	if err := fs.Parse([]string{"-scopes", "docs:read, docs:write,", "-token-ttl", "1h"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+
+	if !slices.Equal(app.Scopes, []string{"docs:read", "docs:write"}) || app.TokenTTLSeconds != 3600 {
+		t.Fatalf("changed values = %v, %d", app.Scopes, app.TokenTTLSeconds)
+	}
+	if app.Name != "app" || app.GrantTypes != models.GrantAuthorizationCode || app.LogoURI != "https://app.example.com/logo.png" ||
+		!slices.Equal(app.RedirectURIs, []string{"https://app.example.com/callback"}) {
+		t.Fatalf("unset values changed: %+v", app)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/emails"
+)
+
+// emailsCommand renders previews of all emails with sample data:
+//
+//	sso emails preview [-out dir] [-locale ru]
+func emailsCommand(args []string) error {
+	if len(args) == 0 || args[0] != "preview" {
+		return errors.New("usage: sso emails preview [-out dir] [-locale locale]")
+	}
+
+	fs := flag.NewFlagSet("emails preview", flag.ContinueOnError)
+	out := fs.String("out", "./email-previews", "directory to write previews to")
+	locale := fs.String("locale", "", "render only this locale")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	locales := emails.Locales
+	if *locale != "" {
+		locales = []string{emails.Locale(*locale)}
+	}
+
+	for _, l := range locales {
+		dir := filepath.Join(*out, l)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+
+		for _, name := range emails.Names {
+			email, err := emails.Render(name, l, sampleEmailData(name))
+			if err != nil {
+				return err
+			}
+
+			path := filepath.Join(dir, name)
+			if err = os.WriteFile(path+".html", []byte(email.HTML), 0o644); err != nil {
+				return err
+			}
+			if err = os.WriteFile(path+".txt", []byte("Subject: "+email.Subject+"\n\n"+email.Text), 0o644); err != nil {
+				return err
+			}
+
+			fmt.Printf("%s.html\n%s.txt\n", path, path)
+		}
+	}
+
+	return nil
+}
+
+func sampleEmailData(name string) any {
+	sampleTime := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("KGT", 6*60*60))
+
+	switch name {
+	case emails.NewDevice:
+		return emails.NewDeviceData{
+			FirstName: "Aibek",
+			Device:    "Firefox on Linux",
+			IP:        "203.0.113.7",
+			Time:      sampleTime,
+		}
+	case emails.OrgInvitation:
+		return emails.OrgInvitationData{
+			OrgName:     "Elif",
+			InviterName: "Aibek Asanov",
+			Role:        "member",
+			Token:       "q9Xc2mT0bS6uV1wY4zA7dF3hJ8kL5nP0rE2tG6yI9oU",
+			ExpiresAt:   sampleTime.Add(7 * 24 * time.Hour),
+		}
+	}
+
+	return emails.CodeData{FirstName: "Aibek", Code: "123456"}
+}
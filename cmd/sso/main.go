@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,14 +10,36 @@ import (
 
 	"github.com/orenvadi/auth-grpc/internal/app"
 	"github.com/orenvadi/auth-grpc/internal/config"
+	"github.com/orenvadi/auth-grpc/internal/lib/tracing"
 )
 
+// commands are administrative subcommands, the server is run without one.
+var commands = map[string]func(args []string) error{
+	"emails": emailsCommand,
+	"apps":   appsCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	storage := flag.String("storage", "", "storage driver: postgres, sqlite or memory, overrides STORAGE_DRIVER")
+	flag.Parse()
+
+	if *storage != "" {
+		os.Setenv("STORAGE_DRIVER", *storage)
+	}
+
 	// DONE init Config object
 	cfg := config.MustLoad()
 
-	fmt.Printf("\nServer config: \n%+v\n\n\n", cfg)
-
 	// DONE init Logger
 	log := setupLogger(cfg.Env)
 
@@ -24,12 +47,13 @@ func main() {
 
 	// DONE init Application (app)
 
-	application := app.New(log, cfg.GRPC.Port, cfg.Storage.DSN(), cfg.TokenTTL)
+	application := app.New(log, cfg)
 
 	// DONE run gRPC-server of the app
 
 	// GraceFull shutdouwn
 	go application.GRPCSrv.MustRun()
+	go application.HTTPSrv.MustRun()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
@@ -39,7 +63,7 @@ func main() {
 
 	log.Info("stopping application", slog.String("signal", sgnl.String()))
 
-	application.GRPCSrv.Stop()
+	application.Stop()
 
 	log.Info("application stopped")
 }
@@ -56,15 +80,15 @@ func setupLogger(env string) *slog.Logger {
 	switch env {
 	case envLocal:
 		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			tracing.NewLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
 		)
 	case envDev:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
 		)
 	case envProd:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
 		)
 	}
 
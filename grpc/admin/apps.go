@@ -0,0 +1,123 @@
+package grpcadmin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *serverAPI) CreateApp(ctx context.Context, req *ssov1.CreateAppRequest) (*ssov1.CreateAppResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	app, secret, err := s.admin.CreateApp(ctx, adminID, models.App{
+		Name:            req.GetName(),
+		RedirectURIs:    req.GetRedirectUris(),
+		GrantTypes:      strings.Join(req.GetGrantTypes(), " "),
+		Scopes:          req.GetScopes(),
+		TokenTTLSeconds: req.GetTokenTtlSeconds(),
+		LogoURI:         req.GetLogoUri(),
+	})
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.CreateAppResponse{App: appToProto(app), Secret: secret}, nil
+}
+
+func (s *serverAPI) ListApps(ctx context.Context, req *ssov1.ListAppsRequest) (*ssov1.ListAppsResponse, error) {
+	apps, err := s.admin.ListApps(ctx)
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &ssov1.ListAppsResponse{Apps: make([]*ssov1.App, 0, len(apps))}
+	for _, app := range apps {
+		resp.Apps = append(resp.Apps, appToProto(app))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) UpdateApp(ctx context.Context, req *ssov1.UpdateAppRequest) (*ssov1.UpdateAppResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := s.admin.UpdateApp(ctx, adminID, models.App{
+		ID:              req.GetAppId(),
+		Name:            req.GetName(),
+		RedirectURIs:    req.GetRedirectUris(),
+		GrantTypes:      strings.Join(req.GetGrantTypes(), " "),
+		Scopes:          req.GetScopes(),
+		TokenTTLSeconds: req.GetTokenTtlSeconds(),
+		LogoURI:         req.GetLogoUri(),
+	})
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.UpdateAppResponse{App: appToProto(app)}, nil
+}
+
+func (s *serverAPI) RotateAppSecret(ctx context.Context, req *ssov1.RotateAppSecretRequest) (*ssov1.RotateAppSecretResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.admin.RotateAppSecret(ctx, adminID, req.GetAppId())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.RotateAppSecretResponse{Secret: secret}, nil
+}
+
+func (s *serverAPI) DeleteApp(ctx context.Context, req *ssov1.DeleteAppRequest) (*ssov1.DeleteAppResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.admin.DeleteApp(ctx, adminID, req.GetAppId()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.DeleteAppResponse{Success: true}, nil
+}
+
+func appToProto(app models.App) *ssov1.App {
+	return &ssov1.App{
+		Id:              app.ID,
+		Name:            app.Name,
+		RedirectUris:    app.RedirectURIs,
+		GrantTypes:      strings.Fields(app.GrantTypes),
+		Scopes:          app.Scopes,
+		TokenTtlSeconds: app.TokenTTLSeconds,
+		LogoUri:         app.LogoURI,
+		CreatedAt:       timestamppb.New(app.CreatedAt),
+	}
+}
@@ -0,0 +1,252 @@
+package grpcadmin
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/services/admin"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Admin interface {
+	ListUsers(ctx context.Context, filter models.UserFilter) (users []models.User, nextAfterID int64, err error)
+	User(ctx context.Context, userID int64) (models.User, error)
+	DisableUser(ctx context.Context, adminID, userID int64) error
+	EnableUser(ctx context.Context, adminID, userID int64) error
+	DeleteUser(ctx context.Context, adminID, userID int64) error
+	SetAdmin(ctx context.Context, adminID, userID int64, isAdmin bool) error
+	CreateApp(ctx context.Context, adminID int64, app models.App) (models.App, string, error)
+	ListApps(ctx context.Context) ([]models.App, error)
+	UpdateApp(ctx context.Context, adminID int64, app models.App) (models.App, error)
+	RotateAppSecret(ctx context.Context, adminID, appID int64) (string, error)
+	DeleteApp(ctx context.Context, adminID, appID int64) error
+}
+
+type serverAPI struct {
+	ssov1.UnimplementedAdminServiceServer
+	admin Admin
+}
+
+// Register registers AdminService, its calls must be authorized by the auth
+// interceptor of the server.
+func Register(gRPC *grpc.Server, admin Admin) {
+	ssov1.RegisterAdminServiceServer(gRPC, &serverAPI{admin: admin})
+}
+
+func (s *serverAPI) ListUsers(ctx context.Context, req *ssov1.ListUsersRequest) (*ssov1.ListUsersResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	afterID, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+
+	filter := models.UserFilter{
+		EmailPrefix: req.GetEmailPrefix(),
+		Confirmed:   req.IsEmailConfirmed,
+		Admin:       req.IsAdmin,
+		AfterID:     afterID,
+		Limit:       int(req.GetPageSize()),
+	}
+	if req.GetCreatedAfter() != nil {
+		t := req.GetCreatedAfter().AsTime()
+		filter.CreatedAfter = &t
+	}
+	if req.GetCreatedBefore() != nil {
+		t := req.GetCreatedBefore().AsTime()
+		filter.CreatedBefore = &t
+	}
+
+	users, nextAfterID, err := s.admin.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &ssov1.ListUsersResponse{
+		Users:         make([]*ssov1.AdminUser, 0, len(users)),
+		NextPageToken: encodePageToken(nextAfterID),
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, userToProto(u))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) GetUser(ctx context.Context, req *ssov1.GetUserRequest) (*ssov1.GetUserResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.admin.User(ctx, req.GetUserId())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.GetUserResponse{User: userToProto(user)}, nil
+}
+
+func (s *serverAPI) DisableUser(ctx context.Context, req *ssov1.DisableUserRequest) (*ssov1.DisableUserResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.admin.DisableUser(ctx, adminID, req.GetUserId()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.DisableUserResponse{Success: true}, nil
+}
+
+func (s *serverAPI) EnableUser(ctx context.Context, req *ssov1.EnableUserRequest) (*ssov1.EnableUserResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.admin.EnableUser(ctx, adminID, req.GetUserId()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.EnableUserResponse{Success: true}, nil
+}
+
+func (s *serverAPI) DeleteUser(ctx context.Context, req *ssov1.DeleteUserRequest) (*ssov1.DeleteUserResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.admin.DeleteUser(ctx, adminID, req.GetUserId()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.DeleteUserResponse{Success: true}, nil
+}
+
+func (s *serverAPI) SetAdmin(ctx context.Context, req *ssov1.SetAdminRequest) (*ssov1.SetAdminResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.admin.SetAdmin(ctx, adminID, req.GetUserId(), req.GetIsAdmin()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.SetAdminResponse{Success: true}, nil
+}
+
+func userToProto(u models.User) *ssov1.AdminUser {
+	user := &ssov1.AdminUser{
+		Id:               u.ID,
+		FirstName:        u.FirstName,
+		LastName:         u.LastName,
+		PhoneNumber:      u.PhoneNumber,
+		Email:            u.Email,
+		Locale:           u.Locale,
+		IsAdmin:          u.IsAdmin,
+		IsEmailConfirmed: u.IsEmailConfirmed,
+		CreatedAt:        timestamppb.New(u.CreatedAt),
+		UpdatedAt:        timestamppb.New(u.UpdatedAt),
+	}
+	if u.DisabledAt != nil {
+		user.DisabledAt = timestamppb.New(*u.DisabledAt)
+	}
+
+	return user
+}
+
+// Page tokens are opaque to clients, so the pagination can change.
+func encodePageToken(afterID int64) string {
+	if afterID == 0 {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(afterID, 10)))
+}
+
+func decodePageToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(b), 10, 64)
+}
+
+func adminError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, admin.ErrSelfModification):
+		return status.Error(codes.FailedPrecondition, admin.ErrSelfModification.Error())
+	case errors.Is(err, storage.ErrAppNotFound):
+		return status.Error(codes.NotFound, "app not found")
+	case errors.Is(err, storage.ErrAppExists):
+		return status.Error(codes.AlreadyExists, "app already exists")
+	case errors.Is(err, admin.ErrInvalidApp):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+// validate checks the constraints declared on the request message.
+func validate(req proto.Message) error {
+	v, err := protovalidate.New()
+	if err != nil {
+		log.Fatalln("error protovalidate", err)
+	}
+
+	if err := v.Validate(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+// adminID returns the ID of the admin authenticated by the auth interceptor.
+func adminID(ctx context.Context) (int64, error) {
+	p, ok := jwtn.PrincipalFromContext(ctx)
+	if !ok || !p.IsAdmin {
+		return 0, status.Error(codes.Internal, "auth interceptor is not installed")
+	}
+
+	return p.UserID, nil
+}
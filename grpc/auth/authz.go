@@ -0,0 +1,186 @@
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/authz"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) WriteRelationTuples(ctx context.Context, req *ssov1.WriteRelationTuplesRequest) (*ssov1.WriteRelationTuplesResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	writes, err := tuplesFromProto(req.GetWrites())
+	if err != nil {
+		return nil, authzError(err)
+	}
+	deletes, err := tuplesFromProto(req.GetDeletes())
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	if _, err = s.auth.AuthorizeAdmin(ctx, req.GetAppId()); err != nil {
+		return nil, adminError(err)
+	}
+
+	if err = s.authz.WriteTuples(ctx, writes, deletes); err != nil {
+		return nil, authzError(err)
+	}
+
+	return &ssov1.WriteRelationTuplesResponse{Success: true}, nil
+}
+
+func (s *serverAPI) Check(ctx context.Context, req *ssov1.CheckRequest) (*ssov1.CheckResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	object, err := authz.ParseObject(req.GetObject())
+	if err != nil {
+		return nil, authzError(err)
+	}
+	subject, err := authz.ParseSubject(req.GetSubject())
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	if err = authorizeAuthzQuery(ctx, &subject); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.authz.Check(ctx, object, req.GetRelation(), subject)
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	return &ssov1.CheckResponse{Allowed: allowed}, nil
+}
+
+func (s *serverAPI) Expand(ctx context.Context, req *ssov1.ExpandRequest) (*ssov1.ExpandResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	object, err := authz.ParseObject(req.GetObject())
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	// the tree lists the subjects of other users
+	if err = authorizeAuthzQuery(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	tree, err := s.authz.Expand(ctx, object, req.GetRelation())
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	return &ssov1.ExpandResponse{Tree: expandNodeToProto(tree)}, nil
+}
+
+func (s *serverAPI) ListObjects(ctx context.Context, req *ssov1.ListObjectsRequest) (*ssov1.ListObjectsResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	subject, err := authz.ParseSubject(req.GetSubject())
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	if err = authorizeAuthzQuery(ctx, &subject); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.authz.ListObjects(ctx, req.GetNamespace(), req.GetRelation(), subject)
+	if err != nil {
+		return nil, authzError(err)
+	}
+
+	return &ssov1.ListObjectsResponse{ObjectIds: ids}, nil
+}
+
+// authorizeAuthzQuery checks that the caller may query the relations of
+// subject, or of every subject if it is nil. Admins and client tokens with
+// authz.ReadScope may query any subject, users only themselves.
+func authorizeAuthzQuery(ctx context.Context, subject *authz.Subject) error {
+	p, ok := jwtn.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Internal, "auth interceptor is not installed")
+	}
+
+	switch {
+	case p.UserID == 0:
+		if !slices.Contains(p.Scopes, authz.ReadScope) {
+			return status.Errorf(codes.PermissionDenied, "client token requires the %s scope", authz.ReadScope)
+		}
+		return nil
+	case p.IsAdmin && slices.Contains(p.AuthMethods, auth.AuthMethodMFA):
+		return nil
+	}
+
+	own := authz.Subject{Object: authz.Object{Namespace: authz.UserNamespace, ID: strconv.FormatInt(p.UserID, 10)}}
+	if subject == nil || *subject != own {
+		return status.Error(codes.PermissionDenied, "users can only query their own relations")
+	}
+
+	return nil
+}
+
+func tuplesFromProto(tuples []*ssov1.RelationTuple) ([]authz.Tuple, error) {
+	res := make([]authz.Tuple, 0, len(tuples))
+	for _, t := range tuples {
+		object, err := authz.ParseObject(t.GetObject())
+		if err != nil {
+			return nil, err
+		}
+		subject, err := authz.ParseSubject(t.GetSubject())
+		if err != nil {
+			return nil, err
+		}
+		if t.GetRelation() == "" {
+			return nil, fmt.Errorf("%w: relation is required", authz.ErrInvalidTuple)
+		}
+
+		res = append(res, authz.Tuple{Object: object, Relation: t.GetRelation(), Subject: subject})
+	}
+
+	return res, nil
+}
+
+func expandNodeToProto(n *authz.Node) *ssov1.ExpandNode {
+	node := &ssov1.ExpandNode{
+		Operation: n.Operation,
+		Userset:   n.Userset,
+		Subjects:  n.Subjects,
+	}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, expandNodeToProto(child))
+	}
+
+	return node
+}
+
+func authzError(err error) error {
+	switch {
+	case errors.Is(err, authz.ErrInvalidTuple), errors.Is(err, authz.ErrUnknownRelation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, authz.ErrMaxDepth):
+		return status.Error(codes.FailedPrecondition, "relation graph too deep")
+	case errors.Is(err, authz.ErrTooManyObjects):
+		return status.Error(codes.FailedPrecondition, "too many objects to list, use Check")
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
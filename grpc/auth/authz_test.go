@@ -0,0 +1,51 @@
+package grpcauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/authz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizeAuthzQuery(t *testing.T) {
+	own := authz.Subject{Object: authz.Object{Namespace: authz.UserNamespace, ID: "42"}}
+	other := authz.Subject{Object: authz.Object{Namespace: authz.UserNamespace, ID: "7"}}
+	group := authz.Subject{Object: authz.Object{Namespace: "group", ID: "eng"}, Relation: "member"}
+
+	user := models.Principal{UserID: 42, AppID: 1}
+	admin := models.Principal{UserID: 1, AppID: 1, IsAdmin: true, AuthMethods: []string{"pwd", auth.AuthMethodMFA}}
+	client := models.Principal{AppID: 1, Scopes: []string{authz.ReadScope}}
+
+	tests := []struct {
+		name      string
+		principal models.Principal
+		subject   *authz.Subject
+		want      codes.Code
+	}{
+		{"user, own subject", user, &own, codes.OK},
+		{"user, other user", user, &other, codes.PermissionDenied},
+		{"user, userset", user, &group, codes.PermissionDenied},
+		{"user, expand", user, nil, codes.PermissionDenied},
+		{"admin, other user", admin, &other, codes.OK},
+		{"admin without MFA", models.Principal{UserID: 1, IsAdmin: true}, &other, codes.PermissionDenied},
+		{"client with scope", client, &other, codes.OK},
+		{"client with scope, expand", client, nil, codes.OK},
+		{"client without scope", models.Principal{AppID: 1}, &other, codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := jwtn.ContextWithPrincipal(context.Background(), tt.principal)
+
+			err := authorizeAuthzQuery(ctx, tt.subject)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("authorizeAuthzQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
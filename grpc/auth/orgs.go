@@ -0,0 +1,161 @@
+package grpcauth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *serverAPI) CreateOrganization(ctx context.Context, req *ssov1.CreateOrganizationRequest) (*ssov1.CreateOrganizationResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	org, err := s.auth.CreateOrganization(ctx, req.GetAppId(), req.GetName(), req.GetSlug())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.CreateOrganizationResponse{
+		Organization: &ssov1.Organization{
+			Id:        org.ID,
+			Name:      org.Name,
+			Slug:      org.Slug,
+			CreatedAt: timestamppb.New(org.CreatedAt),
+		},
+	}, nil
+}
+
+func (s *serverAPI) InviteToOrganization(ctx context.Context, req *ssov1.InviteToOrganizationRequest) (*ssov1.InviteToOrganizationResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	id, err := s.auth.InviteToOrganization(ctx, req.GetAppId(), req.GetOrgId(), req.GetEmail(), req.GetRole())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.InviteToOrganizationResponse{InvitationId: id}, nil
+}
+
+func (s *serverAPI) AcceptOrganizationInvitation(ctx context.Context, req *ssov1.AcceptOrganizationInvitationRequest) (*ssov1.AcceptOrganizationInvitationResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	member, err := s.auth.AcceptOrgInvitation(ctx, req.GetAppId(), req.GetToken())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.AcceptOrganizationInvitationResponse{Membership: orgMemberToProto(member)}, nil
+}
+
+func (s *serverAPI) RemoveOrganizationMember(ctx context.Context, req *ssov1.RemoveOrganizationMemberRequest) (*ssov1.RemoveOrganizationMemberResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RemoveOrgMember(ctx, req.GetAppId(), req.GetOrgId(), req.GetUserId()); err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.RemoveOrganizationMemberResponse{Success: true}, nil
+}
+
+func (s *serverAPI) ListMemberships(ctx context.Context, req *ssov1.ListMembershipsRequest) (*ssov1.ListMembershipsResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.auth.OrgMemberships(ctx, req.GetAppId())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.ListMembershipsResponse{Memberships: orgMembersToProto(memberships)}, nil
+}
+
+func (s *serverAPI) ListOrganizationMembers(ctx context.Context, req *ssov1.ListOrganizationMembersRequest) (*ssov1.ListOrganizationMembersResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	members, err := s.auth.OrgMembers(ctx, req.GetAppId(), req.GetOrgId())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.ListOrganizationMembersResponse{Members: orgMembersToProto(members)}, nil
+}
+
+func (s *serverAPI) SwitchOrganization(ctx context.Context, req *ssov1.SwitchOrganizationRequest) (*ssov1.SwitchOrganizationResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.auth.SwitchOrganization(ctx, req.GetAppId(), req.GetOrgId())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.SwitchOrganizationResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func orgMembersToProto(members []models.OrgMember) []*ssov1.OrganizationMember {
+	res := make([]*ssov1.OrganizationMember, 0, len(members))
+	for _, m := range members {
+		res = append(res, orgMemberToProto(m))
+	}
+
+	return res
+}
+
+func orgMemberToProto(m models.OrgMember) *ssov1.OrganizationMember {
+	return &ssov1.OrganizationMember{
+		OrgId:     m.OrgID,
+		OrgName:   m.OrgName,
+		UserId:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		CreatedAt: timestamppb.New(m.CreatedAt),
+	}
+}
+
+func orgError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "not permitted in the organization")
+	case errors.Is(err, auth.ErrOrgInvitationForbidden):
+		return status.Error(codes.PermissionDenied, "invitation is for another email or the email is not confirmed")
+	case errors.Is(err, auth.ErrInvalidOrgInvitation):
+		return status.Error(codes.InvalidArgument, "invalid or expired invitation")
+	case errors.Is(err, auth.ErrInvalidOrgRole):
+		return status.Error(codes.InvalidArgument, "invalid role")
+	case errors.Is(err, storage.ErrAppNotFound):
+		return status.Error(codes.InvalidArgument, "invalid app_id")
+	case errors.Is(err, storage.ErrOrgExists):
+		return status.Error(codes.AlreadyExists, "organization slug is taken")
+	case errors.Is(err, storage.ErrOrgNotFound):
+		return status.Error(codes.NotFound, "organization not found")
+	case errors.Is(err, storage.ErrOrgMemberNotFound):
+		return status.Error(codes.NotFound, "member not found")
+	case errors.Is(err, storage.ErrLastOrgOwner):
+		return status.Error(codes.FailedPrecondition, "organization must keep an owner")
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,53 @@
+package grpcauth
+
+import (
+	"context"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *serverAPI) ListOutboxEmails(ctx context.Context, req *ssov1.ListOutboxEmailsRequest) (*ssov1.ListOutboxEmailsResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	emails, err := s.auth.OutboxEmails(ctx, req.GetAppId(), req.GetStatus(), int(req.GetLimit()))
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &ssov1.ListOutboxEmailsResponse{Emails: make([]*ssov1.OutboxEmail, 0, len(emails))}
+	for _, e := range emails {
+		resp.Emails = append(resp.Emails, outboxEmailToProto(e))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) RetryOutboxEmail(ctx context.Context, req *ssov1.RetryOutboxEmailRequest) (*ssov1.RetryOutboxEmailResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RetryOutboxEmail(ctx, req.GetAppId(), req.GetEmailId()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.RetryOutboxEmailResponse{Success: true}, nil
+}
+
+func outboxEmailToProto(e models.OutboxEmail) *ssov1.OutboxEmail {
+	return &ssov1.OutboxEmail{
+		Id:            e.ID,
+		Recipient:     e.Recipient,
+		Subject:       e.Subject,
+		Status:        e.Status,
+		Attempts:      int32(e.Attempts),
+		LastError:     e.LastError,
+		NextAttemptAt: timestamppb.New(e.NextAttemptAt),
+		SentAt:        optionalTimestamp(e.SentAt),
+		CreatedAt:     timestamppb.New(e.CreatedAt),
+	}
+}
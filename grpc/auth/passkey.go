@@ -0,0 +1,101 @@
+package grpcauth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/passkey"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) BeginPasskeyRegistration(ctx context.Context, req *ssov1.BeginPasskeyRegistrationRequest) (*ssov1.BeginPasskeyRegistrationResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	options, sessionID, err := s.passkeys.BeginRegistration(ctx, req.GetAppId())
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.BeginPasskeyRegistrationResponse{
+		OptionsJson: string(options),
+		SessionId:   sessionID,
+	}, nil
+}
+
+func (s *serverAPI) FinishPasskeyRegistration(ctx context.Context, req *ssov1.FinishPasskeyRegistrationRequest) (*ssov1.FinishPasskeyRegistrationResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	credentialID, err := s.passkeys.FinishRegistration(ctx, req.GetAppId(), req.GetSessionId(), []byte(req.GetCredentialJson()), req.GetName())
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.FinishPasskeyRegistrationResponse{
+		CredentialId: base64.RawURLEncoding.EncodeToString(credentialID),
+	}, nil
+}
+
+func (s *serverAPI) BeginPasskeyLogin(ctx context.Context, req *ssov1.BeginPasskeyLoginRequest) (*ssov1.BeginPasskeyLoginResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	options, sessionID, err := s.passkeys.BeginLogin(ctx, req.GetAppId())
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.BeginPasskeyLoginResponse{
+		OptionsJson: string(options),
+		SessionId:   sessionID,
+	}, nil
+}
+
+func (s *serverAPI) FinishPasskeyLogin(ctx context.Context, req *ssov1.FinishPasskeyLoginRequest) (*ssov1.FinishPasskeyLoginResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	accessToken, refreshToken, err := s.passkeys.FinishLogin(ctx, req.GetAppId(), req.GetSessionId(), []byte(req.GetCredentialJson()))
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.FinishPasskeyLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// passkeyError maps errors of passkey calls to gRPC status.
+func passkeyError(err error) error {
+	switch {
+	case errors.Is(err, passkey.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, passkey.ErrInvalidSession):
+		return status.Error(codes.FailedPrecondition, "invalid or expired session, please start over")
+	case errors.Is(err, passkey.ErrInvalidCredential), errors.Is(err, passkey.ErrClonedAuthenticator):
+		return status.Error(codes.Unauthenticated, "passkey verification failed")
+	case errors.Is(err, passkey.ErrCredentialExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, auth.ErrUserDisabled):
+		return status.Error(codes.PermissionDenied, "user is disabled")
+	case errors.Is(err, storage.ErrAppNotFound):
+		return status.Error(codes.InvalidArgument, "invalid app_id")
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,186 @@
+package grpcauth
+
+import (
+	"context"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *serverAPI) CheckPermission(ctx context.Context, req *ssov1.CheckPermissionRequest) (*ssov1.CheckPermissionResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.auth.CheckPermission(ctx, req.GetUserId(), req.GetAppId(), req.GetPermission())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.CheckPermissionResponse{Allowed: allowed}, nil
+}
+
+func (s *serverAPI) CreateRole(ctx context.Context, req *ssov1.CreateRoleRequest) (*ssov1.CreateRoleResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	role, err := s.auth.CreateRole(ctx, req.GetAppId(), req.GetName(), req.GetDescription())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.CreateRoleResponse{Role: roleToProto(role)}, nil
+}
+
+func (s *serverAPI) DeleteRole(ctx context.Context, req *ssov1.DeleteRoleRequest) (*ssov1.DeleteRoleResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.DeleteRole(ctx, req.GetAppId(), req.GetName()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.DeleteRoleResponse{Success: true}, nil
+}
+
+func (s *serverAPI) ListRoles(ctx context.Context, req *ssov1.ListRolesRequest) (*ssov1.ListRolesResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.auth.Roles(ctx, req.GetAppId())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &ssov1.ListRolesResponse{Roles: make([]*ssov1.Role, 0, len(roles))}
+	for _, r := range roles {
+		resp.Roles = append(resp.Roles, roleToProto(r))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) CreatePermission(ctx context.Context, req *ssov1.CreatePermissionRequest) (*ssov1.CreatePermissionResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	perm, err := s.auth.CreatePermission(ctx, req.GetAppId(), req.GetName(), req.GetDescription())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.CreatePermissionResponse{Permission: permissionToProto(perm)}, nil
+}
+
+func (s *serverAPI) DeletePermission(ctx context.Context, req *ssov1.DeletePermissionRequest) (*ssov1.DeletePermissionResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.DeletePermission(ctx, req.GetAppId(), req.GetName()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.DeletePermissionResponse{Success: true}, nil
+}
+
+func (s *serverAPI) ListPermissions(ctx context.Context, req *ssov1.ListPermissionsRequest) (*ssov1.ListPermissionsResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	perms, err := s.auth.Permissions(ctx, req.GetAppId())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &ssov1.ListPermissionsResponse{Permissions: make([]*ssov1.Permission, 0, len(perms))}
+	for _, p := range perms {
+		resp.Permissions = append(resp.Permissions, permissionToProto(p))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) GrantPermission(ctx context.Context, req *ssov1.GrantPermissionRequest) (*ssov1.GrantPermissionResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.GrantPermission(ctx, req.GetAppId(), req.GetRole(), req.GetPermission()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.GrantPermissionResponse{Success: true}, nil
+}
+
+func (s *serverAPI) RevokePermission(ctx context.Context, req *ssov1.RevokePermissionRequest) (*ssov1.RevokePermissionResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RevokePermission(ctx, req.GetAppId(), req.GetRole(), req.GetPermission()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.RevokePermissionResponse{Success: true}, nil
+}
+
+func (s *serverAPI) AssignRole(ctx context.Context, req *ssov1.AssignRoleRequest) (*ssov1.AssignRoleResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.AssignRole(ctx, req.GetAppId(), req.GetUserId(), req.GetRole()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.AssignRoleResponse{Success: true}, nil
+}
+
+func (s *serverAPI) UnassignRole(ctx context.Context, req *ssov1.UnassignRoleRequest) (*ssov1.UnassignRoleResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.UnassignRole(ctx, req.GetAppId(), req.GetUserId(), req.GetRole()); err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.UnassignRoleResponse{Success: true}, nil
+}
+
+func (s *serverAPI) ListUserRoles(ctx context.Context, req *ssov1.ListUserRolesRequest) (*ssov1.ListUserRolesResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	roles, perms, err := s.auth.UserRoles(ctx, req.GetAppId(), req.GetUserId())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.ListUserRolesResponse{Roles: roles, Permissions: perms}, nil
+}
+
+func roleToProto(r models.Role) *ssov1.Role {
+	return &ssov1.Role{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.Permissions,
+		CreatedAt:   timestamppb.New(r.CreatedAt),
+	}
+}
+
+func permissionToProto(p models.Permission) *ssov1.Permission {
+	return &ssov1.Permission{
+		Name:        p.Name,
+		Description: p.Description,
+		CreatedAt:   timestamppb.New(p.CreatedAt),
+	}
+}
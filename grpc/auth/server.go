@@ -4,36 +4,89 @@ import (
 	"context"
 	"errors"
 	"log"
+	"time"
 
 	"github.com/bufbuild/protovalidate-go"
 	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
 	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/authz"
 	"github.com/orenvadi/auth-grpc/internal/storage"
 	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Auth interface {
-	Login(ctx context.Context, email, password string, appID int64) (accessToken string, err error)
-	RegisterNewUser(ctx context.Context, firstName, lastName, phoneNumber, email, password string, appID int64) (userID int64, accessToken, refreshToken string, err error)
-	UpdateUser(ctx context.Context, firstName, lastName, phoneNumber, email string, appID int64) error
+	Login(ctx context.Context, email, password string, appID int64) (accessToken, refreshToken, mfaToken string, err error)
+	RegisterNewUser(ctx context.Context, firstName, lastName, phoneNumber, email, password, locale string, appID int64) (userID int64, accessToken, refreshToken string, err error)
+	UpdateUser(ctx context.Context, firstName, lastName, phoneNumber, email, locale string, appID int64) error
 	IsAdmin(ctx context.Context, userID int64) (bool, error)
 	ConfirmUserEmail(ctx context.Context, code string, appID int64) (success bool, err error)
 	GetUserData(ctx context.Context, appID int64) (models.User, error)
 	SendCodeToResetPassword(ctx context.Context, email string) error
 	SetNewPassword(ctx context.Context, confirmCode, email string, newPassword string) error
+	RefreshToken(ctx context.Context, refreshToken string, appID int64) (accessToken, newRefreshToken string, err error)
+	Logout(ctx context.Context, token string, appID int64) error
+	JWKS(ctx context.Context) (jwtn.JWKS, error)
+	RotateSigningKeys(ctx context.Context, appID int64, immediate bool) (models.SigningKey, error)
+	SigningKeys(ctx context.Context, appID int64) ([]models.SigningKey, error)
+	IntrospectToken(ctx context.Context, token string, appID int64) (models.TokenIntrospection, error)
+	EnrollTOTP(ctx context.Context, appID int64) (secret, uri string, err error)
+	VerifyTOTPEnrollment(ctx context.Context, code string, appID int64) (recoveryCodes []string, err error)
+	CompleteMFALogin(ctx context.Context, mfaToken, code string, appID int64) (accessToken, refreshToken string, err error)
+	OutboxEmails(ctx context.Context, appID int64, status string, limit int) ([]models.OutboxEmail, error)
+	RetryOutboxEmail(ctx context.Context, appID, emailID int64) error
+	CheckPermission(ctx context.Context, userID, appID int64, permission string) (bool, error)
+	CreateRole(ctx context.Context, appID int64, name, description string) (models.Role, error)
+	DeleteRole(ctx context.Context, appID int64, name string) error
+	Roles(ctx context.Context, appID int64) ([]models.Role, error)
+	CreatePermission(ctx context.Context, appID int64, name, description string) (models.Permission, error)
+	DeletePermission(ctx context.Context, appID int64, name string) error
+	Permissions(ctx context.Context, appID int64) ([]models.Permission, error)
+	GrantPermission(ctx context.Context, appID int64, role, permission string) error
+	RevokePermission(ctx context.Context, appID int64, role, permission string) error
+	AssignRole(ctx context.Context, appID, userID int64, role string) error
+	UnassignRole(ctx context.Context, appID, userID int64, role string) error
+	UserRoles(ctx context.Context, appID, userID int64) (roles, permissions []string, err error)
+	AuthorizeAdmin(ctx context.Context, appID int64) (adminID int64, err error)
+	CreateOrganization(ctx context.Context, appID int64, name, slug string) (models.Organization, error)
+	InviteToOrganization(ctx context.Context, appID, orgID int64, email, role string) (invitationID int64, err error)
+	AcceptOrgInvitation(ctx context.Context, appID int64, token string) (models.OrgMember, error)
+	RemoveOrgMember(ctx context.Context, appID, orgID, userID int64) error
+	OrgMemberships(ctx context.Context, appID int64) ([]models.OrgMember, error)
+	OrgMembers(ctx context.Context, appID, orgID int64) ([]models.OrgMember, error)
+	SwitchOrganization(ctx context.Context, appID, orgID int64) (accessToken, refreshToken string, err error)
+}
+
+// Passkeys is the passwordless login with WebAuthn.
+type Passkeys interface {
+	BeginRegistration(ctx context.Context, appID int64) (optionsJSON []byte, sessionID string, err error)
+	FinishRegistration(ctx context.Context, appID int64, sessionID string, credentialJSON []byte, name string) (credentialID []byte, err error)
+	BeginLogin(ctx context.Context, appID int64) (optionsJSON []byte, sessionID string, err error)
+	FinishLogin(ctx context.Context, appID int64, sessionID string, credentialJSON []byte) (accessToken, refreshToken string, err error)
+}
+
+// Authz is the relationship-based authorization.
+type Authz interface {
+	WriteTuples(ctx context.Context, writes, deletes []authz.Tuple) error
+	Check(ctx context.Context, object authz.Object, relation string, subject authz.Subject) (bool, error)
+	Expand(ctx context.Context, object authz.Object, relation string) (*authz.Node, error)
+	ListObjects(ctx context.Context, namespace, relation string, subject authz.Subject) ([]string, error)
 }
 
 type serverAPI struct {
 	ssov1.UnimplementedAuthServer
-	auth Auth
+	auth     Auth
+	passkeys Passkeys
+	authz    Authz
 }
 
-func Register(gRPC *grpc.Server, auth Auth) {
-	ssov1.RegisterAuthServer(gRPC, &serverAPI{auth: auth})
+func Register(gRPC *grpc.Server, auth Auth, passkeys Passkeys, authz Authz) {
+	ssov1.RegisterAuthServer(gRPC, &serverAPI{auth: auth, passkeys: passkeys, authz: authz})
 }
 
 const (
@@ -42,33 +95,26 @@ const (
 
 func (s *serverAPI) Login(ctx context.Context, req *ssov1.LoginRequest) (*ssov1.LoginResponse, error) {
 	// DONE add rpc validation using 3rd party package
-	v, err := protovalidate.New()
-	if err != nil {
-		log.Fatalln("error protovalidate", err)
-	}
-
-	// validating
-	if err := v.Validate(req); err != nil {
-		switch {
-
-		case req.GetAppId() == emptyValue:
+	if err := validate(req); err != nil {
+		if req.GetAppId() == emptyValue {
 			return nil, status.Error(codes.InvalidArgument, "app_id is required")
-
-		default:
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-
 		}
+
+		return nil, err
 	}
 
 	// DONE: implement login via auth service
 
-	accessToken, err := s.auth.Login(ctx, req.GetEmail(), req.GetPassword(), req.GetAppId())
+	accessToken, refreshToken, mfaToken, err := s.auth.Login(ctx, req.GetEmail(), req.GetPassword(), req.GetAppId())
 	if err != nil {
 		// DONE handle various error types
 
 		if errors.Is(err, auth.ErrInvalidCredentials) {
 			return nil, status.Error(codes.InvalidArgument, "invalid email or password")
 		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "user is disabled")
+		}
 
 		// cause it is internal service, and users have no access to us
 		// we can return internal errors to the client services
@@ -76,27 +122,29 @@ func (s *serverAPI) Login(ctx context.Context, req *ssov1.LoginRequest) (*ssov1.
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if mfaToken != "" {
+		return &ssov1.LoginResponse{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+
 	return &ssov1.LoginResponse{
-		AccessToken: accessToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
 func (s *serverAPI) Register(ctx context.Context, req *ssov1.RegisterRequest) (*ssov1.RegisterResponse, error) {
-	v, err := protovalidate.New()
-	if err != nil {
-		log.Fatalln("error protovalidate", err)
-	}
-
-	// validating
-	if err := v.Validate(req); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+	if err := validate(req); err != nil {
+		return nil, err
 	}
 
-	userID, accessToken, refreshToken, err := s.auth.RegisterNewUser(ctx, req.GetFirstName(), req.GetLastName(), req.GetPhoneNumber(), req.GetEmail(), req.GetPassword(), req.GetAppId())
+	userID, accessToken, refreshToken, err := s.auth.RegisterNewUser(ctx, req.GetFirstName(), req.GetLastName(), req.GetPhoneNumber(), req.GetEmail(), req.GetPassword(), req.GetLocale(), req.GetAppId())
 	if err != nil {
 		// DONE handle various error types
 
-		if errors.Is(err, storage.ErrUserExists) {
+		if errors.Is(err, auth.ErrUserAlreadyExists) {
 			return nil, status.Error(codes.AlreadyExists, "user already exists")
 		}
 		// because it is internal service, and users have no access to us
@@ -113,14 +161,8 @@ func (s *serverAPI) Register(ctx context.Context, req *ssov1.RegisterRequest) (*
 }
 
 func (s *serverAPI) ConfirmUserEmail(ctx context.Context, req *ssov1.ConfirmUserEmailRequest) (confirmUserEmailResponse *ssov1.ConfirmUserEmailResponse, err error) {
-	v, err := protovalidate.New()
-	if err != nil {
-		log.Fatalln("error protovalidate", err)
-	}
-
-	// validating
-	if err := v.Validate(req); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+	if err := validate(req); err != nil {
+		return nil, err
 	}
 
 	confirmSuccess, err := s.auth.ConfirmUserEmail(ctx, req.GetConfirmCode(), req.GetAppId())
@@ -135,17 +177,11 @@ func (s *serverAPI) ConfirmUserEmail(ctx context.Context, req *ssov1.ConfirmUser
 
 // this took me 8 hours to debug
 func (s *serverAPI) UpdateUser(ctx context.Context, req *ssov1.UpdateUserRequest) (updateUserResponse *ssov1.UpdateUserResponse, err error) {
-	v, err := protovalidate.New()
-	if err != nil {
-		log.Fatalln("error protovalidate", err)
-	}
-
-	// validating
-	if err := v.Validate(req); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+	if err := validate(req); err != nil {
+		return nil, err
 	}
 
-	if err = s.auth.UpdateUser(ctx, req.GetFirstName(), req.GetLastName(), req.GetPhoneNumber(), req.GetEmail(), req.GetAppId()); err != nil {
+	if err = s.auth.UpdateUser(ctx, req.GetFirstName(), req.GetLastName(), req.GetPhoneNumber(), req.GetEmail(), req.GetLocale(), req.GetAppId()); err != nil {
 		return nil, err
 	}
 
@@ -155,22 +191,12 @@ func (s *serverAPI) UpdateUser(ctx context.Context, req *ssov1.UpdateUserRequest
 }
 
 func (s *serverAPI) IsAdmin(ctx context.Context, req *ssov1.IsAdminRequest) (*ssov1.IsAdminResponse, error) {
-	v, err := protovalidate.New()
-	if err != nil {
-		log.Fatalln("error protovalidate", err)
-	}
-
-	// validating
-	if err := v.Validate(req); err != nil {
-		switch {
-
-		case req.GetUserId() == emptyValue:
+	if err := validate(req); err != nil {
+		if req.GetUserId() == emptyValue {
 			return nil, status.Error(codes.InvalidArgument, "user_id is required")
-
-		default:
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-
 		}
+
+		return nil, err
 	}
 
 	isAdmin, err := s.auth.IsAdmin(ctx, req.UserId)
@@ -206,47 +232,302 @@ func (s *serverAPI) GetUserData(ctx context.Context, req *ssov1.GetUserDataReque
 		CreatedAt:   timestamppb.New(user.CreatedAt),
 		UpdatedAt:   timestamppb.New(user.UpdatedAt),
 		Email:       user.Email,
+		Locale:      user.Locale,
 	}, nil
 }
 
 func (s *serverAPI) SendCodeToResetPassword(ctx context.Context, req *ssov1.SendCodeToResetPasswordRequest) (*ssov1.SendCodeToResetPasswordResponse, error) {
-	v, err := protovalidate.New()
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.SendCodeToResetPassword(ctx, req.GetEmail()); err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	return &ssov1.SendCodeToResetPasswordResponse{Success: true}, nil
+}
+
+func (s *serverAPI) SetNewPassword(ctx context.Context, req *ssov1.SetNewPasswordRequest) (*ssov1.SetNewPasswordResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.SetNewPassword(ctx, req.GetConfirmCode(), req.GetEmail(), req.GetNewPassword()); err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	return &ssov1.SetNewPasswordResponse{Success: true}, nil
+}
+
+func (s *serverAPI) RefreshToken(ctx context.Context, req *ssov1.RefreshTokenRequest) (*ssov1.RefreshTokenResponse, error) {
+	if err := validate(req); err != nil {
+		if req.GetAppId() == emptyValue {
+			return nil, status.Error(codes.InvalidArgument, "app_id is required")
+		}
+
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.auth.RefreshToken(ctx, req.GetRefreshToken(), req.GetAppId())
 	if err != nil {
-		log.Fatalln("error protovalidate", err)
+		switch {
+		case errors.Is(err, auth.ErrRefreshTokenReused):
+			return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected, please log in again")
+		case errors.Is(err, auth.ErrInvalidRefreshToken):
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		case errors.Is(err, storage.ErrAppNotFound):
+			return nil, status.Error(codes.InvalidArgument, "invalid app_id")
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// validating
-	if err := v.Validate(req); err != nil {
+	return &ssov1.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (s *serverAPI) Logout(ctx context.Context, req *ssov1.LogoutRequest) (*ssov1.LogoutResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if err := s.auth.Logout(ctx, req.GetToken(), req.GetAppId()); err != nil {
 		switch {
-		default:
-			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, auth.ErrInvalidToken):
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		case errors.Is(err, storage.ErrAppNotFound):
+			return nil, status.Error(codes.InvalidArgument, "invalid app_id")
 		}
+
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	if err = s.auth.SendCodeToResetPassword(ctx, req.GetEmail()); err != nil {
-		return nil, status.Error(codes.Unknown, err.Error())
+	return &ssov1.LogoutResponse{Success: true}, nil
+}
+
+func (s *serverAPI) GetJWKS(ctx context.Context, req *ssov1.GetJWKSRequest) (*ssov1.GetJWKSResponse, error) {
+	jwks, err := s.auth.JWKS(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &ssov1.SendCodeToResetPasswordResponse{Success: true}, nil
+	keys := make([]*ssov1.JWK, 0, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		keys = append(keys, &ssov1.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Use: k.Use,
+			Alg: k.Alg,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+			Y:   k.Y,
+		})
+	}
+
+	return &ssov1.GetJWKSResponse{Keys: keys}, nil
 }
 
-func (s *serverAPI) SetNewPassword(ctx context.Context, req *ssov1.SetNewPasswordRequest) (*ssov1.SetNewPasswordResponse, error) {
+func (s *serverAPI) RotateSigningKeys(ctx context.Context, req *ssov1.RotateSigningKeysRequest) (*ssov1.RotateSigningKeysResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	key, err := s.auth.RotateSigningKeys(ctx, req.GetAppId(), req.GetImmediate())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	return &ssov1.RotateSigningKeysResponse{Key: signingKeyToProto(key)}, nil
+}
+
+func (s *serverAPI) ListSigningKeys(ctx context.Context, req *ssov1.ListSigningKeysRequest) (*ssov1.ListSigningKeysResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	keys, err := s.auth.SigningKeys(ctx, req.GetAppId())
+	if err != nil {
+		return nil, adminError(err)
+	}
+
+	resp := &ssov1.ListSigningKeysResponse{Keys: make([]*ssov1.SigningKey, 0, len(keys))}
+	for _, k := range keys {
+		resp.Keys = append(resp.Keys, signingKeyToProto(k))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) IntrospectToken(ctx context.Context, req *ssov1.IntrospectTokenRequest) (*ssov1.IntrospectTokenResponse, error) {
+	info, err := s.auth.IntrospectToken(ctx, req.GetToken(), req.GetAppId())
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "token is required")
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !info.Active {
+		return &ssov1.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	return &ssov1.IntrospectTokenResponse{
+		Active: true,
+		Sub:    info.Subject,
+		AppId:  info.AppID,
+		Scope:  info.Scope,
+		Exp:    info.ExpiresAt.Unix(),
+		Iat:    info.IssuedAt.Unix(),
+	}, nil
+}
+
+func (s *serverAPI) EnrollTOTP(ctx context.Context, req *ssov1.EnrollTOTPRequest) (*ssov1.EnrollTOTPResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	secret, uri, err := s.auth.EnrollTOTP(ctx, req.GetAppId())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &ssov1.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUri: uri,
+	}, nil
+}
+
+func (s *serverAPI) VerifyTOTPEnrollment(ctx context.Context, req *ssov1.VerifyTOTPEnrollmentRequest) (*ssov1.VerifyTOTPEnrollmentResponse, error) {
+	if err := validateTokenRequest(req, req.GetAppId()); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.auth.VerifyTOTPEnrollment(ctx, req.GetCode(), req.GetAppId())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &ssov1.VerifyTOTPEnrollmentResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *serverAPI) CompleteMFALogin(ctx context.Context, req *ssov1.CompleteMFALoginRequest) (*ssov1.CompleteMFALoginResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	accessToken, refreshToken, err := s.auth.CompleteMFALogin(ctx, req.GetMfaToken(), req.GetCode(), req.GetAppId())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &ssov1.CompleteMFALoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// mfaError maps errors of two-factor authentication calls to gRPC status.
+func mfaError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, auth.ErrInvalidMFACode):
+		return status.Error(codes.Unauthenticated, "invalid code")
+	case errors.Is(err, auth.ErrUserDisabled):
+		return status.Error(codes.PermissionDenied, "user is disabled")
+	case errors.Is(err, auth.ErrTOTPAlreadyEnabled), errors.Is(err, auth.ErrTOTPNotEnrolled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, storage.ErrAppNotFound):
+		return status.Error(codes.InvalidArgument, "invalid app_id")
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+// adminError maps errors of admin only calls to gRPC status.
+func adminError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "admin permissions required")
+	case errors.Is(err, auth.ErrMFARequired):
+		return status.Error(codes.PermissionDenied, "admin permissions require two-factor authentication")
+	case errors.Is(err, storage.ErrAppNotFound):
+		return status.Error(codes.InvalidArgument, "invalid app_id")
+	case errors.Is(err, auth.ErrKeyRotationDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, storage.ErrOutboxEmailNotFound):
+		return status.Error(codes.NotFound, "email not found or already sent")
+	case errors.Is(err, storage.ErrRoleExists):
+		return status.Error(codes.AlreadyExists, "role already exists")
+	case errors.Is(err, storage.ErrPermissionExists):
+		return status.Error(codes.AlreadyExists, "permission already exists")
+	case errors.Is(err, storage.ErrRoleNotFound):
+		return status.Error(codes.NotFound, "role not found")
+	case errors.Is(err, storage.ErrPermissionNotFound):
+		return status.Error(codes.NotFound, "permission not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func signingKeyToProto(k models.SigningKey) *ssov1.SigningKey {
+	return &ssov1.SigningKey{
+		Kid:         k.KID,
+		Alg:         k.Alg,
+		State:       k.State,
+		CreatedAt:   timestamppb.New(k.CreatedAt),
+		ActivatedAt: optionalTimestamp(k.ActivatedAt),
+		RotatedAt:   optionalTimestamp(k.RotatedAt),
+		RetiredAt:   optionalTimestamp(k.RetiredAt),
+	}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+// validate checks the constraints declared on the request message.
+func validate(req proto.Message) error {
 	v, err := protovalidate.New()
 	if err != nil {
 		log.Fatalln("error protovalidate", err)
 	}
 
-	// validating
 	if err := v.Validate(req); err != nil {
-		switch {
-		default:
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		}
+		return status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if err = s.auth.SetNewPassword(ctx, req.GetConfirmCode(), req.GetEmail(), req.GetNewPassword()); err != nil {
-		return nil, status.Error(codes.Unknown, err.Error())
+	return nil
+}
+
+// validateTokenRequest validates a request of a call authenticated by an
+// access token, which must be issued by the app of appID.
+func validateTokenRequest(req proto.Message, appID int64) error {
+	if err := validate(req); err != nil {
+		return err
 	}
 
-	return &ssov1.SetNewPasswordResponse{Success: true}, nil
+	if appID == emptyValue {
+		return status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	return nil
 }
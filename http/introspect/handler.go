@@ -0,0 +1,117 @@
+package httpintrospect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+)
+
+// Path is where the introspection endpoint is served.
+const Path = "/introspect"
+
+type Introspector interface {
+	AuthenticateApp(ctx context.Context, appID int64, secret string) (models.App, error)
+	IntrospectToken(ctx context.Context, token string, appID int64) (models.TokenIntrospection, error)
+}
+
+// Register registers token introspection handler on mux.
+func Register(mux *http.ServeMux, introspector Introspector) {
+	mux.Handle(Path, Handler(introspector))
+}
+
+type response struct {
+	Active    bool   `json:"active"`
+	Sub       string `json:"sub,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	AppID     int64  `json:"app_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+}
+
+// Handler serves token introspection as defined by RFC 7662.
+//
+// Callers authenticate as an app with client_secret_basic or client_secret_post,
+// so the endpoint can not be used to probe tokens anonymously.
+func Handler(introspector Introspector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+
+		appID, err := strconv.ParseInt(clientID, 10, 64)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+			writeError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+
+		if _, err = introspector.AuthenticateApp(r.Context(), appID, clientSecret); err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+				writeError(w, http.StatusUnauthorized, "invalid_client")
+				return
+			}
+
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		token := r.PostForm.Get("token")
+		if token == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		info, err := introspector.IntrospectToken(r.Context(), token, 0)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		resp := response{Active: info.Active}
+		if info.Active {
+			resp = response{
+				Active:    true,
+				Sub:       info.Subject,
+				ClientID:  strconv.FormatInt(info.AppID, 10),
+				AppID:     info.AppID,
+				Scope:     info.Scope,
+				TokenType: "Bearer",
+				Exp:       info.ExpiresAt.Unix(),
+				Iat:       info.IssuedAt.Unix(),
+			}
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,94 @@
+package httpintrospect
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+)
+
+// fakeIntrospector knows app 1 with the secret "secret" and reports every
+// token as active.
+type fakeIntrospector struct {
+	introspected int
+}
+
+func (i *fakeIntrospector) AuthenticateApp(ctx context.Context, appID int64, secret string) (models.App, error) {
+	if appID != 1 || secret != "secret" {
+		return models.App{}, auth.ErrInvalidCredentials
+	}
+
+	return models.App{ID: 1, Name: "app"}, nil
+}
+
+func (i *fakeIntrospector) IntrospectToken(ctx context.Context, token string, appID int64) (models.TokenIntrospection, error) {
+	i.introspected++
+	return models.TokenIntrospection{Active: true, AppID: 1, UserID: 2, Subject: "2"}, nil
+}
+
+func TestHandlerClientAuthentication(t *testing.T) {
+	tests := []struct {
+		name  string
+		basic []string
+		form  url.Values
+		want  int
+	}{
+		{"basic auth", []string{"1", "secret"}, nil, http.StatusOK},
+		{"post form", nil, url.Values{"client_id": {"1"}, "client_secret": {"secret"}}, http.StatusOK},
+		{"no credentials", nil, nil, http.StatusUnauthorized},
+		{"no secret", nil, url.Values{"client_id": {"1"}}, http.StatusUnauthorized},
+		{"wrong basic secret", []string{"1", "wrong"}, nil, http.StatusUnauthorized},
+		{"wrong post secret", nil, url.Values{"client_id": {"1"}, "client_secret": {"wrong"}}, http.StatusUnauthorized},
+		{"unknown client", []string{"3", "secret"}, nil, http.StatusUnauthorized},
+		{"malformed client", []string{"app", "secret"}, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			introspector := &fakeIntrospector{}
+
+			form := url.Values{"token": {"token"}}
+			for k, v := range tt.form {
+				form[k] = v
+			}
+
+			req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.basic != nil {
+				req.SetBasicAuth(tt.basic[0], tt.basic[1])
+			}
+
+			rec := httptest.NewRecorder()
+			Handler(introspector).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+
+			var resp map[string]interface{}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			if tt.want == http.StatusUnauthorized {
+				if resp["error"] != "invalid_client" || rec.Header().Get("WWW-Authenticate") == "" {
+					t.Fatalf("response = %v, want an invalid_client challenge", resp)
+				}
+				if introspector.introspected > 0 {
+					t.Fatalf("token introspected for an unauthenticated client")
+				}
+				return
+			}
+
+			if resp["active"] != true || resp["sub"] != "2" {
+				t.Fatalf("response = %v, want the active token", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package httpjwks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+)
+
+// Path is where the key set is served, as advertised by OpenID Connect discovery.
+const Path = "/.well-known/jwks.json"
+
+type KeySource interface {
+	JWKS(ctx context.Context) (jwtn.JWKS, error)
+}
+
+// Register registers JWKS handler on mux.
+func Register(mux *http.ServeMux, keys KeySource) {
+	mux.Handle(Path, Handler(keys))
+}
+
+// Handler serves the public signing keys as JSON Web Key Set.
+func Handler(keys KeySource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		jwks, err := keys.JWKS(r.Context())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		// keys rotate rarely, let resource servers cache them for a while
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(jwks)
+	})
+}
@@ -0,0 +1,482 @@
+package httpoidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	httpintrospect "github.com/orenvadi/auth-grpc/http/introspect"
+	httpjwks "github.com/orenvadi/auth-grpc/http/jwks"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/oidc"
+)
+
+type Provider interface {
+	Issuer() string
+	ValidateClient(ctx context.Context, req oidc.AuthorizationRequest) (models.App, error)
+	ValidateRequest(ctx context.Context, app models.App, req oidc.AuthorizationRequest) error
+	IssueCode(ctx context.Context, app models.App, session oidc.LoginSession, req oidc.AuthorizationRequest) (string, error)
+	ExchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI, codeVerifier string) (oidc.TokenResponse, error)
+	Refresh(ctx context.Context, clientID, clientSecret, refreshToken string) (oidc.TokenResponse, error)
+	ClientCredentials(ctx context.Context, clientID, clientSecret, scope string) (oidc.TokenResponse, error)
+	UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error)
+	Login(ctx context.Context, app models.App, email, password string) (session oidc.LoginSession, mfaToken string, err error)
+	CompleteLogin(ctx context.Context, app models.App, mfaToken, code string) (oidc.LoginSession, error)
+	NewSession(ctx context.Context, session oidc.LoginSession) (string, error)
+	Session(ctx context.Context, session string) (oidc.LoginSession, error)
+	SessionTTL() time.Duration
+}
+
+const (
+	DiscoveryPath     = "/.well-known/openid-configuration"
+	AuthorizePath     = "/authorize"
+	TokenPath         = "/token"
+	UserInfoPath      = "/userinfo"
+	sessionCookieName = "sso_session"
+	csrfCookieName    = "sso_csrf"
+	csrfFieldName     = "csrf_token"
+	csrfTokenBytes    = 32
+)
+
+type handler struct {
+	log      *slog.Logger
+	provider Provider
+	signAlg  string
+}
+
+// Register registers OpenID Connect endpoints on mux. signAlg is the
+// algorithm ID tokens are signed with, as advertised through discovery.
+func Register(mux *http.ServeMux, log *slog.Logger, provider Provider, signAlg string) {
+	h := &handler{log: log, provider: provider, signAlg: signAlg}
+
+	mux.HandleFunc(DiscoveryPath, h.discovery)
+	mux.HandleFunc(AuthorizePath, h.authorize)
+	mux.HandleFunc(TokenPath, h.token)
+	mux.HandleFunc(UserInfoPath, h.userInfo)
+}
+
+func (h *handler) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := h.provider.Issuer()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + AuthorizePath,
+		"token_endpoint":                        issuer + TokenPath,
+		"userinfo_endpoint":                     issuer + UserInfoPath,
+		"jwks_uri":                              issuer + httpjwks.Path,
+		"introspection_endpoint":                issuer + httpintrospect.Path,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token", "client_credentials"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{h.signAlg},
+		"scopes_supported":                      oidc.SupportedScopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{oidc.CodeChallengeS256},
+		"prompt_values_supported":               []string{"none", "login"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "given_name", "family_name", "updated_at",
+			"email", "email_verified", "phone_number",
+		},
+	})
+}
+
+func (h *handler) authorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+
+	req := oidc.AuthorizationRequest{
+		ResponseType:        r.Form.Get("response_type"),
+		ClientID:            r.Form.Get("client_id"),
+		RedirectURI:         r.Form.Get("redirect_uri"),
+		Scope:               r.Form.Get("scope"),
+		State:               r.Form.Get("state"),
+		Nonce:               r.Form.Get("nonce"),
+		CodeChallenge:       r.Form.Get("code_challenge"),
+		CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+		Prompt:              r.Form.Get("prompt"),
+	}
+
+	app, err := h.provider.ValidateClient(r.Context(), req)
+	if err != nil {
+		// never redirect to an unverified redirect_uri
+		h.log.WarnContext(r.Context(), "invalid authorization request", sl.Err(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = h.provider.ValidateRequest(r.Context(), app, req); err != nil {
+		redirectError(w, r, req, err)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !validCSRFToken(r) {
+			h.log.WarnContext(r.Context(), "login form posted without a valid CSRF token")
+			http.Error(w, "invalid CSRF token, reload the page and sign in again", http.StatusForbidden)
+			return
+		}
+
+		h.login(w, r, app, req)
+		return
+	}
+
+	if req.Prompt != "login" {
+		if cookie, err := r.Cookie(sessionCookieName); err == nil {
+			if session, err := h.provider.Session(r.Context(), cookie.Value); err == nil {
+				h.redirectWithCode(w, r, app, session, req)
+				return
+			}
+		}
+	}
+
+	if req.Prompt == "none" {
+		redirectError(w, r, req, oidc.ErrLoginRequired)
+		return
+	}
+
+	h.renderLogin(w, r, app, req, "", "")
+}
+
+// login handles both steps of the login form: the password, and the second
+// factor code if the user has two-factor authentication enabled.
+func (h *handler) login(w http.ResponseWriter, r *http.Request, app models.App, req oidc.AuthorizationRequest) {
+	var (
+		session oidc.LoginSession
+		err     error
+	)
+
+	if mfaToken := r.PostForm.Get("mfa_token"); mfaToken != "" {
+		session, err = h.provider.CompleteLogin(r.Context(), app, mfaToken, r.PostForm.Get("code"))
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidMFACode) || errors.Is(err, auth.ErrInvalidToken) {
+				// the challenge can be answered only once
+				h.renderLogin(w, r, app, req, "", "Invalid code, please sign in again")
+				return
+			}
+
+			h.log.ErrorContext(r.Context(), "failed to complete login", sl.Err(err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		session, mfaToken, err = h.provider.Login(r.Context(), app, r.PostForm.Get("email"), r.PostForm.Get("password"))
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				h.renderLogin(w, r, app, req, "", "Invalid email or password")
+				return
+			}
+			if errors.Is(err, auth.ErrUserDisabled) {
+				h.renderLogin(w, r, app, req, "", "Your account is disabled")
+				return
+			}
+
+			h.log.ErrorContext(r.Context(), "failed to log in", sl.Err(err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if mfaToken != "" {
+			h.renderLogin(w, r, app, req, mfaToken, "")
+			return
+		}
+	}
+
+	sessionToken, err := h.provider.NewSession(r.Context(), session)
+	if err != nil {
+		h.log.ErrorContext(r.Context(), "failed to create session", sl.Err(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    sessionToken,
+		Path:     "/",
+		MaxAge:   int(h.provider.SessionTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.provider.Issuer(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	h.redirectWithCode(w, r, app, session, req)
+}
+
+func (h *handler) redirectWithCode(w http.ResponseWriter, r *http.Request, app models.App, session oidc.LoginSession, req oidc.AuthorizationRequest) {
+	code, err := h.provider.IssueCode(r.Context(), app, session, req)
+	if err != nil {
+		h.log.ErrorContext(r.Context(), "failed to issue authorization code", sl.Err(err))
+		redirectError(w, r, req, errors.New("server_error"))
+		return
+	}
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	redirect(w, r, req.RedirectURI, params)
+}
+
+func (h *handler) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOAuthError(w, http.StatusMethodNotAllowed, oidc.ErrInvalidRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, oidc.ErrInvalidRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	var (
+		resp oidc.TokenResponse
+		err  error
+	)
+
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case "authorization_code":
+		resp, err = h.provider.ExchangeCode(r.Context(), clientID, clientSecret,
+			r.PostForm.Get("code"), r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+	case "refresh_token":
+		resp, err = h.provider.Refresh(r.Context(), clientID, clientSecret, r.PostForm.Get("refresh_token"))
+	case "client_credentials":
+		resp, err = h.provider.ClientCredentials(r.Context(), clientID, clientSecret, r.PostForm.Get("scope"))
+	default:
+		err = oidc.ErrUnsupportedGrantType
+	}
+	if err != nil {
+		h.log.InfoContext(r.Context(), "token request failed", sl.Err(err))
+
+		status := http.StatusBadRequest
+		if errors.Is(err, oidc.ErrInvalidClient) {
+			status = http.StatusUnauthorized
+		}
+		writeOAuthError(w, status, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *handler) userInfo(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		writeOAuthError(w, http.StatusUnauthorized, oidc.ErrInvalidToken)
+		return
+	}
+
+	info, err := h.provider.UserInfo(r.Context(), strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeOAuthError(w, http.StatusUnauthorized, oidc.ErrInvalidToken)
+			return
+		}
+
+		h.log.ErrorContext(r.Context(), "failed to get user info", sl.Err(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// redirectError sends an OAuth 2.0 error to the already verified redirect URI.
+func redirectError(w http.ResponseWriter, r *http.Request, req oidc.AuthorizationRequest, err error) {
+	params := url.Values{"error": {oauthErrorCode(err)}}
+	if desc := err.Error(); desc != oauthErrorCode(err) {
+		params.Set("error_description", desc)
+	}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	redirect(w, r, req.RedirectURI, params)
+}
+
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, err error) {
+	body := map[string]string{"error": oauthErrorCode(err)}
+	if desc := err.Error(); desc != body["error"] {
+		body["error_description"] = desc
+	}
+
+	writeJSON(w, status, body)
+}
+
+// oauthErrorCode extracts the OAuth 2.0 error code from errors of the oidc service.
+func oauthErrorCode(err error) string {
+	for _, known := range []error{
+		oidc.ErrInvalidRequest,
+		oidc.ErrInvalidClient,
+		oidc.ErrInvalidGrant,
+		oidc.ErrUnsupportedGrantType,
+		oidc.ErrUnsupportedResponse,
+		oidc.ErrAccessDenied,
+		oidc.ErrLoginRequired,
+		oidc.ErrInvalidToken,
+		oidc.ErrInvalidScope,
+		oidc.ErrUnauthorizedClient,
+	} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+
+	return "server_error"
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Sign in to {{.AppName}}</title>
+</head>
+<body style="font-family: sans-serif; max-width: 360px; margin: 80px auto;">
+    {{if .LogoURI}}<p style="text-align: center;"><img src="{{.LogoURI}}" alt="{{.AppName}}" style="max-width: 96px; max-height: 96px;"></p>{{end}}
+    <h1 style="text-align: center;">Sign in</h1>
+    <p style="text-align: center;">to continue to <b>{{.AppName}}</b></p>
+    {{if .Error}}<p style="color: #b00020; text-align: center;">{{.Error}}</p>{{end}}
+    <form method="post" action="{{.Action}}">
+        <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+        {{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+        {{end}}
+        {{if .MFAToken}}
+        <input type="hidden" name="mfa_token" value="{{.MFAToken}}">
+        <p>Enter the code from your authenticator app or a recovery code.</p>
+        <p><input type="text" name="code" placeholder="Code" required autofocus autocomplete="one-time-code" style="width: 100%; padding: 8px;"></p>
+        <p><button type="submit" style="width: 100%; padding: 10px;">Verify</button></p>
+        {{else}}
+        <p><input type="email" name="email" placeholder="Email" required autofocus style="width: 100%; padding: 8px;"></p>
+        <p><input type="password" name="password" placeholder="Password" required style="width: 100%; padding: 8px;"></p>
+        <p><button type="submit" style="width: 100%; padding: 10px;">Sign in</button></p>
+        {{end}}
+    </form>
+</body>
+</html>
+`))
+
+// renderLogin renders the password step of the login form, or the second
+// factor step if mfaToken is set.
+func (h *handler) renderLogin(w http.ResponseWriter, r *http.Request, app models.App, req oidc.AuthorizationRequest, mfaToken, errMsg string) {
+	csrfToken, err := h.csrfToken(w, r)
+	if err != nil {
+		h.log.ErrorContext(r.Context(), "failed to generate CSRF token", sl.Err(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := map[string]string{
+		"response_type":         req.ResponseType,
+		"client_id":             req.ClientID,
+		"redirect_uri":          req.RedirectURI,
+		"scope":                 req.Scope,
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// the login page must not be framed by other sites
+	w.Header().Set("X-Frame-Options", "DENY")
+
+	if errMsg != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	_ = loginPage.Execute(w, map[string]interface{}{
+		"AppName":   app.Name,
+		"LogoURI":   app.LogoURI,
+		"Action":    AuthorizePath,
+		"Params":    params,
+		"MFAToken":  mfaToken,
+		"CSRFToken": csrfToken,
+		"Error":     errMsg,
+	})
+}
+
+// csrfToken returns the CSRF token of the browser, setting a new one if it
+// has none. The login form echoes it back, which a cross-site form can not
+// do: it can neither read the cookie nor, being SameSite=Strict, send it.
+func (h *handler) csrfToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	if cookie, err := r.Cookie(csrfCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	token, err := rnd.GenerateToken(csrfTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    token,
+		Path:     AuthorizePath,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.provider.Issuer(), "https://"),
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return token, nil
+}
+
+// validCSRFToken reports whether the posted form carries the CSRF token of
+// the cookie.
+func validCSRFToken(r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostForm.Get(csrfFieldName))) == 1
+}
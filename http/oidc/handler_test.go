@@ -0,0 +1,108 @@
+package httpoidc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/oidc"
+)
+
+// fakeProvider accepts every authorization request and rejects every
+// password, the tests cover the login form only.
+type fakeProvider struct {
+	Provider
+	logins int
+}
+
+func (p *fakeProvider) Issuer() string {
+	return "https://sso.example.com"
+}
+
+func (p *fakeProvider) ValidateClient(ctx context.Context, req oidc.AuthorizationRequest) (models.App, error) {
+	return models.App{ID: 1, Name: "app"}, nil
+}
+
+func (p *fakeProvider) ValidateRequest(ctx context.Context, app models.App, req oidc.AuthorizationRequest) error {
+	return nil
+}
+
+func (p *fakeProvider) Login(ctx context.Context, app models.App, email, password string) (oidc.LoginSession, string, error) {
+	p.logins++
+	return oidc.LoginSession{}, "", auth.ErrInvalidCredentials
+}
+
+var csrfInput = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+func TestLoginCSRF(t *testing.T) {
+	provider := &fakeProvider{}
+	mux := http.NewServeMux()
+	Register(mux, slog.New(slog.NewTextHandler(io.Discard, nil)), provider, "RS256")
+
+	query := url.Values{"response_type": {"code"}, "client_id": {"1"}, "redirect_uri": {"https://app.example.com/callback"}}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, AuthorizePath+"?"+query.Encode(), nil))
+
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == csrfCookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.SameSite != http.SameSiteStrictMode || !cookie.HttpOnly {
+		t.Fatalf("CSRF cookie = %+v, want a strict HttpOnly cookie", cookie)
+	}
+
+	m := csrfInput.FindStringSubmatch(rec.Body.String())
+	if m == nil || m[1] != cookie.Value {
+		t.Fatalf("login form does not carry the CSRF token of the cookie")
+	}
+
+	tests := []struct {
+		name   string
+		cookie bool
+		token  string
+		want   int
+	}{
+		{"no cookie", false, cookie.Value, http.StatusForbidden},
+		{"no token", true, "", http.StatusForbidden},
+		{"wrong token", true, "forged", http.StatusForbidden},
+		{"valid token", true, cookie.Value, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.logins = 0
+
+			form := url.Values{"email": {"user@example.com"}, "password": {"password"}, "csrf_token": {tt.token}}
+			for k, v := range query {
+				form[k] = v
+			}
+
+			req := httptest.NewRequest(http.MethodPost, AuthorizePath, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.cookie {
+				req.AddCookie(cookie)
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if wantLogin := tt.want != http.StatusForbidden; (provider.logins > 0) != wantLogin {
+				t.Fatalf("password checked = %v, want %v", provider.logins > 0, wantLogin)
+			}
+		})
+	}
+}
@@ -1,36 +1,184 @@
 package app
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"sync"
 	"time"
 
+	httpintrospect "github.com/orenvadi/auth-grpc/http/introspect"
+	httpjwks "github.com/orenvadi/auth-grpc/http/jwks"
+	httpoidc "github.com/orenvadi/auth-grpc/http/oidc"
 	grpcapp "github.com/orenvadi/auth-grpc/internal/app/grpc"
+	httpapp "github.com/orenvadi/auth-grpc/internal/app/http"
+	"github.com/orenvadi/auth-grpc/internal/config"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/mailer"
+	"github.com/orenvadi/auth-grpc/internal/lib/metrics"
+	"github.com/orenvadi/auth-grpc/internal/lib/tracing"
+	"github.com/orenvadi/auth-grpc/internal/services/admin"
 	"github.com/orenvadi/auth-grpc/internal/services/auth"
-	"github.com/orenvadi/auth-grpc/internal/storage/postgres"
-	// "github.com/orenvadi/auth-grpc/internal/storage/sqlite"
+	"github.com/orenvadi/auth-grpc/internal/services/authz"
+	keysvc "github.com/orenvadi/auth-grpc/internal/services/keys"
+	"github.com/orenvadi/auth-grpc/internal/services/oidc"
+	"github.com/orenvadi/auth-grpc/internal/services/outbox"
+	"github.com/orenvadi/auth-grpc/internal/services/passkey"
 )
 
 type App struct {
 	GRPCSrv *grpcapp.App
+	HTTPSrv *httpapp.App
+
+	stopJobs context.CancelFunc
+	jobs     sync.WaitGroup
+
+	stopTracing func(context.Context) error
 }
 
-func New(log *slog.Logger, grpcPort int, storageDSN string, tokenTTL time.Duration) *App {
+func New(log *slog.Logger, cfg *config.Config) *App {
+	// before the storage, so its queries are traced
+	stopTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint, cfg.Tracing.SampleRatio)
+	if err != nil {
+		panic(fmt.Sprintf("failed to set up tracing: %s", err))
+	}
+
 	// DONE init storage
 
-	// storage, err := sqlite.New(storagePath)
-	storage, err := postgres.New(fmt.Sprintf("postgres://%s?sslmode=disable", storageDSN))
+	storage, err := NewStorage(cfg.Storage, cfg.Signing.Alg)
 	if err != nil {
 		panic(err)
 	}
 
 	// DONE init auth server (auth)
 
-	authService := auth.New(log, storage, storage, storage, storage, storage, storage, tokenTTL)
+	var (
+		keys       jwtn.KeyProvider
+		keyRotator auth.KeyRotator
+		keyManager *keysvc.Manager
+	)
+
+	switch {
+	case cfg.Signing.Alg == jwtn.AlgHS256:
+		keys = jwtn.NewStaticKeys(nil, time.Time{})
+	case cfg.Signing.KeyFile != "":
+		key, err := jwtn.LoadKey(cfg.Signing.Alg, cfg.Signing.KeyFile)
+		if err != nil {
+			panic(fmt.Sprintf("failed to load signing key: %s", err))
+		}
+		keys = jwtn.NewStaticKeys(&key, cfg.Signing.LegacyHS256Until)
+	default:
+		keyManager = keysvc.New(log, storage, cfg.Signing.Alg, cfg.Signing.RotationInterval, cfg.Signing.PublishDelay, cfg.TokenTTL, cfg.Signing.LegacyHS256Until)
+		if err = keyManager.Tick(context.Background()); err != nil {
+			panic(fmt.Sprintf("failed to initialize signing keys: %s", err))
+		}
+		keys, keyRotator = keyManager, keyManager
+	}
+
+	mail, err := newMailer(cfg.Mail)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize mailer: %s", err))
+	}
+
+	outboxWorker := outbox.New(log, storage, mail, cfg.Mail.Outbox.MaxAttempts, cfg.Mail.Outbox.Backoff, cfg.Mail.Outbox.MaxBackoff, cfg.Mail.Outbox.Retention)
+
+	authService := auth.New(log, storage, storage, storage, storage, storage, storage, storage, storage, keys, keyRotator, storage, storage, storage, storage, cfg.TOTPIssuer, cfg.TokenTTL, cfg.RefreshTokenTTL, cfg.OrgInvitationTTL)
+
+	webAuthn, err := passkey.NewWebAuthn(cfg.WebAuthn.RPID, cfg.WebAuthn.RPName, cfg.WebAuthn.Origins)
+	if err != nil {
+		panic(fmt.Sprintf("invalid webauthn config: %s", err))
+	}
+
+	passkeyService := passkey.New(log, webAuthn, authService, storage, storage, storage, storage)
+
+	authzConfig, err := authz.LoadConfig(cfg.AuthzNamespacesFile)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load authz namespaces: %s", err))
+	}
+
+	authzService := authz.New(log, storage, authzConfig)
+
+	adminService := admin.New(log, storage, storage, cfg.Signing.Alg == jwtn.AlgHS256)
 
-	grpcApp := grpcapp.New(log, authService, storage, grpcPort)
+	grpcApp := grpcapp.New(log, authService, passkeyService, authzService, adminService, storage, mail, cfg.GRPC.Port, cfg.GRPC.Timeout, cfg.GRPC.DrainDelay)
+	// report the health right away instead of after the first interval
+	_ = grpcApp.CheckHealth(context.Background())
 
-	return &App{
-		GRPCSrv: grpcApp,
+	// the memory storage has no connection pool
+	if db, ok := storage.(interface{ Stats() sql.DBStats }); ok {
+		metrics.RegisterDBStats(cfg.Storage.Driver, db.Stats)
 	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	httpjwks.Register(mux, authService)
+	httpintrospect.Register(mux, authService)
+
+	// ID tokens must be verifiable by relying parties without the app secret
+	if cfg.Signing.Alg != jwtn.AlgHS256 {
+		oidcProvider := oidc.New(log, authService, storage, storage, storage, keys, storage, cfg.OIDC.Issuer, cfg.TokenTTL, cfg.OIDC.SessionTTL)
+		httpoidc.Register(mux, log, oidcProvider, cfg.Signing.Alg)
+	}
+
+	httpApp := httpapp.New(log, mux, cfg.HTTP.Port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := &App{
+		GRPCSrv:     grpcApp,
+		HTTPSrv:     httpApp,
+		stopJobs:    cancel,
+		stopTracing: stopTracing,
+	}
+
+	a.runPeriodically(ctx, log, "purge revoked tokens", cfg.RevokedTokensCleanupInterval, authService.PurgeRevokedTokens)
+	a.runPeriodically(ctx, log, "purge refresh tokens", cfg.RevokedTokensCleanupInterval, authService.PurgeExpiredRefreshTokens)
+	a.runPeriodically(ctx, log, "purge organization invitations", cfg.RevokedTokensCleanupInterval, authService.PurgeExpiredOrgInvitations)
+	a.runPeriodically(ctx, log, "purge webauthn sessions", cfg.RevokedTokensCleanupInterval, passkeyService.PurgeSessions)
+	a.runPeriodically(ctx, log, "deliver emails", cfg.Mail.Outbox.PollInterval, outboxWorker.Deliver)
+	a.runPeriodically(ctx, log, "purge sent emails", cfg.RevokedTokensCleanupInterval, outboxWorker.PurgeSent)
+	a.runPeriodically(ctx, log, "check health", cfg.GRPC.HealthCheckInterval, grpcApp.CheckHealth)
+	if keyManager != nil {
+		a.runPeriodically(ctx, log, "rotate signing keys", cfg.Signing.CheckInterval, keyManager.Tick)
+	}
+
+	return a
+}
+
+// healthMailer delivers the outbox, and is pinged by health checks.
+type healthMailer interface {
+	outbox.Mailer
+	grpcapp.Pinger
+}
+
+func newMailer(cfg config.Mail) (healthMailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTP.Host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required by the smtp mail driver")
+		}
+		return mailer.NewSMTP(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.From), nil
+	case "file":
+		return mailer.NewFile(cfg.Dir, cfg.From)
+	case "memory":
+		return mailer.NewMemory(), nil
+	}
+
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+}
+
+// Stop stops background jobs and then the servers, and flushes the spans
+// left.
+func (a *App) Stop() {
+	a.stopJobs()
+	a.jobs.Wait()
+
+	a.HTTPSrv.Stop()
+	a.GRPCSrv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = a.stopTracing(ctx)
 }
@@ -4,37 +4,87 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"sync/atomic"
+	"time"
 
+	admingrpc "github.com/orenvadi/auth-grpc/grpc/admin"
 	authgrpc "github.com/orenvadi/auth-grpc/grpc/auth"
-	"github.com/orenvadi/auth-grpc/internal/storage/postgres"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 
 	// "github.com/orenvadi/auth-grpc/internal/services/auth"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 type App struct {
-	log        *slog.Logger
-	gRPCServer *grpc.Server
-	db         *postgres.Storage
-	port       int
+	log          *slog.Logger
+	gRPCServer   *grpc.Server
+	health       *health.Server
+	dependencies map[string]Pinger
+	serving      atomic.Bool
+	drainDelay   time.Duration
+	db           Storage
+	port         int
 }
 
-// type Stoppable interface {
-// 	Stop() error
-// }
+// AuthService is the auth service, authenticating the calls as well.
+type AuthService interface {
+	authgrpc.Auth
+	Authenticator
+}
 
-// New creates new gRPCServer app.
-func New(log *slog.Logger, authService authgrpc.Auth, db *postgres.Storage, port int) *App {
-	gRPCServer := grpc.NewServer()
-	authgrpc.Register(gRPCServer, authService)
+// Storage is the database, closed when the server stops.
+type Storage interface {
+	Pinger
+	Stop() error
+}
 
-	return &App{
-		log:        log,
-		gRPCServer: gRPCServer,
-		db:         db,
-		port:       port,
+// New creates new gRPCServer app. Calls taking longer than timeout are
+// cancelled. The health of the server follows the database and the mailer,
+// see CheckHealth, and is NOT_SERVING for drainDelay before Stop stops it.
+func New(log *slog.Logger, authService AuthService, passkeys authgrpc.Passkeys, authz authgrpc.Authz, adminService admingrpc.Admin, db Storage, mailer Pinger, port int, timeout, drainDelay time.Duration) *App {
+	// the request ID goes first to appear in all logs, recovery next to
+	// catch panics of the other interceptors
+	gRPCServer := grpc.NewServer(
+		// starts a span per call continuing the W3C trace context of the metadata
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			UnaryRequestIDInterceptor(),
+			UnaryRecoveryInterceptor(log),
+			UnaryLoggingInterceptor(log),
+			UnaryMetricsInterceptor(),
+			UnaryDeadlineInterceptor(timeout),
+			UnaryAuthInterceptor(log, authService),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamRequestIDInterceptor(),
+			StreamRecoveryInterceptor(log),
+			StreamLoggingInterceptor(log),
+			StreamMetricsInterceptor(),
+			StreamDeadlineInterceptor(timeout),
+			StreamAuthInterceptor(log, authService),
+		),
+	)
+	authgrpc.Register(gRPCServer, authService, passkeys, authz)
+	admingrpc.Register(gRPCServer, adminService)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(gRPCServer, healthServer)
+
+	a := &App{
+		log:          log,
+		gRPCServer:   gRPCServer,
+		health:       healthServer,
+		dependencies: map[string]Pinger{"storage": db, "mailer": mailer},
+		drainDelay:   drainDelay,
+		db:           db,
+		port:         port,
 	}
+	a.serving.Store(true)
+
+	return a
 }
 
 func (a *App) MustRun() {
@@ -72,15 +122,19 @@ func (a *App) Run() error {
 
 // Stop stops gRPCServer.
 func (a *App) Stop() {
-	const op = "grpcapp.Run"
+	const op = "grpcapp.Stop"
 
 	log := a.log.With(slog.String("op: ", op))
 	log.Info("stopping gRPC server", slog.Int("port", a.port))
 
+	// load balancers stop sending new calls before the server stops accepting them
+	a.health.Shutdown()
+	time.Sleep(a.drainDelay)
+
 	a.gRPCServer.GracefulStop()
 
 	if err := a.db.Stop(); err != nil {
-		panic("could not stop postgres connection")
+		panic("could not stop storage connection")
 	}
 	log.Info("DB connection closed")
 }
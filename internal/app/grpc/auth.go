@@ -0,0 +1,201 @@
+package grpcapp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Policy is who may call a method.
+type Policy int
+
+const (
+	// PolicyAuthenticated requires the access token of a user. It is the
+	// policy of methods missing from the table, so new methods are not
+	// exposed by accident.
+	PolicyAuthenticated Policy = iota
+	// PolicyPublic lets anyone call the method, e.g. to log in.
+	PolicyPublic
+	// PolicyAdmin requires the access token of an admin who logged in with
+	// a second factor.
+	PolicyAdmin
+	// PolicyUserOrClient requires the access token of a user or a client
+	// token of an app. The handler decides what the caller may see.
+	PolicyUserOrClient
+	// PolicyClient requires a client token, which an app gets with its
+	// secret through the client credentials grant.
+	PolicyClient
+)
+
+// policies maps full method names, or service names ending with a slash,
+// to their policy.
+var policies = map[string]Policy{
+	"/auth.Auth/Register":                PolicyPublic,
+	"/auth.Auth/Login":                   PolicyPublic,
+	"/auth.Auth/IsAdmin":                 PolicyPublic,
+	"/auth.Auth/Logout":                  PolicyPublic, // the access token is in the request or the metadata, revoked ones succeed again
+	"/auth.Auth/SendCodeToResetPassword": PolicyPublic,
+	"/auth.Auth/SetNewPassword":          PolicyPublic,
+	"/auth.Auth/RefreshToken":            PolicyPublic,
+	"/auth.Auth/GetJWKS":                 PolicyPublic,
+	"/auth.Auth/CompleteMFALogin":        PolicyPublic, // the MFA token is in the request
+	"/auth.Auth/BeginPasskeyLogin":       PolicyPublic,
+	"/auth.Auth/FinishPasskeyLogin":      PolicyPublic,
+
+	"/auth.Auth/CheckPermission":              PolicyAuthenticated,
+	"/auth.Auth/UpdateUser":                   PolicyAuthenticated,
+	"/auth.Auth/ConfirmUserEmail":             PolicyAuthenticated,
+	"/auth.Auth/GetUserData":                  PolicyAuthenticated,
+	"/auth.Auth/EnrollTOTP":                   PolicyAuthenticated,
+	"/auth.Auth/VerifyTOTPEnrollment":         PolicyAuthenticated,
+	"/auth.Auth/BeginPasskeyRegistration":     PolicyAuthenticated,
+	"/auth.Auth/FinishPasskeyRegistration":    PolicyAuthenticated,
+	"/auth.Auth/CreateOrganization":           PolicyAuthenticated,
+	"/auth.Auth/InviteToOrganization":         PolicyAuthenticated,
+	"/auth.Auth/AcceptOrganizationInvitation": PolicyAuthenticated,
+	"/auth.Auth/RemoveOrganizationMember":     PolicyAuthenticated,
+	"/auth.Auth/ListMemberships":              PolicyAuthenticated,
+	"/auth.Auth/ListOrganizationMembers":      PolicyAuthenticated,
+	"/auth.Auth/SwitchOrganization":           PolicyAuthenticated,
+
+	"/auth.Auth/Check":       PolicyUserOrClient,
+	"/auth.Auth/Expand":      PolicyUserOrClient,
+	"/auth.Auth/ListObjects": PolicyUserOrClient,
+
+	"/auth.Auth/IntrospectToken": PolicyClient,
+
+	"/auth.Auth/RotateSigningKeys":   PolicyAdmin,
+	"/auth.Auth/ListSigningKeys":     PolicyAdmin,
+	"/auth.Auth/ListOutboxEmails":    PolicyAdmin,
+	"/auth.Auth/RetryOutboxEmail":    PolicyAdmin,
+	"/auth.Auth/CreateRole":          PolicyAdmin,
+	"/auth.Auth/DeleteRole":          PolicyAdmin,
+	"/auth.Auth/ListRoles":           PolicyAdmin,
+	"/auth.Auth/CreatePermission":    PolicyAdmin,
+	"/auth.Auth/DeletePermission":    PolicyAdmin,
+	"/auth.Auth/ListPermissions":     PolicyAdmin,
+	"/auth.Auth/GrantPermission":     PolicyAdmin,
+	"/auth.Auth/RevokePermission":    PolicyAdmin,
+	"/auth.Auth/AssignRole":          PolicyAdmin,
+	"/auth.Auth/UnassignRole":        PolicyAdmin,
+	"/auth.Auth/ListUserRoles":       PolicyAdmin,
+	"/auth.Auth/WriteRelationTuples": PolicyAdmin,
+
+	"/auth.AdminService/": PolicyAdmin,
+
+	"/grpc.health.v1.Health/":                    PolicyPublic,
+	"/grpc.reflection.v1.ServerReflection/":      PolicyPublic,
+	"/grpc.reflection.v1alpha.ServerReflection/": PolicyPublic,
+}
+
+// policy returns the policy of a method, or of its service.
+func policy(fullMethod string) Policy {
+	if p, ok := policies[fullMethod]; ok {
+		return p
+	}
+
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+
+	return policies["/"+service+"/"]
+}
+
+// Authenticator verifies the access tokens of incoming requests.
+type Authenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (models.Principal, error)
+}
+
+// UnaryAuthInterceptor authenticates the bearer token of calls that are not
+// public and passes the caller to the handlers as a models.Principal, see
+// jwtn.PrincipalFromContext.
+func UnaryAuthInterceptor(log *slog.Logger, authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, log, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor is UnaryAuthInterceptor for streaming calls.
+func StreamAuthInterceptor(log *slog.Logger, authenticator Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), log, authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, log *slog.Logger, authenticator Authenticator, fullMethod string) (context.Context, error) {
+	pol := policy(fullMethod)
+	if pol == PolicyPublic {
+		return ctx, nil
+	}
+
+	token, err := jwtn.BearerToken(ctx)
+	if err != nil || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing access token")
+	}
+
+	p, err := authenticator.AuthenticateToken(ctx, token)
+	if err != nil {
+		return nil, authenticationError(ctx, log, fullMethod, err)
+	}
+
+	switch {
+	case pol == PolicyClient && p.UserID != 0:
+		return nil, status.Error(codes.PermissionDenied, "client token required")
+	// client tokens authenticate apps, the other methods act on behalf of a user
+	case p.UserID == 0 && pol != PolicyUserOrClient && pol != PolicyClient:
+		return nil, status.Error(codes.PermissionDenied, "user access token required")
+	}
+
+	if pol == PolicyAdmin {
+		switch {
+		case !p.IsAdmin:
+			return nil, status.Error(codes.PermissionDenied, "admin permissions required")
+		case !slices.Contains(p.AuthMethods, auth.AuthMethodMFA):
+			return nil, status.Error(codes.PermissionDenied, "admin permissions require two-factor authentication")
+		}
+	}
+
+	return jwtn.ContextWithPrincipal(ctx, p), nil
+}
+
+// authenticationError logs why a token was rejected and hides it from the
+// caller, the cause may tell an attacker which part of a forged token failed.
+func authenticationError(ctx context.Context, log *slog.Logger, fullMethod string, err error) error {
+	log = log.With(slog.String("method", fullMethod), slog.String("request_id", RequestID(ctx)))
+
+	if errors.Is(err, auth.ErrInvalidToken) {
+		log.InfoContext(ctx, "invalid access token", sl.Err(err))
+	} else {
+		log.ErrorContext(ctx, "failed to authenticate access token", sl.Err(err))
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid token")
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,79 @@
+package grpcapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAuthenticator maps tokens to their principals.
+type fakeAuthenticator map[string]models.Principal
+
+func (f fakeAuthenticator) AuthenticateToken(ctx context.Context, token string) (models.Principal, error) {
+	if token == "broken" {
+		return models.Principal{}, errors.New("storage is down")
+	}
+
+	p, ok := f[token]
+	if !ok {
+		return models.Principal{}, auth.ErrInvalidToken
+	}
+
+	return p, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	authenticator := fakeAuthenticator{
+		"user":   {UserID: 1, AppID: 1},
+		"client": {AppID: 1},
+		"admin":  {UserID: 2, AppID: 1, IsAdmin: true, AuthMethods: []string{auth.AuthMethodPassword, auth.AuthMethodMFA}},
+	}
+
+	tests := []struct {
+		method string
+		token  string
+		want   codes.Code
+	}{
+		{"/auth.Auth/Login", "", codes.OK},
+		{"/auth.Auth/GetUserData", "", codes.Unauthenticated},
+		{"/auth.Auth/GetUserData", "forged", codes.Unauthenticated},
+		{"/auth.Auth/GetUserData", "broken", codes.Unauthenticated},
+		{"/auth.Auth/GetUserData", "user", codes.OK},
+		{"/auth.Auth/GetUserData", "client", codes.PermissionDenied},
+		{"/auth.Auth/Check", "client", codes.OK},
+		{"/auth.Auth/Check", "user", codes.OK},
+		{"/auth.Auth/IntrospectToken", "", codes.Unauthenticated},
+		{"/auth.Auth/IntrospectToken", "user", codes.PermissionDenied},
+		{"/auth.Auth/IntrospectToken", "client", codes.OK},
+		{"/auth.AdminService/ListUsers", "user", codes.PermissionDenied},
+		{"/auth.AdminService/ListUsers", "admin", codes.OK},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.token, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+
+			_, err := authenticate(ctx, log, authenticator, tt.method)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("authenticate() = %v, want %v", got, tt.want)
+			}
+			// the cause is logged, not returned
+			if tt.want == codes.Unauthenticated && tt.token != "" && status.Convert(err).Message() != "invalid token" {
+				t.Fatalf("authenticate() message = %q, want %q", status.Convert(err).Message(), "invalid token")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package grpcapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	ssov1 "github.com/orenvadi/auth-grpc/protos/gen/go/proto/sso"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Pinger is a dependency the service can not work without.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// healthCheckTimeout bounds a single check, so a hanging dependency is
+// reported instead of blocking the checks.
+const healthCheckTimeout = 5 * time.Second
+
+// CheckHealth pings the dependencies and reports the service as NOT_SERVING
+// while any of them is unreachable.
+func (a *App) CheckHealth(ctx context.Context) error {
+	const op = "grpcapp.CheckHealth"
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var errs []error
+	for name, dep := range a.dependencies {
+		if err := dep.Ping(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	serving := err == nil
+	if a.serving.Swap(serving) != serving {
+		if serving {
+			a.log.InfoContext(ctx, "service is serving again", slog.String("op", op))
+		} else {
+			a.log.ErrorContext(ctx, "service is not serving", slog.String("op", op), sl.Err(err))
+		}
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if !serving {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	a.setServingStatus(status)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *App) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	// the empty name is the status of the whole server
+	for _, service := range []string{"", ssov1.Auth_ServiceDesc.ServiceName, ssov1.AdminService_ServiceDesc.ServiceName} {
+		a.health.SetServingStatus(service, status)
+	}
+}
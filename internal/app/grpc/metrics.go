@@ -0,0 +1,45 @@
+package grpcapp
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryMetricsInterceptor records the latency and the status code of calls.
+func UnaryMetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		observeCall(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamMetricsInterceptor is UnaryMetricsInterceptor for streaming calls.
+func StreamMetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		observeCall(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func observeCall(fullMethod string, start time.Time, err error) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	code := status.Code(err).String()
+
+	metrics.RPCDuration.WithLabelValues(service, method, code).Observe(time.Since(start).Seconds())
+	metrics.RPCs.WithLabelValues(service, method, code).Inc()
+}
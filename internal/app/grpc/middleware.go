@@ -0,0 +1,239 @@
+package grpcapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is the metadata key of the request ID. It is taken from
+// the incoming request if the client sets one, and sent back in the header.
+const RequestIDHeader = "x-request-id"
+
+const (
+	requestIDBytes     = 12
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+// RequestID returns the ID of the request set by the request ID interceptor.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// UnaryRecoveryInterceptor turns panics of handlers into codes.Internal
+// errors, so one bad request does not kill the process.
+func UnaryRecoveryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, log, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor is UnaryRecoveryInterceptor for streaming calls.
+func StreamRecoveryInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, log *slog.Logger, method string, r any) error {
+	log.ErrorContext(ctx, "panic in handler",
+		slog.String("method", method),
+		slog.String("request_id", RequestID(ctx)),
+		slog.String("panic", fmt.Sprint(r)),
+		slog.String("stack", string(debug.Stack())),
+	)
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryRequestIDInterceptor assigns an ID to every request, see RequestID.
+// The ID is sent back in the header and to the services called while
+// handling the request.
+func UnaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestIDInterceptor is UnaryRequestIDInterceptor for streaming calls.
+func StreamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	id := incomingRequestID(ctx)
+	if id == "" {
+		var err error
+		if id, err = rnd.GenerateToken(requestIDBytes); err != nil {
+			return ctx
+		}
+	}
+
+	// the header can not be sent only if the call has already failed
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+	ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, id)
+
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// incomingRequestID returns the request ID set by the client, if it is
+// reasonable to log.
+func incomingRequestID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	ids := md.Get(RequestIDHeader)
+	if len(ids) == 0 || len(ids[0]) > maxRequestIDLength {
+		return ""
+	}
+
+	for _, c := range ids[0] {
+		if c < '!' || c > '~' {
+			return ""
+		}
+	}
+
+	return ids[0]
+}
+
+// UnaryLoggingInterceptor logs every call with its status code and duration.
+func UnaryLoggingInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logCall(ctx, log, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor is UnaryLoggingInterceptor for streaming calls.
+func StreamLoggingInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		logCall(ss.Context(), log, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logCall(ctx context.Context, log *slog.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("request_id", RequestID(ctx)),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	}
+
+	log.LogAttrs(ctx, level, "grpc call", attrs...)
+}
+
+// UnaryDeadlineInterceptor limits how long a call may take. Clients may set
+// a shorter deadline, not a longer one.
+func UnaryDeadlineInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && status.Code(err) != codes.DeadlineExceeded {
+			return nil, status.Error(codes.DeadlineExceeded, "deadline exceeded")
+		}
+
+		return resp, err
+	}
+}
+
+// longLivedStreams are the streaming methods, or services ending with a
+// slash, that StreamDeadlineInterceptor does not limit. Their clients keep
+// them open to watch for changes.
+var longLivedStreams = map[string]bool{
+	"/grpc.health.v1.Health/Watch":               true,
+	"/grpc.reflection.v1.ServerReflection/":      true,
+	"/grpc.reflection.v1alpha.ServerReflection/": true,
+}
+
+// StreamDeadlineInterceptor is UnaryDeadlineInterceptor for streaming calls.
+// The long-lived streams of the health and reflection services are not
+// limited, new streaming methods are unless added to longLivedStreams.
+func StreamDeadlineInterceptor(timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if timeout <= 0 || longLived(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithTimeout(ss.Context(), timeout)
+		defer cancel()
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && status.Code(err) != codes.DeadlineExceeded {
+			return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+		}
+
+		return err
+	}
+}
+
+func longLived(fullMethod string) bool {
+	if longLivedStreams[fullMethod] {
+		return true
+	}
+
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+
+	return longLivedStreams["/"+service+"/"]
+}
@@ -0,0 +1,53 @@
+package grpcapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream is a server stream with only a context.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamDeadlineInterceptor(t *testing.T) {
+	interceptor := StreamDeadlineInterceptor(10 * time.Millisecond)
+
+	// handler blocks until the call is cancelled
+	handler := func(srv any, ss grpc.ServerStream) error {
+		select {
+		case <-ss.Context().Done():
+			return ss.Context().Err()
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		}
+	}
+
+	tests := []struct {
+		method string
+		want   codes.Code
+	}{
+		{"/auth.Auth/Export", codes.DeadlineExceeded},
+		{"/grpc.health.v1.Health/Watch", codes.OK},
+		{"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			err := interceptor(nil, fakeStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: tt.method, IsServerStream: true}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("interceptor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+)
+
+// runPeriodically runs job every interval in background until ctx is cancelled.
+// Failed runs are logged and retried on the next tick.
+func (a *App) runPeriodically(ctx context.Context, log *slog.Logger, name string, interval time.Duration, job func(ctx context.Context) error) {
+	log = log.With(slog.String("job", name))
+
+	a.jobs.Add(1)
+	go func() {
+		defer a.jobs.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.InfoContext(ctx, "background job stopped")
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil && ctx.Err() == nil {
+					log.ErrorContext(ctx, "background job failed", sl.Err(err))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	grpcapp "github.com/orenvadi/auth-grpc/internal/app/grpc"
+	"github.com/orenvadi/auth-grpc/internal/config"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/services/admin"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/services/authz"
+	keysvc "github.com/orenvadi/auth-grpc/internal/services/keys"
+	"github.com/orenvadi/auth-grpc/internal/services/oidc"
+	"github.com/orenvadi/auth-grpc/internal/services/outbox"
+	"github.com/orenvadi/auth-grpc/internal/services/passkey"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+	"github.com/orenvadi/auth-grpc/internal/storage/postgres"
+	"github.com/orenvadi/auth-grpc/internal/storage/sqlite"
+)
+
+// Storage is what every storage backend implements, the data of all services.
+type Storage interface {
+	auth.UserSaver
+	auth.UserProvider
+	auth.UserUpdater
+	auth.AppProvider
+	auth.EmailConfirmProvider
+	auth.PasswordResetter
+	auth.RefreshTokenProvider
+	auth.TokenRevoker
+	auth.MFAProvider
+	auth.OutboxProvider
+	auth.RBACProvider
+	auth.OrgProvider
+	keysvc.KeyStorage
+	outbox.Storage
+	passkey.UserProvider
+	passkey.AppProvider
+	passkey.CredentialProvider
+	passkey.SessionProvider
+	authz.TupleStore
+	admin.UserStore
+	admin.AppStore
+	oidc.UserProvider
+	oidc.AppProvider
+	oidc.AuthorizationCodeProvider
+	jwtn.RevocationChecker
+	grpcapp.Storage
+}
+
+// NewStorage opens the storage backend selected by cfg.Driver. signingAlg
+// decides whether the memory storage seeds its test app with a plain secret.
+func NewStorage(cfg config.Storage, signingAlg string) (Storage, error) {
+	switch cfg.Driver {
+	case "postgres":
+		return postgres.New(fmt.Sprintf("postgres://%s?sslmode=disable", cfg.DSN()))
+	case "sqlite":
+		return sqlite.New(cfg.Path)
+	case "memory":
+		return newMemoryStorage(signingAlg)
+	}
+
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// newMemoryStorage returns an empty memory storage with the test app the
+// migrations create, so clients can log in right away. The plain secret
+// is kept only if it signs the tokens of the app.
+func newMemoryStorage(signingAlg string) (*memory.Storage, error) {
+	const op = "app.newMemoryStorage"
+
+	storage := memory.New()
+
+	app := models.App{
+		Name:       "test",
+		SecretHash: appsecret.Hash("test-secret"),
+		GrantTypes: strings.Join(admin.GrantTypes, " "),
+	}
+	if signingAlg == jwtn.AlgHS256 {
+		app.Secret = "test-secret"
+	}
+
+	_, err := storage.SaveApp(context.Background(), app)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return storage, nil
+}
@@ -3,19 +3,38 @@ package config
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Env      string
-	Storage  Storage
-	TokenTTL time.Duration
-	GRPC     GRPC
+	Env             string
+	Storage         Storage
+	TokenTTL        time.Duration
+	RefreshTokenTTL time.Duration
+	// OrgInvitationTTL is how long invitations to organizations can be accepted.
+	OrgInvitationTTL time.Duration
+	GRPC             GRPC
+	HTTP             HTTP
+	Signing          Signing
+	OIDC             OIDC
+	TOTPIssuer       string
+	WebAuthn         WebAuthn
+	Mail             Mail
+	// AuthzNamespacesFile is the YAML namespace configuration of
+	// relationship-based authorization. If empty, no relations are declared.
+	AuthzNamespacesFile string
+	Tracing             Tracing
+
+	RevokedTokensCleanupInterval time.Duration
 }
 
 type Storage struct {
+	Driver string // postgres, sqlite or memory
+	// Path is the database file of the sqlite driver.
+	Path     string
 	User     string
 	Password string
 	Host     string
@@ -29,6 +48,95 @@ func (s Storage) DSN() string {
 type GRPC struct {
 	Port    int
 	Timeout time.Duration
+	// HealthCheckInterval is how often the database and the mailer are
+	// pinged to report the health of the server.
+	HealthCheckInterval time.Duration
+	// DrainDelay is how long the server reports NOT_SERVING on shutdown
+	// before it stops accepting calls, so load balancers move traffic away.
+	DrainDelay time.Duration
+}
+
+type Tracing struct {
+	Exporter string // otlp, stdout or none
+	// Endpoint is the host:port of the OTLP collector, if empty the
+	// OTEL_EXPORTER_OTLP_ENDPOINT variable or the default is used.
+	Endpoint    string
+	SampleRatio float64 // of traces not sampled by the caller
+}
+
+type HTTP struct {
+	Port int
+}
+
+// Signing configures the global token signing key.
+type Signing struct {
+	// Alg is one of HS256, RS256, ES256 or EdDSA. HS256 signs tokens
+	// with the secret of each app, the others with a global key.
+	Alg string
+	// KeyFile is a PEM encoded private key used as a static signing key.
+	// If empty, keys are kept in the database and rotated on schedule.
+	KeyFile string
+	// RotationInterval is how long a key signs tokens before the next one is published.
+	RotationInterval time.Duration
+	// PublishDelay is how long a new key is published before it starts signing.
+	PublishDelay time.Duration
+	// CheckInterval is how often the rotation schedule is checked.
+	CheckInterval time.Duration
+	// LegacyHS256Until is when tokens without a kid, signed with the app
+	// secrets before the switch from HS256, stop being accepted. Zero means
+	// they are rejected right away. Ignored when Alg is HS256.
+	LegacyHS256Until time.Time
+}
+
+// OIDC configures the OpenID Connect provider, served over HTTP
+// when tokens are signed with a global key.
+type OIDC struct {
+	// Issuer is the public base URL of the provider.
+	Issuer string
+	// SessionTTL is how long users stay logged in to the provider.
+	SessionTTL time.Duration
+}
+
+// WebAuthn configures the relying party passkeys are registered for.
+type WebAuthn struct {
+	// RPID is the domain passkeys are bound to, e.g. example.com.
+	RPID string
+	// RPName is shown by authenticators when creating a passkey.
+	RPName string
+	// Origins are the web origins allowed to use the passkeys.
+	Origins []string
+}
+
+// Mail configures how emails are delivered.
+type Mail struct {
+	// Driver is smtp, file or memory. The file driver writes emails to
+	// a maildir at Dir, the memory driver only keeps them in memory.
+	Driver string
+	// From is the sender address, e.g. "Elif SSO <noreply@example.com>".
+	From   string
+	Dir    string
+	SMTP   SMTP
+	Outbox Outbox
+}
+
+// Outbox configures background delivery of queued emails.
+type Outbox struct {
+	// PollInterval is how often the outbox is checked for emails to send.
+	PollInterval time.Duration
+	// MaxAttempts is how many times delivery is tried before the email is marked dead.
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt, doubled after every next one.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long sent emails are kept.
+	Retention time.Duration
+}
+
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
 }
 
 func MustLoad() *Config {
@@ -46,6 +154,16 @@ func MustLoad() *Config {
 		cfg.Env = "local"
 	}
 
+	cfg.Storage.Driver = viper.GetString("STORAGE_DRIVER")
+	if cfg.Storage.Driver == "" {
+		cfg.Storage.Driver = "postgres"
+	}
+
+	cfg.Storage.Path = viper.GetString("STORAGE_PATH")
+	if cfg.Storage.Path == "" {
+		cfg.Storage.Path = "./storage/local.db"
+	}
+
 	cfg.Storage.User = viper.GetString("STORAGE_USER")
 	if cfg.Storage.User == "" {
 		cfg.Storage.User = "postgres"
@@ -62,7 +180,7 @@ func MustLoad() *Config {
 	}
 
 	cfg.Storage.DbName = viper.GetString("STORAGE_DB_NAME")
-	if cfg.Storage.DbName == "" {
+	if cfg.Storage.DbName == "" && cfg.Storage.Driver == "postgres" {
 		panic("STORAGE_DB_NAME environment variable is required")
 	}
 
@@ -76,6 +194,9 @@ func MustLoad() *Config {
 	}
 	cfg.TokenTTL = tokenTTL
 
+	cfg.RefreshTokenTTL = durationOrDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	cfg.OrgInvitationTTL = durationOrDefault("ORG_INVITATION_TTL", 7*24*time.Hour)
+
 	grpcPortStr := viper.GetString("GRPC_PORT")
 	if grpcPortStr == "" {
 		panic("GRPC_PORT environment variable is required")
@@ -97,6 +218,137 @@ func MustLoad() *Config {
 	}
 
 	cfg.GRPC.Timeout = grpcTimeout
+	cfg.GRPC.HealthCheckInterval = durationOrDefault("GRPC_HEALTH_CHECK_INTERVAL", 10*time.Second)
+	cfg.GRPC.DrainDelay = durationOrDefault("GRPC_DRAIN_DELAY", 5*time.Second)
+
+	cfg.HTTP.Port = intOrDefault("HTTP_PORT", 8080)
+
+	cfg.Signing.Alg = viper.GetString("SIGNING_ALG")
+	if cfg.Signing.Alg == "" {
+		cfg.Signing.Alg = "HS256"
+	}
+	cfg.Signing.KeyFile = viper.GetString("SIGNING_KEY_FILE")
+	cfg.Signing.RotationInterval = durationOrDefault("SIGNING_KEY_ROTATION_INTERVAL", 30*24*time.Hour)
+	cfg.Signing.PublishDelay = durationOrDefault("SIGNING_KEY_PUBLISH_DELAY", 24*time.Hour)
+	cfg.Signing.CheckInterval = durationOrDefault("SIGNING_KEY_CHECK_INTERVAL", time.Minute)
+	cfg.Signing.LegacyHS256Until = timeOrZero("SIGNING_LEGACY_HS256_UNTIL")
+
+	cfg.OIDC.Issuer = strings.TrimSuffix(viper.GetString("OIDC_ISSUER"), "/")
+	if cfg.OIDC.Issuer == "" {
+		cfg.OIDC.Issuer = fmt.Sprintf("http://localhost:%d", cfg.HTTP.Port)
+	}
+	cfg.OIDC.SessionTTL = durationOrDefault("OIDC_SESSION_TTL", 24*time.Hour)
+
+	cfg.TOTPIssuer = viper.GetString("TOTP_ISSUER")
+	if cfg.TOTPIssuer == "" {
+		cfg.TOTPIssuer = "Elif SSO"
+	}
+
+	cfg.WebAuthn.RPID = viper.GetString("WEBAUTHN_RP_ID")
+	if cfg.WebAuthn.RPID == "" {
+		cfg.WebAuthn.RPID = "localhost"
+	}
+	cfg.WebAuthn.RPName = viper.GetString("WEBAUTHN_RP_NAME")
+	if cfg.WebAuthn.RPName == "" {
+		cfg.WebAuthn.RPName = cfg.TOTPIssuer
+	}
+	cfg.WebAuthn.Origins = strings.Split(viper.GetString("WEBAUTHN_RP_ORIGINS"), ",")
+	if viper.GetString("WEBAUTHN_RP_ORIGINS") == "" {
+		cfg.WebAuthn.Origins = []string{cfg.OIDC.Issuer}
+	}
+
+	cfg.Mail.Driver = viper.GetString("MAIL_DRIVER")
+	if cfg.Mail.Driver == "" {
+		cfg.Mail.Driver = "file"
+	}
+	cfg.Mail.From = viper.GetString("MAIL_FROM")
+	if cfg.Mail.From == "" {
+		cfg.Mail.From = "noreply@localhost"
+	}
+	cfg.Mail.Dir = viper.GetString("MAIL_DIR")
+	if cfg.Mail.Dir == "" {
+		cfg.Mail.Dir = "./mail"
+	}
+	cfg.Mail.SMTP.Host = viper.GetString("SMTP_HOST")
+	cfg.Mail.SMTP.Port = intOrDefault("SMTP_PORT", 587)
+	cfg.Mail.SMTP.Username = viper.GetString("SMTP_USERNAME")
+	cfg.Mail.SMTP.Password = viper.GetString("SMTP_PASSWORD")
+	cfg.Mail.Outbox.PollInterval = durationOrDefault("MAIL_OUTBOX_POLL_INTERVAL", 5*time.Second)
+	cfg.Mail.Outbox.MaxAttempts = intOrDefault("MAIL_OUTBOX_MAX_ATTEMPTS", 8)
+	cfg.Mail.Outbox.Backoff = durationOrDefault("MAIL_OUTBOX_BACKOFF", 30*time.Second)
+	cfg.Mail.Outbox.MaxBackoff = durationOrDefault("MAIL_OUTBOX_MAX_BACKOFF", time.Hour)
+	cfg.Mail.Outbox.Retention = durationOrDefault("MAIL_OUTBOX_RETENTION", 24*time.Hour)
+
+	cfg.AuthzNamespacesFile = viper.GetString("AUTHZ_NAMESPACES_FILE")
+
+	cfg.Tracing.Exporter = viper.GetString("TRACING_EXPORTER")
+	if cfg.Tracing.Exporter == "" {
+		cfg.Tracing.Exporter = "none"
+	}
+	cfg.Tracing.Endpoint = viper.GetString("TRACING_OTLP_ENDPOINT")
+	cfg.Tracing.SampleRatio = floatOrDefault("TRACING_SAMPLE_RATIO", 1)
+
+	cfg.RevokedTokensCleanupInterval = durationOrDefault("REVOKED_TOKENS_CLEANUP_INTERVAL", time.Hour)
 
 	return &cfg
 }
+
+// durationOrDefault parses an optional duration setting.
+func durationOrDefault(key string, def time.Duration) time.Duration {
+	str := viper.GetString(key)
+	if str == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse %s: %s", key, err))
+	}
+
+	return d
+}
+
+// timeOrZero parses an optional RFC 3339 timestamp setting.
+func timeOrZero(key string) time.Time {
+	str := viper.GetString(key)
+	if str == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse %s: %s", key, err))
+	}
+
+	return t
+}
+
+// intOrDefault parses an optional integer setting.
+func intOrDefault(key string, def int) int {
+	str := viper.GetString(key)
+	if str == "" {
+		return def
+	}
+
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse %s: %s", key, err))
+	}
+
+	return i
+}
+
+// floatOrDefault parses an optional number setting.
+func floatOrDefault(key string, def float64) float64 {
+	str := viper.GetString(key)
+	if str == "" {
+		return def
+	}
+
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse %s: %s", key, err))
+	}
+
+	return f
+}
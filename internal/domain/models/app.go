@@ -1,7 +1,28 @@
 package models
 
+import "time"
+
+// OAuth 2.0 grant types apps may be allowed to use.
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
 type App struct {
-	ID     int64
-	Name   string
-	Secret string
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	// Secret is the plain secret, kept only as the signing key of apps when
+	// tokens are signed with HS256, empty otherwise.
+	Secret          string `db:"secret"`
+	SecretHash      string `db:"secret_hash"`
+	GrantTypes      string `db:"grant_types"`       // space separated
+	TokenTTLSeconds int64  `db:"token_ttl_seconds"` // overrides the access token TTL, 0 keeps the default
+	LogoURI         string `db:"logo_uri"`
+	// Public apps, e.g. SPAs and mobile apps, can not keep a secret. They have
+	// none and authenticate only with PKCE.
+	Public       bool      `db:"public"`
+	CreatedAt    time.Time `db:"created_at"`
+	RedirectURIs []string  `db:"-"`
+	Scopes       []string  `db:"-"` // scopes of the client credentials grant
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AuthorizationCode is an OAuth 2.0 authorization code bound to a PKCE challenge.
+type AuthorizationCode struct {
+	ID                  int64      `db:"id"`
+	CodeHash            string     `db:"code_hash"`
+	AppID               int64      `db:"app_id"`
+	UserID              int64      `db:"user_id"`
+	RedirectURI         string     `db:"redirect_uri"`
+	Scope               string     `db:"scope"`
+	Nonce               string     `db:"nonce"`
+	CodeChallenge       string     `db:"code_challenge"`
+	CodeChallengeMethod string     `db:"code_challenge_method"`
+	AuthTime            time.Time  `db:"auth_time"`
+	AuthMethods         string     `db:"auth_methods"` // space separated amr values
+	ExpiresAt           time.Time  `db:"expires_at"`
+	UsedAt              *time.Time `db:"used_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Organization is a tenant, a group of users sharing resources.
+type Organization struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name"`
+	Slug      string    `db:"slug"` // unique, URL friendly name
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Roles of organization members.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// OrgMember is a membership of a user in an organization.
+type OrgMember struct {
+	OrgID     int64     `db:"org_id"`
+	OrgName   string    `db:"org_name"`
+	UserID    int64     `db:"user_id"`
+	Email     string    `db:"email"`
+	Role      string    `db:"role"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// OrgInvitation invites the owner of an email to an organization. Only the
+// hash of its token is stored.
+type OrgInvitation struct {
+	ID         int64      `db:"id"`
+	OrgID      int64      `db:"org_id"`
+	Email      string     `db:"email"`
+	Role       string     `db:"role"`
+	TokenHash  string     `db:"token_hash"`
+	InvitedBy  *int64     `db:"invited_by"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Principal is the caller of a request, authenticated by its access token.
+type Principal struct {
+	UserID      int64 // 0 for client tokens, which have no user
+	AppID       int64
+	OrgID       int64 // 0 if the token is not issued for an organization
+	Roles       []string
+	Permissions []string
+	Scopes      []string
+	AuthMethods []string
+	IsAdmin     bool
+}
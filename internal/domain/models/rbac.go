@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Role is a named set of permissions in an app, assigned to users.
+type Role struct {
+	ID          int64     `db:"id"`
+	AppID       int64     `db:"app_id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	Permissions []string  `db:"-"` // names of the permissions granted by the role
+}
+
+// Permission is an action in an app, e.g. orders:read. Permissions ending
+// with * grant all permissions with the same prefix.
+type Permission struct {
+	ID          int64     `db:"id"`
+	AppID       int64     `db:"app_id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+}
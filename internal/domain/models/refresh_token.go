@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RefreshToken is a persisted, single-use refresh token.
+//
+// Tokens issued from the same login share a FamilyID, so presenting an
+// already rotated token revokes the whole chain.
+type RefreshToken struct {
+	ID        int64  `db:"id"`
+	UserID    int64  `db:"user_id"`
+	AppID     int64  `db:"app_id"`
+	FamilyID  string `db:"family_id"`
+	TokenHash string `db:"token_hash"`
+	// AuthMethods are the space separated amr values of the login, kept for
+	// the access tokens issued on refresh.
+	AuthMethods string `db:"auth_methods"`
+	// OrgID is the organization the access tokens of the session are issued for.
+	OrgID     *int64     `db:"org_id"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RelationTuple states that the subject has the relation to the object,
+// e.g. document:readme#editor@group:eng#member. The subject is an object,
+// usually a user, or the userset of objects having SubjectRelation to it.
+type RelationTuple struct {
+	ID               int64     `db:"id"`
+	Namespace        string    `db:"namespace"`
+	ObjectID         string    `db:"object_id"`
+	Relation         string    `db:"relation"`
+	SubjectNamespace string    `db:"subject_namespace"`
+	SubjectID        string    `db:"subject_id"`
+	SubjectRelation  string    `db:"subject_relation"` // empty if the subject is an object
+	CreatedAt        time.Time `db:"created_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Signing key states.
+//
+// A pending key is already published, so resource servers can cache it
+// before it signs anything. The active key with no RotatedAt signs new
+// tokens; after rotation it stays active for verification until its tokens
+// expire and then it is retired.
+const (
+	SigningKeyPending = "pending"
+	SigningKeyActive  = "active"
+	SigningKeyRetired = "retired"
+)
+
+type SigningKey struct {
+	ID          int64      `db:"id"`
+	KID         string     `db:"kid"`
+	Alg         string     `db:"alg"`
+	PrivateKey  []byte     `db:"private_key"`
+	State       string     `db:"state"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ActivatedAt *time.Time `db:"activated_at"`
+	RotatedAt   *time.Time `db:"rotated_at"`
+	RetiredAt   *time.Time `db:"retired_at"`
+}
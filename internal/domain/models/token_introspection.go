@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TokenIntrospection is the state of a token as defined by RFC 7662.
+// Only Active is set for inactive tokens.
+type TokenIntrospection struct {
+	Active    bool
+	Subject   string
+	UserID    int64 // zero for tokens issued to the app itself
+	AppID     int64
+	Scope     string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TOTP is the authenticator app enrollment of a user. It takes effect
+// only once confirmed with a valid code.
+type TOTP struct {
+	UserID       int64      `db:"user_id"`
+	Secret       string     `db:"secret"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
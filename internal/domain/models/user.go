@@ -3,14 +3,28 @@ package models
 import "time"
 
 type User struct {
-	ID               int64     `db:"id"`
-	FirstName        string    `db:"first_name"`
-	LastName         string    `db:"last_name"`
-	PhoneNumber      string    `db:"phone_number"`
-	CreatedAt        time.Time `db:"created_at"`
-	UpdatedAt        time.Time `db:"updated_at"`
-	Email            string    `db:"email"`
-	PasswordHash     []byte    `db:"pass_hash"`
-	IsAdmin          bool      `db:"is_admin"`
-	IsEmailConfirmed bool      `db:"is_email_confirmed"`
+	ID               int64      `db:"id"`
+	FirstName        string     `db:"first_name"`
+	LastName         string     `db:"last_name"`
+	PhoneNumber      string     `db:"phone_number"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+	Email            string     `db:"email"`
+	PasswordHash     []byte     `db:"pass_hash"`
+	IsAdmin          bool       `db:"is_admin"`
+	IsEmailConfirmed bool       `db:"is_email_confirmed"`
+	Locale           string     `db:"locale"`      // language of emails, e.g. ru
+	DisabledAt       *time.Time `db:"disabled_at"` // disabled users can not log in
+}
+
+// UserFilter selects users listed by admins. Zero fields do not filter.
+type UserFilter struct {
+	EmailPrefix   string
+	Confirmed     *bool
+	Admin         *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// AfterID continues the listing after the user with the ID, users are ordered by ID.
+	AfterID int64
+	Limit   int
 }
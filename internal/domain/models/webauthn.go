@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a passkey registered by a user.
+type WebAuthnCredential struct {
+	ID           int64      `db:"id"`
+	UserID       int64      `db:"user_id"`
+	CredentialID []byte     `db:"credential_id"`
+	Name         string     `db:"name"`
+	Data         []byte     `db:"data"` // JSON encoded public key, flags and sign counter
+	CreatedAt    time.Time  `db:"created_at"`
+	LastUsedAt   *time.Time `db:"last_used_at"`
+}
+
+const (
+	WebAuthnRegistration = "registration"
+	WebAuthnLogin        = "login"
+)
+
+// WebAuthnSession is the server side state of a registration or login
+// ceremony, i.e. the challenge the authenticator must sign.
+type WebAuthnSession struct {
+	ID        string    `db:"id"`
+	UserID    *int64    `db:"user_id"` // nil for passkey login, the user is not known yet
+	AppID     int64     `db:"app_id"`
+	Kind      string    `db:"kind"`
+	Data      []byte    `db:"data"` // JSON encoded challenge and options
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
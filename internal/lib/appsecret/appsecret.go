@@ -0,0 +1,29 @@
+// Package appsecret generates app secrets and checks them against the
+// hashes they are stored as.
+package appsecret
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+)
+
+// Generate returns a new random secret. It is shown to the admin once,
+// only its hash is stored.
+func Generate() (string, error) {
+	return rnd.GenerateToken(32)
+}
+
+// Hash returns the form secrets are stored in. Secrets are random, so a
+// plain SHA-256 is enough, unlike passwords.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify reports whether secret matches hash in constant time.
+func Verify(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(secret)), []byte(hash)) == 1
+}
@@ -0,0 +1,151 @@
+// Package emails renders the emails of the service from templates.
+//
+// Every email has an HTML and a plain text variant per locale, found in
+// templates/<locale>/<name>.html and <name>.txt. The text template defines
+// the subject as well.
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Names of the emails.
+const (
+	VerifyEmail   = "verify_email"
+	ResetPassword = "reset_password"
+	NewDevice     = "new_device"
+	OrgInvitation = "org_invitation"
+)
+
+// DefaultLocale is used for users with an unknown locale.
+const DefaultLocale = "en"
+
+// Locales are the supported locales.
+var Locales = []string{"en", "ru", "ky"}
+
+// Names are the names of all emails.
+var Names = []string{VerifyEmail, ResetPassword, NewDevice, OrgInvitation}
+
+// CodeData is the data of emails with a confirmation code.
+type CodeData struct {
+	FirstName string
+	Code      string
+}
+
+// NewDeviceData is the data of the new device alert.
+type NewDeviceData struct {
+	FirstName string
+	Device    string
+	IP        string
+	Time      time.Time
+}
+
+// OrgInvitationData is the data of an invitation to an organization.
+type OrgInvitationData struct {
+	OrgName     string
+	InviterName string
+	Role        string
+	Token       string
+	ExpiresAt   time.Time
+}
+
+// Email is a rendered email.
+type Email struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+//go:embed templates
+var files embed.FS
+
+type localized struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var templates = mustParse()
+
+func mustParse() map[string]localized {
+	parsed := make(map[string]localized, len(Locales)*len(Names))
+
+	for _, locale := range Locales {
+		for _, name := range Names {
+			base := "templates/" + locale + "/" + name
+
+			html := htmltemplate.Must(htmltemplate.ParseFS(files, "templates/layout.html", base+".html"))
+			text := texttemplate.Must(texttemplate.ParseFS(files, base+".txt"))
+
+			parsed[key(locale, name)] = localized{html: html, text: text}
+		}
+	}
+
+	return parsed
+}
+
+func key(locale, name string) string {
+	return locale + "/" + name
+}
+
+// Locale returns the supported locale matching the given one, e.g. ru for ru-RU,
+// or DefaultLocale.
+func Locale(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	for _, l := range Locales {
+		if l == locale {
+			return l
+		}
+	}
+
+	return DefaultLocale
+}
+
+// Render renders the named email in the locale of the user.
+func Render(name, locale string, data any) (Email, error) {
+	const op = "emails.Render"
+
+	locale = Locale(locale)
+
+	t, ok := templates[key(locale, name)]
+	if !ok {
+		return Email{}, fmt.Errorf("%s: unknown email %q", op, name)
+	}
+
+	var subject, text, html bytes.Buffer
+
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Email{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := t.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return Email{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	view := struct {
+		Locale  string
+		Subject string
+		Data    any
+	}{
+		Locale:  locale,
+		Subject: subject.String(),
+		Data:    data,
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", view); err != nil {
+		return Email{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return Email{
+		Subject: subject.String(),
+		HTML:    html.String(),
+		Text:    strings.TrimLeft(text.String(), "\n"),
+	}, nil
+}
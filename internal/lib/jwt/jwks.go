@@ -0,0 +1,144 @@
+package jwtn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKS builds a key set from the public parts of the given keys.
+// Symmetric keys are skipped.
+func NewJWKS(keys []Key) (JWKS, error) {
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+
+	for _, k := range keys {
+		if k.IsSymmetric() {
+			continue
+		}
+
+		jwk, err := NewJWK(k)
+		if err != nil {
+			return JWKS{}, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, nil
+}
+
+// NewJWK returns the public part of k as JWK.
+func NewJWK(k Key) (JWK, error) {
+	jwk, err := publicJWK(k)
+	if err != nil {
+		return JWK{}, err
+	}
+
+	jwk.Kid = k.ID
+	jwk.Use = "sig"
+	jwk.Alg = k.Method.Alg()
+
+	return jwk, nil
+}
+
+// Thumbprint computes the RFC 7638 JWK thumbprint of the public key of k.
+func Thumbprint(k Key) (string, error) {
+	jwk, err := publicJWK(k)
+	if err != nil {
+		return "", err
+	}
+
+	// required members only, in lexicographic order
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func publicJWK(k Key) (JWK, error) {
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   encodeBigInt(pub.N, 0),
+			E:   encodeBigInt(big.NewInt(int64(pub.E)), 0),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Crv: pub.Curve.Params().Name,
+			X:   encodeBigInt(pub.X, size),
+			Y:   encodeBigInt(pub.Y, size),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	}
+
+	return JWK{}, fmt.Errorf("unsupported public key type %T", k.Public)
+}
+
+// encodeBigInt encodes n as unsigned big-endian base64url, left padded to size bytes.
+func encodeBigInt(n *big.Int, size int) string {
+	b := n.Bytes()
+	if len(b) < size {
+		b = n.FillBytes(make([]byte, size))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,117 @@
+package jwtn
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func decodeBase64URL(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+
+	return b
+}
+
+func TestThumbprintRFC7638(t *testing.T) {
+	// RFC 7638 section 3.1
+	const (
+		n = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+		e = "AQAB"
+	)
+
+	pub := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(decodeBase64URL(t, n)),
+		E: int(new(big.Int).SetBytes(decodeBase64URL(t, e)).Int64()),
+	}
+	key := Key{ID: "2011-04-29", Method: jwt.SigningMethodRS256, Public: pub}
+
+	jwk, err := NewJWK(key)
+	if err != nil {
+		t.Fatalf("NewJWK() error = %v", err)
+	}
+	if jwk.Kty != "RSA" || jwk.N != n || jwk.E != e || jwk.Kid != "2011-04-29" || jwk.Alg != "RS256" || jwk.Use != "sig" {
+		t.Fatalf("NewJWK() = %+v", jwk)
+	}
+
+	kid, err := Thumbprint(key)
+	if err != nil {
+		t.Fatalf("Thumbprint() error = %v", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; kid != want {
+		t.Fatalf("Thumbprint() = %s, want %s", kid, want)
+	}
+}
+
+func TestNewKeyEd25519RFC8037(t *testing.T) {
+	// RFC 8037 appendix A.1 and A.3
+	priv := ed25519.NewKeyFromSeed(decodeBase64URL(t, "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"))
+
+	key, err := NewKey(AlgEdDSA, priv)
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	if want := "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"; key.ID != want {
+		t.Fatalf("key ID = %s, want %s", key.ID, want)
+	}
+
+	jwk, err := NewJWK(key)
+	if err != nil {
+		t.Fatalf("NewJWK() error = %v", err)
+	}
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X != "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo" || jwk.Alg != "EdDSA" {
+		t.Fatalf("NewJWK() = %+v", jwk)
+	}
+}
+
+func TestNewJWKS(t *testing.T) {
+	var keys []Key
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		key, err := GenerateKey(alg)
+		if err != nil {
+			t.Fatalf("generate %s key: %v", alg, err)
+		}
+		keys = append(keys, key)
+	}
+	keys = append(keys, Key{Method: jwt.SigningMethodHS256, Private: []byte("secret")})
+
+	jwks, err := NewJWKS(keys)
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+
+	// the HS256 secret is not published
+	if len(jwks.Keys) != 3 {
+		t.Fatalf("NewJWKS() returned %d keys, want 3", len(jwks.Keys))
+	}
+
+	for i, jwk := range jwks.Keys {
+		if jwk.Kid != keys[i].ID || jwk.Alg != keys[i].Method.Alg() || jwk.Use != "sig" {
+			t.Fatalf("JWK %d = %+v, want kid %s and alg %s", i, jwk, keys[i].ID, keys[i].Method.Alg())
+		}
+	}
+
+	// coordinates are padded to the size of the curve (RFC 7518 section 6.2.1.2)
+	ec := jwks.Keys[1]
+	if ec.Kty != "EC" || ec.Crv != "P-256" || len(decodeBase64URL(t, ec.X)) != 32 || len(decodeBase64URL(t, ec.Y)) != 32 {
+		t.Fatalf("EC JWK = %+v", ec)
+	}
+
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	if strings.Contains(string(data), `"d"`) {
+		t.Fatalf("JWKS contains a private key: %s", data)
+	}
+}
@@ -2,25 +2,185 @@ package jwtn
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
 	"google.golang.org/grpc/metadata"
 )
 
-// NewToken creates new JWT token for given user and app.
-func NewToken(user models.User, app models.App, duration time.Duration) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+var ErrTokenRevoked = errors.New("token is revoked")
+
+// RevocationChecker reports whether a token with the given jti was revoked.
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// Option adds optional claims to a token created by NewToken.
+type Option func(claims jwt.MapClaims)
+
+// WithSessionID binds the token to a login session, i.e. a refresh token family,
+// so the session can be terminated as a whole.
+func WithSessionID(sid string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["sid"] = sid
+	}
+}
+
+// WithScope sets the space separated OAuth 2.0 scopes granted to the token.
+func WithScope(scope string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["scope"] = scope
+	}
+}
+
+// WithType marks tokens that are not access tokens, e.g. SSO sessions,
+// so they can not be used in place of one.
+func WithType(typ string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["typ"] = typ
+	}
+}
+
+// WithAuthMethods sets the amr claim (RFC 8176), i.e. how the user authenticated.
+func WithAuthMethods(amr []string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["amr"] = amr
+	}
+}
+
+// WithRoles sets the roles of the user in the app of the token.
+func WithRoles(roles []string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["roles"] = roles
+	}
+}
+
+// WithPermissions sets the permissions granted to the user in the app of the token.
+func WithPermissions(permissions []string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["permissions"] = permissions
+	}
+}
+
+// WithOrg sets the organization the token is issued for and the role of the user in it.
+func WithOrg(orgID int64, role string) Option {
+	return func(claims jwt.MapClaims) {
+		claims["org_id"] = orgID
+		claims["org_role"] = role
+	}
+}
+
+// AuthMethods returns the amr claim of a parsed token.
+func AuthMethods(claims jwt.MapClaims) []string {
+	return stringsClaim(claims, "amr")
+}
+
+// Roles returns the roles claim of a parsed token.
+func Roles(claims jwt.MapClaims) []string {
+	return stringsClaim(claims, "roles")
+}
+
+// Permissions returns the permissions claim of a parsed token.
+func Permissions(claims jwt.MapClaims) []string {
+	return stringsClaim(claims, "permissions")
+}
+
+// OrgID returns the org_id claim of a parsed token, false if the token is
+// not issued for an organization.
+func OrgID(claims jwt.MapClaims) (int64, bool) {
+	orgID, ok := claims["org_id"].(float64)
+	return int64(orgID), ok
+}
+
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	values, _ := claims[name].([]interface{})
+
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+
+	return strs
+}
+
+const jtiBytes = 16
+
+// NewToken creates new JWT token for given user and app signed with key.
+func NewToken(key Key, user models.User, app models.App, duration time.Duration, opts ...Option) (string, error) {
+	jti, err := rnd.GenerateToken(jtiBytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	token := jwt.New(key.Method)
 	claims := token.Claims.(jwt.MapClaims)
+	claims["jti"] = jti
 	claims["uid"] = user.ID
 	claims["email"] = user.Email
-	claims["exp"] = time.Now().Add(duration).Unix()
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(duration).Unix()
+	claims["app_id"] = app.ID
+
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	return sign(token, key)
+}
+
+// NewClientToken creates new JWT token for the app itself, without a user.
+// Its sub claim identifies the app as ClientSubject does.
+func NewClientToken(key Key, app models.App, duration time.Duration, opts ...Option) (string, error) {
+	jti, err := rnd.GenerateToken(jtiBytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	token := jwt.New(key.Method)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["jti"] = jti
+	claims["sub"] = ClientSubject(app.ID)
+	claims["client_id"] = strconv.FormatInt(app.ID, 10)
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(duration).Unix()
 	claims["app_id"] = app.ID
 
-	tokenString, err := token.SignedString([]byte(app.Secret))
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	return sign(token, key)
+}
+
+// ClientSubject returns the sub claim of tokens issued to the app itself.
+// It is prefixed, so it never collides with user subjects.
+func ClientSubject(appID int64) string {
+	return "app:" + strconv.FormatInt(appID, 10)
+}
+
+// Sign signs arbitrary claims with key, e.g. for OpenID Connect ID tokens.
+func Sign(key Key, claims jwt.MapClaims) (string, error) {
+	return sign(jwt.NewWithClaims(key.Method, claims), key)
+}
+
+func sign(token *jwt.Token, key Key) (string, error) {
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+
+	tokenString, err := token.SignedString(key.Private)
 	if err != nil {
 		return "", err
 	}
@@ -28,23 +188,73 @@ func NewToken(user models.User, app models.App, duration time.Duration) (string,
 	return tokenString, nil
 }
 
-func ValidateToken(ctx context.Context, app models.App) (claims jwt.MapClaims, err error) {
+// UnverifiedAppID returns the app_id claim of a token without verifying it,
+// so the key of the app can be looked up before verification.
+func UnverifiedAppID(tokenString string) (int64, error) {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return 0, err
+	}
+
+	appID, ok := token.Claims.(jwt.MapClaims)["app_id"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("missing app_id claim")
+	}
+
+	return int64(appID), nil
+}
+
+// ValidateToken validates the bearer token from the authorization header of the incoming request.
+func ValidateToken(ctx context.Context, app models.App, keys KeyProvider, revoked RevocationChecker) (claims jwt.MapClaims, err error) {
+	tokenString, err := BearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseToken(ctx, tokenString, app, keys, revoked)
+}
+
+// BearerToken returns the token from the authorization header of the incoming request.
+func BearerToken(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, fmt.Errorf("missing context metadata")
+		return "", fmt.Errorf("missing context metadata")
 	}
 
 	authHeaders := md.Get("authorization")
 	if len(authHeaders) == 0 {
-		return nil, fmt.Errorf("missing authorization header")
+		return "", fmt.Errorf("missing authorization header")
 	}
 
-	tokenString := strings.TrimSpace(strings.TrimPrefix(authHeaders[0], "Bearer "))
+	return strings.TrimSpace(strings.TrimPrefix(authHeaders[0], "Bearer ")), nil
+}
 
+// ParseToken validates the given access token and returns its claims.
+//
+// The verification key is selected by the kid header, tokens without one are
+// verified with the app secret. Tokens issued for another app or revoked
+// before their expiration are rejected, the latter with ErrTokenRevoked.
+func ParseToken(ctx context.Context, tokenString string, app models.App, keys KeyProvider, revoked RevocationChecker) (claims jwt.MapClaims, err error) {
+	return ParseTypedToken(ctx, tokenString, "", app, keys, revoked)
+}
+
+// ParseTypedToken is like ParseToken, but accepts only tokens created WithType(typ).
+func ParseTypedToken(ctx context.Context, tokenString, typ string, app models.App, keys KeyProvider, revoked RevocationChecker) (claims jwt.MapClaims, err error) {
 	// Parse and validate JWT token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte([]byte(app.Secret)), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+
+		key, err := keys.VerificationKey(ctx, app, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+
+		return key.Public, nil
+	}, jwt.WithValidMethods(validMethods))
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %v", err)
 	}
@@ -55,9 +265,29 @@ func ValidateToken(ctx context.Context, app models.App) (claims jwt.MapClaims, e
 	}
 
 	// Extract username from token
-	claims, ok = token.Claims.(jwt.MapClaims)
+	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		return nil, fmt.Errorf("invalid token claims")
 	}
+
+	if appID, _ := claims["app_id"].(float64); int64(appID) != app.ID {
+		return nil, fmt.Errorf("token was issued for another app")
+	}
+
+	if tokenTyp, _ := claims["typ"].(string); tokenTyp != typ {
+		return nil, fmt.Errorf("unexpected token type %q", tokenTyp)
+	}
+
+	// tokens issued before revocation support carry no jti and can not be revoked
+	if jti, ok := claims["jti"].(string); ok && revoked != nil {
+		isRevoked, err := revoked.IsTokenRevoked(ctx, jti)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if isRevoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,226 @@
+package jwtn
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+var validMethods = []string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA}
+
+// Key is a token signing key.
+//
+// Asymmetric keys carry an ID that is put into the kid header and published
+// through JWKS. HMAC keys are the legacy per-app secrets, they have no ID
+// and are never published.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.PrivateKey
+	Public  crypto.PublicKey
+}
+
+// IsSymmetric reports whether the key is a shared HMAC secret.
+func (k Key) IsSymmetric() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// KeyProvider selects keys for signing and verifying tokens of an app.
+type KeyProvider interface {
+	// SigningKey returns the key new tokens of the app are signed with.
+	SigningKey(ctx context.Context, app models.App) (Key, error)
+	// VerificationKey returns the key with the given kid. An empty kid
+	// selects the legacy HMAC secret of the app, if HS256 tokens are
+	// still accepted.
+	VerificationKey(ctx context.Context, app models.App, kid string) (Key, error)
+	// PublicKeys returns the asymmetric keys to publish through JWKS.
+	PublicKeys(ctx context.Context) ([]Key, error)
+}
+
+// AppSecretKey returns the legacy HS256 key derived from the app secret.
+func AppSecretKey(app models.App) Key {
+	return Key{
+		Method:  jwt.SigningMethodHS256,
+		Private: []byte(app.Secret),
+		Public:  []byte(app.Secret),
+	}
+}
+
+// StaticKeys is a KeyProvider with a single global signing key. Without
+// a global key every app signs its tokens with its own secret using HS256.
+type StaticKeys struct {
+	global *Key
+	// legacyUntil is when tokens signed with app secrets before the global
+	// key was configured stop being accepted.
+	legacyUntil time.Time
+}
+
+// NewStaticKeys returns a StaticKeys signing with global, or with the app
+// secrets if global is nil. With a global key, tokens without a kid are
+// verified with the app secret only before legacyUntil.
+func NewStaticKeys(global *Key, legacyUntil time.Time) *StaticKeys {
+	return &StaticKeys{global: global, legacyUntil: legacyUntil}
+}
+
+func (s *StaticKeys) SigningKey(_ context.Context, app models.App) (Key, error) {
+	if s.global == nil {
+		if app.Secret == "" {
+			return Key{}, ErrUnknownKey
+		}
+		return AppSecretKey(app), nil
+	}
+
+	return *s.global, nil
+}
+
+func (s *StaticKeys) VerificationKey(_ context.Context, app models.App, kid string) (Key, error) {
+	switch {
+	// apps created without HS256 signing have no plain secret, an empty
+	// key would accept tokens signed by anyone
+	case kid == "" && app.Secret != "" && (s.global == nil || LegacyAccepted(s.legacyUntil)):
+		return AppSecretKey(app), nil
+	case s.global != nil && s.global.ID == kid:
+		return *s.global, nil
+	}
+
+	return Key{}, ErrUnknownKey
+}
+
+func (s *StaticKeys) PublicKeys(_ context.Context) ([]Key, error) {
+	if s.global == nil {
+		return nil, nil
+	}
+
+	return []Key{*s.global}, nil
+}
+
+// LegacyAccepted reports whether tokens signed with app secrets are still
+// accepted after switching from HS256 to the algorithm of a global key.
+func LegacyAccepted(until time.Time) bool {
+	return time.Now().Before(until)
+}
+
+// GenerateKey generates a new asymmetric key for the given algorithm.
+func GenerateKey(alg string) (Key, error) {
+	var priv crypto.PrivateKey
+	var err error
+
+	switch alg {
+	case AlgRS256:
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return Key{}, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	return NewKey(alg, priv)
+}
+
+// LoadKey reads a PEM encoded private key (PKCS#8, PKCS#1 or SEC 1) from path.
+func LoadKey(alg, path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return ParseKey(alg, data)
+}
+
+// ParseKey parses a PEM encoded private key (PKCS#8, PKCS#1 or SEC 1).
+func ParseKey(alg string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found")
+	}
+
+	var priv crypto.PrivateKey
+	var err error
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	return NewKey(alg, priv)
+}
+
+// MarshalKey encodes the private key of k as PKCS#8 PEM.
+func MarshalKey(k Key) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// NewKey wraps an asymmetric private key, checking that it matches alg.
+// The key ID is the RFC 7638 thumbprint of the public key.
+func NewKey(alg string, priv crypto.PrivateKey) (Key, error) {
+	var key Key
+
+	switch p := priv.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return Key{}, fmt.Errorf("RSA key can not be used with %s", alg)
+		}
+		key = Key{Method: jwt.SigningMethodRS256, Private: p, Public: &p.PublicKey}
+	case *ecdsa.PrivateKey:
+		if alg != AlgES256 || p.Curve != elliptic.P256() {
+			return Key{}, fmt.Errorf("ECDSA key can only be a P-256 key used with %s", AlgES256)
+		}
+		key = Key{Method: jwt.SigningMethodES256, Private: p, Public: &p.PublicKey}
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			return Key{}, fmt.Errorf("Ed25519 key can not be used with %s", alg)
+		}
+		key = Key{Method: jwt.SigningMethodEdDSA, Private: p, Public: p.Public()}
+	default:
+		return Key{}, fmt.Errorf("unsupported private key type %T", priv)
+	}
+
+	kid, err := Thumbprint(key)
+	if err != nil {
+		return Key{}, err
+	}
+	key.ID = kid
+
+	return key, nil
+}
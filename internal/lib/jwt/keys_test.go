@@ -0,0 +1,44 @@
+package jwtn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+func TestStaticKeysLegacyHS256(t *testing.T) {
+	global, err := GenerateKey(AlgES256)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	app := models.App{ID: 1, Secret: "test-secret"}
+
+	tests := []struct {
+		name    string
+		keys    *StaticKeys
+		app     models.App
+		wantErr error
+	}{
+		{"HS256 signing", NewStaticKeys(nil, time.Time{}), app, nil},
+		{"HS256 signing, no plain secret", NewStaticKeys(nil, time.Time{}), models.App{ID: 1}, ErrUnknownKey},
+		{"global key", NewStaticKeys(&global, time.Time{}), app, ErrUnknownKey},
+		{"global key, legacy window open", NewStaticKeys(&global, time.Now().Add(time.Hour)), app, nil},
+		{"global key, legacy window closed", NewStaticKeys(&global, time.Now().Add(-time.Hour)), app, ErrUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.keys.VerificationKey(context.Background(), tt.app, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerificationKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !key.IsSymmetric() {
+				t.Fatalf("VerificationKey() = %s key, want HS256", key.Method.Alg())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File writes messages to a maildir instead of sending them, so emails
+// can be read with a mail client during local development.
+type File struct {
+	dir  string
+	from string
+}
+
+// NewFile creates the maildir at dir if needed.
+func NewFile(dir, from string) (*File, error) {
+	const op = "mailer.NewFile"
+
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return &File{dir: dir, from: from}, nil
+}
+
+// Send delivers the message to the new subdirectory. It is written
+// to tmp first, so readers never see partial messages.
+func (f *File) Send(_ context.Context, msg Message) error {
+	const op = "mailer.File.Send"
+
+	body, err := msg.format(f.from)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	name, err := uniqueName()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tmp := filepath.Join(f.dir, "tmp", name)
+	if err = os.WriteFile(tmp, body, 0o600); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = os.Rename(tmp, filepath.Join(f.dir, "new", name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// uniqueName returns a maildir file name.
+func uniqueName() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("%d.%s.%s.eml", time.Now().UnixNano(), hex.EncodeToString(b), host), nil
+}
+
+// Ping checks that the maildir still exists.
+func (f *File) Ping(_ context.Context) error {
+	const op = "mailer.File.Ping"
+
+	if _, err := os.Stat(filepath.Join(f.dir, "new")); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+// implicitTLSPort is the submission port speaking TLS from the start (RFC 8314),
+// other ports are upgraded with STARTTLS.
+const implicitTLSPort = 465
+
+// SMTP sends messages through an SMTP server.
+type SMTP struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// NewSMTP returns a mailer sending messages from the given address.
+// Authentication is skipped if username is empty.
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	return &SMTP{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	const op = "mailer.SMTP.Send"
+
+	body, err := msg.format(s.from)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("%s: invalid sender: %w", op, err)
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer c.Close()
+
+	if err = c.Mail(from.Address); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = c.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = w.Write(body); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = c.Quit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// dial connects and authenticates to the server. The connection is bound
+// to the deadline of ctx, as net/smtp does not support contexts.
+func (s *SMTP) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	tlsConfig := &tls.Config{ServerName: s.host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.port == implicitTLSPort {
+		d := tls.Dialer{Config: tlsConfig}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if s.port != implicitTLSPort {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err = c.StartTLS(tlsConfig); err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if s.username != "" {
+		// PlainAuth refuses to send the password over unencrypted connections
+		// to anything but localhost
+		if err = c.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Ping checks that the server accepts connections and the credentials.
+func (s *SMTP) Ping(ctx context.Context) error {
+	const op = "mailer.SMTP.Ping"
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = c.Quit(); err != nil {
+		c.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
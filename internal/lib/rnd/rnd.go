@@ -1,6 +1,8 @@
 package rnd
 
 import (
+	crand "crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"math/rand"
 )
@@ -8,3 +10,14 @@ import (
 func GenerateRandomNumber() string {
 	return fmt.Sprintf("%d", rand.Intn(900000)+100000) // Generates a random number between 0 and 100,000 (inclusive)
 }
+
+// GenerateToken returns n cryptographically secure random bytes encoded as
+// unpadded base64url, suitable for opaque tokens and identifiers.
+func GenerateToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogHandler(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	var buf bytes.Buffer
+	// loggers derived with With keep the trace IDs, like the ones of the services
+	log := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil))).With(slog.String("op", "test"))
+
+	log.InfoContext(ctx, "with span")
+	if out := buf.String(); !strings.Contains(out, "trace_id="+sc.TraceID().String()) || !strings.Contains(out, "span_id="+sc.SpanID().String()) {
+		t.Fatalf("record %q has no trace IDs", out)
+	}
+
+	buf.Reset()
+	log.InfoContext(context.Background(), "without span")
+	if out := buf.String(); strings.Contains(out, "trace_id") {
+		t.Fatalf("record %q has trace IDs without a span", out)
+	}
+}
@@ -0,0 +1,156 @@
+// Package admin implements user and app management by admins.
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+type UserStore interface {
+	Users(ctx context.Context, filter models.UserFilter) ([]models.User, error)
+	UserAllData(ctx context.Context, id int64) (models.User, error)
+	DisableUser(ctx context.Context, userID int64) error
+	EnableUser(ctx context.Context, userID int64) error
+	DeleteUser(ctx context.Context, userID int64) error
+	SetAdmin(ctx context.Context, userID int64, isAdmin bool) error
+}
+
+// ErrSelfModification is returned when admins disable, delete or demote
+// themselves, so the last admin can not lock everyone out.
+var ErrSelfModification = errors.New("admins can not disable, delete or demote themselves")
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+type Service struct {
+	log   *slog.Logger
+	users UserStore
+	apps  AppStore
+	// keepAppSecrets stores plain app secrets, which are the signing keys
+	// of apps when tokens are signed with HS256
+	keepAppSecrets bool
+}
+
+func New(log *slog.Logger, users UserStore, apps AppStore, keepAppSecrets bool) *Service {
+	return &Service{
+		log:            log,
+		users:          users,
+		apps:           apps,
+		keepAppSecrets: keepAppSecrets,
+	}
+}
+
+// ListUsers returns a page of users matching the filter ordered by ID, and
+// the ID to continue after, 0 on the last page.
+func (s *Service) ListUsers(ctx context.Context, filter models.UserFilter) (users []models.User, nextAfterID int64, err error) {
+	const op = "admin.ListUsers"
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = DefaultPageSize
+	case filter.Limit > MaxPageSize:
+		filter.Limit = MaxPageSize
+	}
+
+	// one more than the page tells whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+
+	users, err = s.users.Users(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		nextAfterID = users[pageSize-1].ID
+	}
+
+	return users, nextAfterID, nil
+}
+
+func (s *Service) User(ctx context.Context, userID int64) (models.User, error) {
+	const op = "admin.User"
+
+	user, err := s.users.UserAllData(ctx, userID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// DisableUser prevents the user from logging in and ends all sessions.
+// Access tokens already issued are reported inactive by introspection.
+func (s *Service) DisableUser(ctx context.Context, adminID, userID int64) error {
+	const op = "admin.DisableUser"
+
+	if adminID == userID {
+		return fmt.Errorf("%s: %w", op, ErrSelfModification)
+	}
+
+	if err := s.users.DisableUser(ctx, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "user disabled", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("user_id", userID))
+
+	return nil
+}
+
+func (s *Service) EnableUser(ctx context.Context, adminID, userID int64) error {
+	const op = "admin.EnableUser"
+
+	if err := s.users.EnableUser(ctx, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "user enabled", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("user_id", userID))
+
+	return nil
+}
+
+// DeleteUser deletes the user with all their data.
+func (s *Service) DeleteUser(ctx context.Context, adminID, userID int64) error {
+	const op = "admin.DeleteUser"
+
+	if adminID == userID {
+		return fmt.Errorf("%s: %w", op, ErrSelfModification)
+	}
+
+	if err := s.users.DeleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "user deleted", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("user_id", userID))
+
+	return nil
+}
+
+// SetAdmin grants or revokes the global admin flag.
+func (s *Service) SetAdmin(ctx context.Context, adminID, userID int64, isAdmin bool) error {
+	const op = "admin.SetAdmin"
+
+	if adminID == userID && !isAdmin {
+		return fmt.Errorf("%s: %w", op, ErrSelfModification)
+	}
+
+	if err := s.users.SetAdmin(ctx, userID, isAdmin); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "admin flag changed",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.Int64("user_id", userID),
+		slog.Bool("is_admin", isAdmin),
+	)
+
+	return nil
+}
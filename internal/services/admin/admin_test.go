@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+func newTestService(s *memory.Storage, keepAppSecrets bool) *Service {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), s, s, keepAppSecrets)
+}
+
+func saveTestUser(t *testing.T, s *memory.Storage, email string) int64 {
+	t.Helper()
+
+	id, err := s.SaveUser(context.Background(), models.User{Email: email, PasswordHash: []byte("hash")}, "123456", models.OutboxEmail{Recipient: email})
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	return id
+}
+
+func userIDs(users []models.User) []int64 {
+	ids := make([]int64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	return ids
+}
+
+func TestListUsersPagination(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, false)
+
+	var ids []int64
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		ids = append(ids, saveTestUser(t, s, email))
+	}
+
+	var pages [][]int64
+	filter := models.UserFilter{Limit: 2}
+	for {
+		users, next, err := svc.ListUsers(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListUsers() error = %v", err)
+		}
+		pages = append(pages, userIDs(users))
+
+		if next == 0 {
+			break
+		}
+		if next != users[len(users)-1].ID {
+			t.Fatalf("ListUsers() next = %d, want the last ID of the page %d", next, users[len(users)-1].ID)
+		}
+		filter.AfterID = next
+	}
+
+	want := [][]int64{ids[0:2], ids[2:4], ids[4:5]}
+	if !slices.EqualFunc(pages, want, slices.Equal[[]int64]) {
+		t.Fatalf("pages = %v, want %v", pages, want)
+	}
+
+	// a full last page has no next page
+	users, next, err := svc.ListUsers(ctx, models.UserFilter{Limit: len(ids)})
+	if err != nil || len(users) != len(ids) || next != 0 {
+		t.Fatalf("ListUsers() = %v, %d, %v, want all users on one page", userIDs(users), next, err)
+	}
+}
+
+// limitStore records the limit the storage is asked for.
+type limitStore struct {
+	*memory.Storage
+	limit int
+}
+
+func (s *limitStore) Users(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	s.limit = filter.Limit
+	return s.Storage.Users(ctx, filter)
+}
+
+func TestListUsersPageSize(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{0, DefaultPageSize},
+		{-1, DefaultPageSize},
+		{10, 10},
+		{MaxPageSize + 1, MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		store := &limitStore{Storage: memory.New()}
+		svc := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, false)
+
+		if _, _, err := svc.ListUsers(context.Background(), models.UserFilter{Limit: tt.limit}); err != nil {
+			t.Fatalf("ListUsers() error = %v", err)
+		}
+		// one more user tells whether there is a next page
+		if store.limit != tt.want+1 {
+			t.Errorf("ListUsers(limit %d) asked for %d users, want %d", tt.limit, store.limit, tt.want+1)
+		}
+	}
+}
+
+func TestListUsersFilters(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, false)
+
+	alice := saveTestUser(t, s, "alice@example.com")
+	alex := saveTestUser(t, s, "Alex@example.com")
+	bob := saveTestUser(t, s, "bob@example.com")
+
+	if err := s.UserEmailConfirm(ctx, alice); err != nil {
+		t.Fatalf("confirm email: %v", err)
+	}
+	if err := s.SetAdmin(ctx, bob, true); err != nil {
+		t.Fatalf("set admin: %v", err)
+	}
+
+	yes, no := true, false
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		filter models.UserFilter
+		want   []int64
+	}{
+		{"no filter", models.UserFilter{}, []int64{alice, alex, bob}},
+		{"email prefix, any case", models.UserFilter{EmailPrefix: "AL"}, []int64{alice, alex}},
+		{"confirmed", models.UserFilter{Confirmed: &yes}, []int64{alice}},
+		{"not confirmed", models.UserFilter{Confirmed: &no}, []int64{alex, bob}},
+		{"admins", models.UserFilter{Admin: &yes}, []int64{bob}},
+		{"created after", models.UserFilter{CreatedAfter: &future}, []int64{}},
+		{"created before", models.UserFilter{CreatedBefore: &future, CreatedAfter: &past}, []int64{alice, alex, bob}},
+		{"combined", models.UserFilter{EmailPrefix: "al", Confirmed: &no}, []int64{alex}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, _, err := svc.ListUsers(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if got := userIDs(users); !slices.Equal(got, tt.want) {
+				t.Fatalf("ListUsers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisableUser(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, false)
+
+	adminID := saveTestUser(t, s, "admin@example.com")
+	userID := saveTestUser(t, s, "user@example.com")
+
+	session := models.RefreshToken{UserID: userID, AppID: 1, FamilyID: "session", TokenHash: "session", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := s.SaveRefreshToken(ctx, session); err != nil {
+		t.Fatalf("save refresh token: %v", err)
+	}
+
+	if err := svc.DisableUser(ctx, adminID, adminID); !errors.Is(err, ErrSelfModification) {
+		t.Fatalf("DisableUser() self error = %v, want %v", err, ErrSelfModification)
+	}
+	if err := svc.DisableUser(ctx, adminID, userID+100); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("DisableUser() unknown user error = %v, want %v", err, storage.ErrUserNotFound)
+	}
+
+	if err := svc.DisableUser(ctx, adminID, userID); err != nil {
+		t.Fatalf("DisableUser() error = %v", err)
+	}
+	if user, err := svc.User(ctx, userID); err != nil || user.DisabledAt == nil {
+		t.Fatalf("User() after disabling = %+v, %v, want disabled", user, err)
+	}
+	if token, err := s.RefreshToken(ctx, "session"); err != nil || token.RevokedAt == nil {
+		t.Fatalf("refresh token after disabling = %+v, %v, want revoked", token, err)
+	}
+
+	if err := svc.EnableUser(ctx, adminID, userID); err != nil {
+		t.Fatalf("EnableUser() error = %v", err)
+	}
+	if user, err := svc.User(ctx, userID); err != nil || user.DisabledAt != nil {
+		t.Fatalf("User() after enabling = %+v, %v, want enabled", user, err)
+	}
+	if err := svc.EnableUser(ctx, adminID, userID+100); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("EnableUser() unknown user error = %v, want %v", err, storage.ErrUserNotFound)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, false)
+
+	adminID := saveTestUser(t, s, "admin@example.com")
+	userID := saveTestUser(t, s, "user@example.com")
+
+	if err := svc.DeleteUser(ctx, adminID, adminID); !errors.Is(err, ErrSelfModification) {
+		t.Fatalf("DeleteUser() self error = %v, want %v", err, ErrSelfModification)
+	}
+	if _, err := svc.User(ctx, adminID); err != nil {
+		t.Fatalf("User() of the admin error = %v", err)
+	}
+
+	if err := svc.DeleteUser(ctx, adminID, userID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err := svc.User(ctx, userID); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("User() after deleting error = %v, want %v", err, storage.ErrUserNotFound)
+	}
+	if err := svc.DeleteUser(ctx, adminID, userID); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("DeleteUser() again error = %v, want %v", err, storage.ErrUserNotFound)
+	}
+}
+
+func TestSetAdmin(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, false)
+
+	adminID := saveTestUser(t, s, "admin@example.com")
+	userID := saveTestUser(t, s, "user@example.com")
+
+	isAdmin := func(userID int64) bool {
+		t.Helper()
+
+		user, err := svc.User(ctx, userID)
+		if err != nil {
+			t.Fatalf("User() error = %v", err)
+		}
+
+		return user.IsAdmin
+	}
+
+	if err := svc.SetAdmin(ctx, adminID, adminID, true); err != nil {
+		t.Fatalf("SetAdmin() self error = %v", err)
+	}
+	if err := svc.SetAdmin(ctx, adminID, adminID, false); !errors.Is(err, ErrSelfModification) {
+		t.Fatalf("SetAdmin() demoting self error = %v, want %v", err, ErrSelfModification)
+	}
+	if !isAdmin(adminID) {
+		t.Fatalf("admin demoted themselves")
+	}
+
+	if err := svc.SetAdmin(ctx, adminID, userID, true); err != nil || !isAdmin(userID) {
+		t.Fatalf("SetAdmin(true) error = %v, admin = %v", err, isAdmin(userID))
+	}
+	if err := svc.SetAdmin(ctx, adminID, userID, false); err != nil || isAdmin(userID) {
+		t.Fatalf("SetAdmin(false) error = %v, admin = %v", err, isAdmin(userID))
+	}
+	if err := svc.SetAdmin(ctx, adminID, userID+100, true); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("SetAdmin() unknown user error = %v, want %v", err, storage.ErrUserNotFound)
+	}
+}
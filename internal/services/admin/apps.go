@@ -0,0 +1,260 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+)
+
+type AppStore interface {
+	SaveApp(ctx context.Context, app models.App) (models.App, error)
+	App(ctx context.Context, id int64) (models.App, error)
+	AppRedirectURIs(ctx context.Context, appID int64) ([]string, error)
+	AppScopes(ctx context.Context, appID int64) ([]string, error)
+	Apps(ctx context.Context) ([]models.App, error)
+	UpdateApp(ctx context.Context, app models.App) error
+	SetAppSecret(ctx context.Context, appID int64, secret, secretHash string) error
+	ClearAppSecrets(ctx context.Context) (int64, error)
+	DeleteApp(ctx context.Context, appID int64) error
+}
+
+var ErrInvalidApp = errors.New("invalid app")
+
+// ErrAppSecretsInUse is returned when dropping the plain app secrets while
+// they sign tokens with HS256.
+var ErrAppSecretsInUse = errors.New("plain app secrets sign the tokens of apps with HS256")
+
+// GrantTypes are the grant types apps may be allowed to use.
+var GrantTypes = []string{models.GrantAuthorizationCode, models.GrantRefreshToken, models.GrantClientCredentials}
+
+// DefaultGrantTypes are allowed to apps created without grant types.
+var DefaultGrantTypes = []string{models.GrantAuthorizationCode, models.GrantRefreshToken}
+
+// CreateApp creates the app and returns it with its secret, which is
+// not stored and can not be shown again. Public apps get no secret.
+func (s *Service) CreateApp(ctx context.Context, adminID int64, app models.App) (models.App, string, error) {
+	const op = "admin.CreateApp"
+
+	if app.GrantTypes == "" {
+		app.GrantTypes = strings.Join(DefaultGrantTypes, " ")
+	}
+
+	if err := validateApp(app); err != nil {
+		return models.App{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var secret string
+	if !app.Public {
+		var err error
+		if secret, err = appsecret.Generate(); err != nil {
+			return models.App{}, "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		app.SecretHash = appsecret.Hash(secret)
+		if s.keepAppSecrets {
+			app.Secret = secret
+		}
+	}
+
+	app, err := s.apps.SaveApp(ctx, app)
+	if err != nil {
+		return models.App{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "app created", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("app_id", app.ID))
+
+	return withoutSecret(app), secret, nil
+}
+
+// App returns the app with its redirect URIs and scopes.
+func (s *Service) App(ctx context.Context, appID int64) (models.App, error) {
+	const op = "admin.App"
+
+	app, err := s.apps.App(ctx, appID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if app.RedirectURIs, err = s.apps.AppRedirectURIs(ctx, appID); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if app.Scopes, err = s.apps.AppScopes(ctx, appID); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return withoutSecret(app), nil
+}
+
+func (s *Service) ListApps(ctx context.Context) ([]models.App, error) {
+	const op = "admin.ListApps"
+
+	apps, err := s.apps.Apps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := range apps {
+		apps[i] = withoutSecret(apps[i])
+	}
+
+	return apps, nil
+}
+
+// UpdateApp replaces the metadata, redirect URIs and scopes of the app.
+// The secret is not changed.
+func (s *Service) UpdateApp(ctx context.Context, adminID int64, app models.App) (models.App, error) {
+	const op = "admin.UpdateApp"
+
+	// whether the app is public is fixed when it is created
+	stored, err := s.apps.App(ctx, app.ID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+	app.Public = stored.Public
+
+	if err := validateApp(app); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.apps.UpdateApp(ctx, app); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "app updated", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("app_id", app.ID))
+
+	app, err = s.App(ctx, app.ID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return app, nil
+}
+
+// RotateAppSecret replaces the secret of the app and returns the new one.
+// The old secret stops working immediately.
+func (s *Service) RotateAppSecret(ctx context.Context, adminID, appID int64) (string, error) {
+	const op = "admin.RotateAppSecret"
+
+	app, err := s.apps.App(ctx, appID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if app.Public {
+		return "", fmt.Errorf("%s: %w: public apps have no secret", op, ErrInvalidApp)
+	}
+
+	secret, err := appsecret.Generate()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var plain string
+	if s.keepAppSecrets {
+		plain = secret
+	}
+
+	if err = s.apps.SetAppSecret(ctx, appID, plain, appsecret.Hash(secret)); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "app secret rotated", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("app_id", appID))
+
+	return secret, nil
+}
+
+// DropPlainAppSecrets drops the plain app secrets kept from HS256 signing
+// and returns how many were dropped. Apps keep authenticating with their
+// hashes, but tokens without a kid can no longer be verified, and the
+// secrets can not be restored if signing is switched back to HS256.
+func (s *Service) DropPlainAppSecrets(ctx context.Context) (int64, error) {
+	const op = "admin.DropPlainAppSecrets"
+
+	if s.keepAppSecrets {
+		return 0, fmt.Errorf("%s: %w", op, ErrAppSecretsInUse)
+	}
+
+	dropped, err := s.apps.ClearAppSecrets(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.WarnContext(ctx, "plain app secrets dropped", slog.String("op", op), slog.Int64("count", dropped))
+
+	return dropped, nil
+}
+
+// DeleteApp deletes the app with its sessions and settings.
+func (s *Service) DeleteApp(ctx context.Context, adminID, appID int64) error {
+	const op = "admin.DeleteApp"
+
+	if err := s.apps.DeleteApp(ctx, appID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "app deleted", slog.String("op", op), slog.Int64("admin_id", adminID), slog.Int64("app_id", appID))
+
+	return nil
+}
+
+func validateApp(app models.App) error {
+	if strings.TrimSpace(app.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidApp)
+	}
+
+	grants := strings.Fields(app.GrantTypes)
+	if len(grants) == 0 {
+		return fmt.Errorf("%w: at least one grant type is required", ErrInvalidApp)
+	}
+	for _, g := range grants {
+		if !slices.Contains(GrantTypes, g) {
+			return fmt.Errorf("%w: unknown grant type %q", ErrInvalidApp, g)
+		}
+	}
+
+	// a public app has no secret to authenticate the grant with
+	if app.Public && slices.Contains(grants, models.GrantClientCredentials) {
+		return fmt.Errorf("%w: public apps can not use the client credentials grant", ErrInvalidApp)
+	}
+
+	if app.TokenTTLSeconds < 0 {
+		return fmt.Errorf("%w: token TTL must not be negative", ErrInvalidApp)
+	}
+
+	for _, uri := range app.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			return fmt.Errorf("%w: redirect URI %q must be absolute without a fragment", ErrInvalidApp, uri)
+		}
+	}
+
+	if app.LogoURI != "" {
+		u, err := url.Parse(app.LogoURI)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: logo URI must be an http(s) URL", ErrInvalidApp)
+		}
+	}
+
+	for _, scope := range app.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\n") {
+			return fmt.Errorf("%w: invalid scope %q", ErrInvalidApp, scope)
+		}
+	}
+
+	return nil
+}
+
+// withoutSecret clears the secrets, they must never leave the service.
+func withoutSecret(app models.App) models.App {
+	app.Secret = ""
+	app.SecretHash = ""
+
+	return app
+}
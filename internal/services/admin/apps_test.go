@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+// storedApp returns the app as stored, with its secrets.
+func storedApp(t *testing.T, s *memory.Storage, appID int64) models.App {
+	t.Helper()
+
+	app, err := s.App(context.Background(), appID)
+	if err != nil {
+		t.Fatalf("stored app: %v", err)
+	}
+
+	return app
+}
+
+func TestCreateAppSecret(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		ctx := context.Background()
+		s := memory.New()
+		svc := newTestService(s, keep)
+
+		app, secret, err := svc.CreateApp(ctx, 1, models.App{Name: "app"})
+		if err != nil {
+			t.Fatalf("CreateApp() error = %v", err)
+		}
+		if secret == "" {
+			t.Fatalf("CreateApp() returned no secret")
+		}
+		if app.Secret != "" || app.SecretHash != "" {
+			t.Fatalf("CreateApp() returned the stored secret %+v", app)
+		}
+
+		stored := storedApp(t, s, app.ID)
+		if stored.SecretHash == secret || !appsecret.Verify(secret, stored.SecretHash) {
+			t.Fatalf("stored hash %q does not hash the secret", stored.SecretHash)
+		}
+		// the plain secret is kept only to sign HS256 tokens
+		if keep && stored.Secret != secret || !keep && stored.Secret != "" {
+			t.Fatalf("stored plain secret = %q with keepAppSecrets %v", stored.Secret, keep)
+		}
+
+		// the secret is never shown again
+		if got, err := svc.App(ctx, app.ID); err != nil || got.Secret != "" || got.SecretHash != "" {
+			t.Fatalf("App() = %+v, %v, want no secret", got, err)
+		}
+		apps, err := svc.ListApps(ctx)
+		if err != nil || len(apps) != 1 || apps[0].Secret != "" || apps[0].SecretHash != "" {
+			t.Fatalf("ListApps() = %+v, %v, want no secret", apps, err)
+		}
+	}
+}
+
+func TestCreatePublicApp(t *testing.T) {
+	s := memory.New()
+	svc := newTestService(s, true)
+
+	app, secret, err := svc.CreateApp(context.Background(), 1, models.App{Name: "spa", Public: true})
+	if err != nil {
+		t.Fatalf("CreateApp() error = %v", err)
+	}
+	if secret != "" {
+		t.Fatalf("CreateApp() returned a secret for a public app")
+	}
+	if stored := storedApp(t, s, app.ID); stored.Secret != "" || stored.SecretHash != "" {
+		t.Fatalf("public app stored with a secret")
+	}
+
+	if _, err = svc.RotateAppSecret(context.Background(), 1, app.ID); !errors.Is(err, ErrInvalidApp) {
+		t.Fatalf("RotateAppSecret() error = %v, want %v", err, ErrInvalidApp)
+	}
+
+	_, _, err = svc.CreateApp(context.Background(), 1, models.App{Name: "cli", Public: true, GrantTypes: models.GrantClientCredentials})
+	if !errors.Is(err, ErrInvalidApp) {
+		t.Fatalf("CreateApp() public client credentials error = %v, want %v", err, ErrInvalidApp)
+	}
+}
+
+func TestRotateAppSecret(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, true)
+
+	app, old, err := svc.CreateApp(ctx, 1, models.App{Name: "app"})
+	if err != nil {
+		t.Fatalf("CreateApp() error = %v", err)
+	}
+
+	secret, err := svc.RotateAppSecret(ctx, 1, app.ID)
+	if err != nil {
+		t.Fatalf("RotateAppSecret() error = %v", err)
+	}
+	if secret == "" || secret == old {
+		t.Fatalf("RotateAppSecret() = %q, want a new secret", secret)
+	}
+
+	stored := storedApp(t, s, app.ID)
+	if appsecret.Verify(old, stored.SecretHash) || stored.Secret == old {
+		t.Fatalf("old secret still works")
+	}
+	if !appsecret.Verify(secret, stored.SecretHash) || stored.Secret != secret {
+		t.Fatalf("new secret is not stored")
+	}
+}
+
+func TestAppMetadata(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	svc := newTestService(s, false)
+
+	created, _, err := svc.CreateApp(ctx, 1, models.App{
+		Name:            "app",
+		GrantTypes:      models.GrantAuthorizationCode + " " + models.GrantClientCredentials,
+		TokenTTLSeconds: 900,
+		LogoURI:         "https://app.example.com/logo.png",
+		RedirectURIs:    []string{"https://app.example.com/a", "https://app.example.com/b"},
+		Scopes:          []string{"docs:read", "docs:write"},
+	})
+	if err != nil {
+		t.Fatalf("CreateApp() error = %v", err)
+	}
+
+	equal := func(got, want models.App) bool {
+		return got.ID == want.ID && got.Name == want.Name && got.GrantTypes == want.GrantTypes &&
+			got.TokenTTLSeconds == want.TokenTTLSeconds && got.LogoURI == want.LogoURI && got.Public == want.Public &&
+			slices.Equal(got.RedirectURIs, want.RedirectURIs) && slices.Equal(got.Scopes, want.Scopes)
+	}
+
+	app, err := svc.App(ctx, created.ID)
+	if err != nil || !equal(app, created) {
+		t.Fatalf("App() = %+v, %v, want %+v", app, err, created)
+	}
+
+	hash := storedApp(t, s, app.ID).SecretHash
+
+	update := app
+	update.Name = "renamed"
+	update.GrantTypes = models.GrantAuthorizationCode
+	update.TokenTTLSeconds = 0
+	update.LogoURI = ""
+	update.RedirectURIs = []string{"https://app.example.com/c"}
+	update.Scopes = nil
+	// whether an app is public can not be changed
+	update.Public = true
+
+	updated, err := svc.UpdateApp(ctx, 1, update)
+	if err != nil {
+		t.Fatalf("UpdateApp() error = %v", err)
+	}
+	update.Public = false
+	if !equal(updated, update) {
+		t.Fatalf("UpdateApp() = %+v, want %+v", updated, update)
+	}
+	if app, err = svc.App(ctx, created.ID); err != nil || !equal(app, update) {
+		t.Fatalf("App() after update = %+v, %v, want %+v", app, err, update)
+	}
+	if storedApp(t, s, app.ID).SecretHash != hash {
+		t.Fatalf("UpdateApp() changed the secret")
+	}
+
+	invalid := []models.App{
+		{Name: " "},
+		{Name: "app", GrantTypes: "password"},
+		{Name: "app", TokenTTLSeconds: -1},
+		{Name: "app", RedirectURIs: []string{"/callback"}},
+		{Name: "app", RedirectURIs: []string{"https://app.example.com/#fragment"}},
+		{Name: "app", LogoURI: "javascript:alert(1)"},
+		{Name: "app", Scopes: []string{"docs read"}},
+	}
+	for _, app := range invalid {
+		app.ID = created.ID
+		if _, err = svc.UpdateApp(ctx, 1, app); !errors.Is(err, ErrInvalidApp) {
+			t.Errorf("UpdateApp(%+v) error = %v, want %v", app, err, ErrInvalidApp)
+		}
+	}
+}
+
+func TestDropPlainAppSecrets(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+
+	app, secret, err := newTestService(s, true).CreateApp(ctx, 1, models.App{Name: "app"})
+	if err != nil {
+		t.Fatalf("CreateApp() error = %v", err)
+	}
+
+	// the secrets sign the tokens while HS256 is used
+	if _, err = newTestService(s, true).DropPlainAppSecrets(ctx); !errors.Is(err, ErrAppSecretsInUse) {
+		t.Fatalf("DropPlainAppSecrets() with HS256 error = %v, want %v", err, ErrAppSecretsInUse)
+	}
+	if storedApp(t, s, app.ID).Secret != secret {
+		t.Fatalf("plain secret dropped while in use")
+	}
+
+	svc := newTestService(s, false)
+
+	dropped, err := svc.DropPlainAppSecrets(ctx)
+	if err != nil || dropped != 1 {
+		t.Fatalf("DropPlainAppSecrets() = %d, %v, want 1", dropped, err)
+	}
+
+	stored := storedApp(t, s, app.ID)
+	if stored.Secret != "" || !appsecret.Verify(secret, stored.SecretHash) {
+		t.Fatalf("stored app after dropping = %+v, want only the hash", stored)
+	}
+
+	if dropped, err = svc.DropPlainAppSecrets(ctx); err != nil || dropped != 0 {
+		t.Fatalf("DropPlainAppSecrets() again = %d, %v, want 0", dropped, err)
+	}
+}
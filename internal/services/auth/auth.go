@@ -5,17 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net/smtp"
 	"time"
 
 	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/emails"
 	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
 	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/lib/metrics"
 	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
 	"github.com/orenvadi/auth-grpc/internal/storage"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// tracer starts a span per method, the storage queries become its children.
+var tracer = otel.Tracer("github.com/orenvadi/auth-grpc/internal/services/auth")
+
 type Auth struct {
 	log                  *slog.Logger
 	usrSaver             UserSaver
@@ -24,11 +29,24 @@ type Auth struct {
 	appProvider          AppProvider
 	passwordResetter     PasswordResetter
 	emailConfirmProvider EmailConfirmProvider
+	refreshTokenProvider RefreshTokenProvider
+	tokenRevoker         TokenRevoker
+	keys                 jwtn.KeyProvider
+	keyRotator           KeyRotator
+	mfaProvider          MFAProvider
+	outboxProvider       OutboxProvider
+	rbacProvider         RBACProvider
+	orgProvider          OrgProvider
+	totpIssuer           string
 	tokenTTL             time.Duration
+	refreshTokenTTL      time.Duration
+	orgInvitationTTL     time.Duration
 }
 
+// UserSaver saves new users. The email confirmation code is saved and the
+// email with it is queued in the same transaction.
 type UserSaver interface {
-	SaveUser(ctx context.Context, firstName, lastName, phoneNumber, email string, passwordHash []byte) (uid int64, err error)
+	SaveUser(ctx context.Context, user models.User, confirmCode string, confirmEmail models.OutboxEmail) (uid int64, err error)
 }
 
 type UserUpdater interface {
@@ -47,7 +65,7 @@ type AppProvider interface {
 }
 
 type EmailConfirmProvider interface {
-	SaveConfirmationCode(ctx context.Context, userID int64, code string) error
+	SaveConfirmationCode(ctx context.Context, userID int64, code string, email models.OutboxEmail) error
 	ConfirmationCode(ctx context.Context, userID int64) (confCode models.ConfirmCode, err error)
 	DeleteConfirmationCode(ctx context.Context, user_id int64) error
 }
@@ -61,6 +79,7 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidAppID       = errors.New("invalid app_id")
 	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrUserDisabled       = errors.New("user is disabled")
 )
 
 // New return a new instance of the Auth service.
@@ -72,7 +91,18 @@ func New(
 	appProvider AppProvider,
 	emailConfirmProvider EmailConfirmProvider,
 	passwordResetter PasswordResetter,
+	refreshTokenProvider RefreshTokenProvider,
+	tokenRevoker TokenRevoker,
+	keys jwtn.KeyProvider,
+	keyRotator KeyRotator,
+	mfaProvider MFAProvider,
+	outboxProvider OutboxProvider,
+	rbacProvider RBACProvider,
+	orgProvider OrgProvider,
+	totpIssuer string,
 	tokenTTL time.Duration,
+	refreshTokenTTL time.Duration,
+	orgInvitationTTL time.Duration,
 ) *Auth {
 	return &Auth{
 		log:                  log,
@@ -82,192 +112,238 @@ func New(
 		appProvider:          appProvider,
 		emailConfirmProvider: emailConfirmProvider,
 		passwordResetter:     passwordResetter,
+		refreshTokenProvider: refreshTokenProvider,
+		tokenRevoker:         tokenRevoker,
+		keys:                 keys,
+		keyRotator:           keyRotator,
+		mfaProvider:          mfaProvider,
+		outboxProvider:       outboxProvider,
+		rbacProvider:         rbacProvider,
+		orgProvider:          orgProvider,
+		totpIssuer:           totpIssuer,
 		tokenTTL:             tokenTTL,
+		refreshTokenTTL:      refreshTokenTTL,
+		orgInvitationTTL:     orgInvitationTTL,
 	}
 }
 
-// Login checks if user with given credentials exists in the system and returns access token.
+// Login checks if user with given credentials exists in the system and returns
+// access token together with a refresh token starting a new token family.
+//
+// If the user has two-factor authentication enabled, only mfaToken is returned,
+// to be exchanged for the tokens with CompleteMFALogin.
 //
 // If user exists, but password is incorrect, returns error.
 // If user doesn't exist, returns error.
-func (a *Auth) Login(ctx context.Context, email, password string, appID int64) (accessToken string, err error) {
+func (a *Auth) Login(ctx context.Context, email, password string, appID int64) (accessToken, refreshToken, mfaToken string, err error) {
 	const op = "auth.Login"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	log := a.log.With(
 		slog.String("op: ", op),
 		// slog.String("email: ", email), // do not do that
 	)
 
-	log.Info("attempting to login user")
+	log.InfoContext(ctx, "attempting to login user")
+
+	defer func() {
+		metrics.Logins.WithLabelValues(loginOutcome(mfaToken, err)).Inc()
+	}()
+
+	user, err := a.Authenticate(ctx, email, password)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	mfaToken, err = a.NewMFAChallenge(ctx, user, app)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to create mfa challenge", sl.Err(err))
+
+		return "", "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	if mfaToken != "" {
+		log.InfoContext(ctx, "second factor required")
+
+		return "", "", mfaToken, nil
+	}
+
+	log.InfoContext(ctx, "user logged in successfully")
+
+	accessToken, refreshToken, err = a.IssueTokens(ctx, user, app, []string{AuthMethodPassword})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to issue tokens", sl.Err(err))
+
+		return "", "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return accessToken, refreshToken, "", nil
+}
+
+// loginOutcome returns the metrics label of the result of a login.
+func loginOutcome(mfaToken string, err error) string {
+	switch {
+	case err == nil && mfaToken != "":
+		return metrics.LoginMFARequired
+	case err == nil:
+		return metrics.LoginSuccess
+	case errors.Is(err, ErrInvalidCredentials):
+		return metrics.LoginInvalidCredentials
+	case errors.Is(err, ErrUserDisabled):
+		return metrics.LoginDisabled
+	}
+
+	return metrics.LoginError
+}
+
+// Authenticate checks the password of the user with the given email.
+//
+// Unknown email and wrong password both result in ErrInvalidCredentials.
+func (a *Auth) Authenticate(ctx context.Context, email, password string) (models.User, error) {
+	const op = "auth.Authenticate"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+	)
 
 	user, err := a.usrProvider.User(ctx, email)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
+			a.log.WarnContext(ctx, "user not found", sl.Err(err))
 
-			return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			return models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 		}
 
-		log.Warn("user not found", sl.Err(err))
-		return "", fmt.Errorf("%s: %w", op, err)
+		log.WarnContext(ctx, "user not found", sl.Err(err))
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
-		log.Info("invalid credentials", sl.Err(err))
+		log.InfoContext(ctx, "invalid credentials", sl.Err(err))
 
-		return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		return models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 	}
 
-	app, err := a.appProvider.App(ctx, appID)
-	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
-	}
-	log.Info("user logged in successfully")
-
-	accessToken, err = jwtn.NewToken(user, app, a.tokenTTL)
-	if err != nil {
-		log.Error("failed to generate token", sl.Err(err))
+	// checked after the password, so it does not reveal the state of accounts
+	if user.DisabledAt != nil {
+		log.InfoContext(ctx, "disabled user attempted to log in")
 
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.User{}, fmt.Errorf("%s: %w", op, ErrUserDisabled)
 	}
 
-	return accessToken, nil
+	return user, nil
 }
 
-func (a *Auth) RegisterNewUser(ctx context.Context, firstName, lastName, phoneNumber, email, password string, appID int64) (userID int64, accessToken, refreshToken string, err error) {
+// RegisterNewUser saves a new user and queues the email confirmation code.
+// Emails are sent in locale, the default one if it is not supported.
+func (a *Auth) RegisterNewUser(ctx context.Context, firstName, lastName, phoneNumber, email, password, locale string, appID int64) (userID int64, accessToken, refreshToken string, err error) {
 	const op = "auth.RegisterNewUser"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	log := a.log.With(
 		slog.String("op: ", op),
 		// slog.String("email: ", email), // do not do that
 	)
 
-	log.Info("registering user")
+	log.InfoContext(ctx, "registering user")
 
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		log.Error("failed to generate password hash", sl.Err(err))
+		log.ErrorContext(ctx, "failed to generate password hash", sl.Err(err))
 		return 0, "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	userID, err = a.usrSaver.SaveUser(ctx, firstName, lastName, phoneNumber, email, passwordHash)
+	// the app is checked first, so the user is not saved if tokens can not be issued
+	app, err := a.appProvider.App(ctx, appID)
 	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			a.log.WarnContext(ctx, "user not found", sl.Err(err))
 
-		if errors.Is(err, storage.ErrUserExists) {
-			a.log.Warn("user already exists", sl.Err(err))
-
-			return 0, "", "", fmt.Errorf("%s: %w", op, ErrUserAlreadyExists)
+			return -1, "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 		}
 
-		log.Error("failed to save user", sl.Err(err))
-		return 0, "", "", fmt.Errorf("%s: %w", op, err)
+		log.WarnContext(ctx, "user not found", sl.Err(err))
+		return -1, "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
 	user := models.User{
-		ID:           userID,
 		FirstName:    firstName,
 		LastName:     lastName,
 		PhoneNumber:  phoneNumber,
 		Email:        email,
 		PasswordHash: passwordHash,
+		Locale:       emails.Locale(locale),
 	}
 
-	app, err := a.appProvider.App(ctx, appID)
-	if err != nil {
-		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
-
-			return -1, "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
-		}
+	// Confirmation code is emailed by the outbox worker
+	rndCode := rnd.GenerateRandomNumber()
 
-		log.Warn("user not found", sl.Err(err))
-		return -1, "", "", fmt.Errorf("%s: %w", op, err)
+	confirmEmail, err := codeEmail(emails.VerifyEmail, user, rndCode)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	accessToken, err = jwtn.NewToken(user, app, a.tokenTTL)
+	userID, err = a.usrSaver.SaveUser(ctx, user, rndCode, confirmEmail)
 	if err != nil {
-		log.Error("failed to generate token", sl.Err(err))
 
+		if errors.Is(err, storage.ErrUserExists) {
+			a.log.WarnContext(ctx, "user already exists", sl.Err(err))
+
+			return 0, "", "", fmt.Errorf("%s: %w", op, ErrUserAlreadyExists)
+		}
+
+		log.ErrorContext(ctx, "failed to save user", sl.Err(err))
 		return 0, "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Info("user registered")
+	metrics.Registrations.Inc()
+	metrics.CodesIssued.WithLabelValues(metrics.CodeVerifyEmail).Inc()
 
-	// Sending confirmation code
-	rndCode := rnd.GenerateRandomNumber()
+	user.ID = userID
 
-	if err = a.emailConfirmProvider.SaveConfirmationCode(ctx, user.ID, rndCode); err != nil {
-		return 0, "", "", fmt.Errorf("%s: %w", op, err)
-	}
+	accessToken, refreshToken, err = a.IssueTokens(ctx, user, app, []string{AuthMethodPassword})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to issue tokens", sl.Err(err))
 
-	if err = sendVerificationEmail(user.Email, rndCode); err != nil {
 		return 0, "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	return userID, accessToken, "", nil
-}
+	log.InfoContext(ctx, "user registered")
 
-func sendVerificationEmail(email, code string) error {
-	auth := smtp.PlainAuth("", "eligdigital@gmail.com", "dqwqqgtxbbuwobgt", "smtp.gmail.com")
-	to := []string{email}
-
-	htmlMsg := `
-    <html>
-    <body>
-        <h1 style="text-align: center;">Email Verification Code</h1>
-        <p style="text-align: center; font-size: 20px;">Your verification code is:</p>
-        <div style="text-align: center; font-size: 30px; border: 2px solid #000; padding: 10px; margin: 20px;">` + code + `</div>
-    </body>
-    </html>
-    `
-
-	err := smtp.SendMail("smtp.gmail.com:587", auth, "eligdigital@gmail.com", to, []byte(
-		"From: eligdigital@gmail.com\r\n"+
-			"To: "+email+"\r\n"+
-			"Subject: Email Verification Code\r\n"+
-			"MIME-Version: 1.0\r\n"+
-			"Content-Type: text/html; charset=utf-8\r\n"+
-			"\r\n"+
-			htmlMsg,
-	))
-	if err != nil {
-		return err
-	}
-	return nil
+	return userID, accessToken, refreshToken, nil
 }
 
 func (a *Auth) ConfirmUserEmail(ctx context.Context, confirmCode string, appID int64) (success bool, err error) {
 	const op = "auth.ConfirmUserEmail"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	log := a.log.With(
 		slog.String("op: ", op),
 		// slog.String("user_email", email),
 	)
 
-	// Extract username from token
-
-	app, err := a.appProvider.App(ctx, appID)
+	user, err := a.requestUser(ctx, appID)
 	if err != nil {
-		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
-
-			return false, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
-		}
-
-		log.Warn("user not found", sl.Err(err))
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
-
-	claims, err := jwtn.ValidateToken(ctx, app)
-	if err != nil {
-		return false, fmt.Errorf("invalid token claims")
-	}
-	userID := claims["uid"].(float64)
-	uid := int64(userID)
+	uid := user.ID
 
 	// logicccc
 
-	log.Info("confirming user")
+	log.InfoContext(ctx, "confirming user")
 
 	confCodeFromDB, err := a.emailConfirmProvider.ConfirmationCode(ctx, uid)
 	if err != nil {
@@ -277,68 +353,48 @@ func (a *Auth) ConfirmUserEmail(ctx context.Context, confirmCode string, appID i
 	now := time.Now()
 
 	if confirmCode != confCodeFromDB.Code {
+		metrics.CodesVerified.WithLabelValues(metrics.CodeVerifyEmail, metrics.CodeInvalid).Inc()
 		return false, fmt.Errorf("%s: invalid confirm code", op)
 	}
 
 	if now.Sub(confCodeFromDB.CreatedAt) > (5 * time.Minute) {
+		metrics.CodesVerified.WithLabelValues(metrics.CodeVerifyEmail, metrics.CodeExpired).Inc()
 		return false, fmt.Errorf("%s: confirm code is expired", op)
 	}
 
+	metrics.CodesVerified.WithLabelValues(metrics.CodeVerifyEmail, metrics.CodeValid).Inc()
+
 	if err = a.usrProvider.UserEmailConfirm(ctx, uid); err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err = a.emailConfirmProvider.DeleteConfirmationCode(ctx, confCodeFromDB.ID); err != nil {
+	if err = a.emailConfirmProvider.DeleteConfirmationCode(ctx, uid); err != nil {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
 	// logicccc end
 
-	log.Info("user confirmed")
+	log.InfoContext(ctx, "user confirmed")
 	return true, nil
 }
 
-// UpdateUser updates user information.
-func (a *Auth) UpdateUser(ctx context.Context, firstName, lastName, phoneNumber, email string, appID int64) error {
+// UpdateUser updates the profile of the user of the access token.
+// The locale is kept if empty.
+func (a *Auth) UpdateUser(ctx context.Context, firstName, lastName, phoneNumber, email, locale string, appID int64) error {
 	const op = "auth.UpdateUser"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	log := a.log.With(
 		slog.String("op: ", op),
 		// slog.String("user_email", email),
 	)
 
-	// Extract username from token
+	log.InfoContext(ctx, "updating user")
 
-	app, err := a.appProvider.App(ctx, appID)
+	user, err := a.requestUser(ctx, appID)
 	if err != nil {
-		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
-
-			return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
-		}
-
-		log.Warn("user not found", sl.Err(err))
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	claims, err := jwtn.ValidateToken(ctx, app)
-	if err != nil {
-		return fmt.Errorf("invalid token claims")
-	}
-	userID := claims["uid"].(float64)
-	uid := int64(userID)
-
-	log.Info("updating user")
-
-	// Retrieve the user from the storage
-	user, err := a.usrProvider.UserAllData(ctx, uid)
-	if err != nil {
-		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
-			return ErrInvalidCredentials // or ErrUserNotFound
-		}
-
-		log.Warn("user not found", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -347,47 +403,57 @@ func (a *Auth) UpdateUser(ctx context.Context, firstName, lastName, phoneNumber,
 	user.LastName = lastName
 	user.PhoneNumber = phoneNumber
 	user.Email = email
+	if locale != "" {
+		user.Locale = emails.Locale(locale)
+	}
 
 	// Hash and update password if provided
 	// if password != "" {
 	// 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	// 	if err != nil {
-	// 		log.Error("failed to generate password hash", sl.Err(err))
+	// 		log.ErrorContext(ctx, "failed to generate password hash", sl.Err(err))
 	// 		return fmt.Errorf("%s: %w", op, err)
 	// 	}
 	// 	user.PasswordHash = passwordHash
 	// }
 
-	// log.Info("upd: ", sl.Err(fmt.Errorf(fmt.Sprintf("%v", user))))
+	// log.InfoContext(ctx, "upd: ", sl.Err(fmt.Errorf(fmt.Sprintf("%v", user))))
 
 	// Save updated user information to the storage
 
 	err = a.usrUpdater.UpdateUser(ctx, user)
 	if err != nil {
-		log.Error("failed to update user", sl.Err(err))
+		log.ErrorContext(ctx, "failed to update user", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Info("user updated successfully")
+	log.InfoContext(ctx, "user updated successfully")
 
 	return nil
 }
 
+// IsAdmin reports whether the user has the global admin flag. Roles named
+// admin in apps are not taken into account.
+//
+// Deprecated: roles are scoped per app, use CheckPermission.
 func (a *Auth) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	const op = "Auth.IsAdmin"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	log := a.log.With(
 		slog.String("op", op),
 		slog.Int64("user_id", userID),
 	)
 
-	log.Info("checking if user is admin")
+	log.InfoContext(ctx, "checking if user is admin")
 
 	isAdmin, err := a.usrProvider.IsAdmin(ctx, userID)
 	if err != nil {
 
 		if errors.Is(err, storage.ErrAppNotFound) {
-			a.log.Warn("app not found", sl.Err(err))
+			a.log.WarnContext(ctx, "app not found", sl.Err(err))
 
 			return false, fmt.Errorf("%s: %w", op, err)
 		}
@@ -395,7 +461,7 @@ func (a *Auth) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Info("checked if user is admin", slog.Bool("is_admin", isAdmin))
+	log.InfoContext(ctx, "checked if user is admin", slog.Bool("is_admin", isAdmin))
 
 	return isAdmin, nil
 }
@@ -403,33 +469,10 @@ func (a *Auth) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 func (a *Auth) GetUserData(ctx context.Context, appID int64) (models.User, error) {
 	const op = "auth.GetUserData"
 
-	log := a.log.With(
-		slog.String("op: ", op),
-		// slog.String("user_email", email),
-	)
-
-	// Extract username from token
-
-	app, err := a.appProvider.App(ctx, appID)
-	if err != nil {
-		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
-
-			return models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
-		}
-
-		log.Warn("user not found", sl.Err(err))
-		return models.User{}, fmt.Errorf("%s: %w", op, err)
-	}
-
-	claims, err := jwtn.ValidateToken(ctx, app)
-	if err != nil {
-		return models.User{}, fmt.Errorf("invalid token claims")
-	}
-	userID := claims["uid"].(float64)
-	uid := int64(userID)
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	user, err := a.usrProvider.UserAllData(ctx, uid)
+	user, err := a.requestUser(ctx, appID)
 	if err != nil {
 		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -440,6 +483,9 @@ func (a *Auth) GetUserData(ctx context.Context, appID int64) (models.User, error
 func (a *Auth) SendCodeToResetPassword(ctx context.Context, email string) error {
 	const op = "auth.SendCodeToResetPassword"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	isEmailConfirmed, err := a.passwordResetter.IsEmailConfirmed(ctx, email)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -457,20 +503,26 @@ func (a *Auth) SendCodeToResetPassword(ctx context.Context, email string) error
 	// Sending confirmation code
 	rndCode := rnd.GenerateRandomNumber()
 
-	if err = sendVerificationEmail(email, rndCode); err != nil {
+	resetEmail, err := codeEmail(emails.ResetPassword, user, rndCode)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err = a.emailConfirmProvider.SaveConfirmationCode(ctx, user.ID, rndCode); err != nil {
+	if err = a.emailConfirmProvider.SaveConfirmationCode(ctx, user.ID, rndCode, resetEmail); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	metrics.CodesIssued.WithLabelValues(metrics.CodePasswordReset).Inc()
+
 	return nil
 }
 
 func (a *Auth) SetNewPassword(ctx context.Context, confirmCode, email string, newPassword string) error {
 	const op = "auth.SetNewPassword"
 
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
 	log := a.log.With(
 		slog.String("op: ", op),
 		// slog.String("email: ", email), // do not do that
@@ -488,7 +540,7 @@ func (a *Auth) SetNewPassword(ctx context.Context, confirmCode, email string, ne
 
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
-		log.Error("failed to generate password hash", sl.Err(err))
+		log.ErrorContext(ctx, "failed to generate password hash", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -504,25 +556,31 @@ func (a *Auth) SetNewPassword(ctx context.Context, confirmCode, email string, ne
 	// now := time.Now()
 
 	if confirmCode != confCodeFromDB.Code {
+		metrics.CodesVerified.WithLabelValues(metrics.CodePasswordReset, metrics.CodeInvalid).Inc()
 		return fmt.Errorf("%s: invalid confirm code", op)
 	}
 
 	if elapsedTime := now.Sub(confCodeFromDB.CreatedAt.In(location)); elapsedTime > (5 * time.Minute) {
+		metrics.CodesVerified.WithLabelValues(metrics.CodePasswordReset, metrics.CodeExpired).Inc()
 		return fmt.Errorf("%s: confirm code is expired", op)
 	}
 
+	metrics.CodesVerified.WithLabelValues(metrics.CodePasswordReset, metrics.CodeValid).Inc()
+
 	if err = a.usrProvider.UserEmailConfirm(ctx, uid); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err = a.emailConfirmProvider.DeleteConfirmationCode(ctx, confCodeFromDB.ID); err != nil {
+	if err = a.emailConfirmProvider.DeleteConfirmationCode(ctx, uid); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	if err = a.passwordResetter.ChangePassword(ctx, userAllData.Email, passwordHash); err != nil {
-		log.Error("failed to change pass", sl.Err(err))
+		log.ErrorContext(ctx, "failed to change pass", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	metrics.PasswordResets.Inc()
+
 	return nil
 }
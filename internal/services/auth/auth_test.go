@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/mailer"
+	"github.com/orenvadi/auth-grpc/internal/services/outbox"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+func newTestAuth(s *memory.Storage) *Auth {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return New(log, s, s, s, s, s, s, s, s, jwtn.NewStaticKeys(nil, time.Time{}), nil, s, s, s, s, "test", time.Hour, time.Hour, time.Hour)
+}
+
+func saveTestUser(t *testing.T, s *memory.Storage, email string) int64 {
+	t.Helper()
+
+	id, err := s.SaveUser(context.Background(), models.User{Email: email, PasswordHash: []byte("hash")}, "123456", models.OutboxEmail{Recipient: email})
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	return id
+}
+
+// deliver sends the queued emails with the memory mailer.
+func deliver(t *testing.T, s *memory.Storage, mail *mailer.Memory) {
+	t.Helper()
+
+	worker := outbox.New(slog.New(slog.NewTextHandler(io.Discard, nil)), s, mail, 3, time.Second, time.Minute, time.Hour)
+	if err := worker.Deliver(context.Background()); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+}
+
+// wantCodeEmail checks that the latest email to the address carries the
+// latest confirmation code of the user.
+func wantCodeEmail(t *testing.T, s *memory.Storage, mail *mailer.Memory, email string, userID int64) {
+	t.Helper()
+
+	msg, ok := mail.Last(email)
+	if !ok {
+		t.Fatalf("no email sent to %s", email)
+	}
+
+	code, err := s.ConfirmationCode(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("confirmation code: %v", err)
+	}
+
+	if !strings.Contains(msg.Text, code.Code) || !strings.Contains(msg.HTML, code.Code) {
+		t.Fatalf("email %q does not contain the code %s", msg.Subject, code.Code)
+	}
+}
+
+func TestRegisterNewUserSendsConfirmationCode(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	mail := mailer.NewMemory()
+	a := newTestAuth(s)
+
+	app, err := s.SaveApp(ctx, models.App{Name: "app", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID, _, _, err := a.RegisterNewUser(ctx, "Test", "User", "+996700000000", "user@example.com", "password", "en", app.ID)
+	if err != nil {
+		t.Fatalf("RegisterNewUser() error = %v", err)
+	}
+
+	deliver(t, s, mail)
+	wantCodeEmail(t, s, mail, "user@example.com", userID)
+
+	// a taken email queues nothing
+	mail.Reset()
+
+	_, _, _, err = a.RegisterNewUser(ctx, "Test", "User", "+996700000000", "user@example.com", "password", "en", app.ID)
+	if !errors.Is(err, ErrUserAlreadyExists) {
+		t.Fatalf("RegisterNewUser() error = %v, want %v", err, ErrUserAlreadyExists)
+	}
+
+	deliver(t, s, mail)
+	if msgs := mail.Messages(); len(msgs) != 0 {
+		t.Fatalf("%d emails sent for a taken email", len(msgs))
+	}
+}
+
+func TestSendCodeToResetPassword(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	mail := mailer.NewMemory()
+	a := newTestAuth(s)
+
+	userID := saveTestUser(t, s, "user@example.com")
+	deliver(t, s, mail)
+	mail.Reset()
+
+	// only confirmed emails get reset codes
+	if err := a.SendCodeToResetPassword(ctx, "user@example.com"); err == nil {
+		t.Fatal("SendCodeToResetPassword() of an unconfirmed email succeeded")
+	}
+
+	deliver(t, s, mail)
+	if msgs := mail.Messages(); len(msgs) != 0 {
+		t.Fatalf("%d emails sent to an unconfirmed email", len(msgs))
+	}
+
+	if err := s.UserEmailConfirm(ctx, userID); err != nil {
+		t.Fatalf("confirm email: %v", err)
+	}
+
+	if err := a.SendCodeToResetPassword(ctx, "user@example.com"); err != nil {
+		t.Fatalf("SendCodeToResetPassword() error = %v", err)
+	}
+
+	deliver(t, s, mail)
+	wantCodeEmail(t, s, mail, "user@example.com", userID)
+
+	if err := a.SendCodeToResetPassword(ctx, "missing@example.com"); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Fatalf("SendCodeToResetPassword() error = %v, want %v", err, storage.ErrUserNotFound)
+	}
+}
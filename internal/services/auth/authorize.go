@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// AuthorizeAdmin checks that the request carries a valid token of an admin
+// user, for admin calls served by other services.
+func (a *Auth) AuthorizeAdmin(ctx context.Context, appID int64) (int64, error) {
+	const op = "auth.AuthorizeAdmin"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	userID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+// authorizeAdmin checks that the request carries a valid token of an admin user.
+func (a *Auth) authorizeAdmin(ctx context.Context, appID int64) (userID int64, err error) {
+	p, err := requestPrincipal(ctx, appID)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.UserID == 0 {
+		return 0, fmt.Errorf("%w: missing uid claim", ErrInvalidToken)
+	}
+	if !p.IsAdmin {
+		return 0, ErrPermissionDenied
+	}
+	// admins must log in with a second factor, a leaked password alone grants nothing
+	if !slices.Contains(p.AuthMethods, AuthMethodMFA) {
+		return 0, ErrMFARequired
+	}
+
+	return p.UserID, nil
+}
+
+// userIDFromClaims returns the uid claim of a user token.
+func userIDFromClaims(claims jwt.MapClaims) (int64, error) {
+	uid, ok := claims["uid"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("%w: missing uid claim", ErrInvalidToken)
+	}
+
+	return int64(uid), nil
+}
+
+// requestUserID returns the user of the access token of the incoming request.
+func (a *Auth) requestUserID(ctx context.Context, appID int64) (int64, error) {
+	p, err := requestPrincipal(ctx, appID)
+	if err != nil {
+		return 0, err
+	}
+	if p.UserID == 0 {
+		return 0, fmt.Errorf("%w: missing uid claim", ErrInvalidToken)
+	}
+
+	return p.UserID, nil
+}
+
+// requestPrincipal returns the caller the auth interceptor authenticated,
+// if its token was issued for app. Tokens are verified only there, so every
+// method sees the same checks.
+func requestPrincipal(ctx context.Context, appID int64) (models.Principal, error) {
+	p, ok := jwtn.PrincipalFromContext(ctx)
+	if !ok {
+		return models.Principal{}, fmt.Errorf("%w: request is not authenticated", ErrInvalidToken)
+	}
+	if p.AppID != appID {
+		return models.Principal{}, fmt.Errorf("%w: token was issued for another app", ErrInvalidToken)
+	}
+
+	return p, nil
+}
+
+// AuthenticateToken verifies an access token issued by any app and returns
+// the caller it belongs to. Tokens of deleted or disabled users are rejected.
+func (a *Auth) AuthenticateToken(ctx context.Context, token string) (models.Principal, error) {
+	const op = "auth.AuthenticateToken"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	claims, err := a.VerifyAccessToken(ctx, token)
+	if err != nil {
+		return models.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	appID, _ := claims["app_id"].(float64)
+	orgID, _ := jwtn.OrgID(claims)
+
+	p := models.Principal{
+		AppID:       int64(appID),
+		OrgID:       orgID,
+		Roles:       jwtn.Roles(claims),
+		Permissions: jwtn.Permissions(claims),
+		Scopes:      jwtn.Scopes(claims),
+		AuthMethods: jwtn.AuthMethods(claims),
+	}
+
+	// client tokens of the client credentials grant have no user
+	if _, ok := claims["uid"]; !ok {
+		return p, nil
+	}
+
+	if p.UserID, err = userIDFromClaims(claims); err != nil {
+		return models.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.usrProvider.UserAllData(ctx, p.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.Principal{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		return models.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DisabledAt != nil {
+		return models.Principal{}, fmt.Errorf("%s: %w: %v", op, ErrInvalidToken, ErrUserDisabled)
+	}
+
+	p.IsAdmin = user.IsAdmin
+
+	return p, nil
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/emails"
+)
+
+// codeEmail renders the named email with a confirmation code in the locale
+// of the user, it is queued in the outbox together with the code.
+func codeEmail(name string, user models.User, code string) (models.OutboxEmail, error) {
+	email, err := emails.Render(name, user.Locale, emails.CodeData{
+		FirstName: user.FirstName,
+		Code:      code,
+	})
+	if err != nil {
+		return models.OutboxEmail{}, err
+	}
+
+	return models.OutboxEmail{
+		Recipient: user.Email,
+		Subject:   email.Subject,
+		HTML:      email.HTML,
+		Text:      email.Text,
+	}, nil
+}
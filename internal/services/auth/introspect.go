@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// IntrospectToken reports whether an access token is active (RFC 7662).
+//
+// Besides the signature and expiration, a token is inactive once it is revoked,
+// its app or user no longer exist, or its user is disabled. If appID is zero,
+// tokens of any app are accepted.
+//
+// Inactive tokens are not an error, only their state is returned.
+func (a *Auth) IntrospectToken(ctx context.Context, token string, appID int64) (models.TokenIntrospection, error) {
+	const op = "auth.IntrospectToken"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+		slog.Int64("app_id", appID),
+	)
+
+	if token == "" {
+		return models.TokenIntrospection{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+	}
+
+	inactive := models.TokenIntrospection{Active: false}
+
+	if appID == 0 {
+		var err error
+		if appID, err = jwtn.UnverifiedAppID(token); err != nil {
+			log.InfoContext(ctx, "malformed token", sl.Err(err))
+
+			return inactive, nil
+		}
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			log.InfoContext(ctx, "token of unknown app")
+
+			return inactive, nil
+		}
+
+		return models.TokenIntrospection{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims, err := jwtn.ParseToken(ctx, token, app, a.keys, a.tokenRevoker)
+	if err != nil {
+		log.InfoContext(ctx, "token is not active", sl.Err(err))
+
+		return inactive, nil
+	}
+
+	info := models.TokenIntrospection{
+		Active: true,
+		AppID:  app.ID,
+	}
+
+	info.Scope, _ = claims["scope"].(string)
+
+	if exp, ok := claims["exp"].(float64); ok {
+		info.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	if iat, ok := claims["iat"].(float64); ok {
+		info.IssuedAt = time.Unix(int64(iat), 0)
+	}
+
+	// tokens issued to the app itself have no user
+	if sub, ok := claims["sub"].(string); ok && claims["uid"] == nil {
+		info.Subject = sub
+
+		return info, nil
+	}
+
+	userID, err := userIDFromClaims(claims)
+	if err != nil {
+		log.InfoContext(ctx, "token has no subject", sl.Err(err))
+
+		return inactive, nil
+	}
+
+	user, err := a.usrProvider.UserAllData(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.InfoContext(ctx, "token of deleted user", slog.Int64("user_id", userID))
+
+			return inactive, nil
+		}
+
+		return models.TokenIntrospection{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DisabledAt != nil {
+		log.InfoContext(ctx, "token of disabled user", slog.Int64("user_id", userID))
+
+		return inactive, nil
+	}
+
+	info.UserID = userID
+	info.Subject = strconv.FormatInt(userID, 10)
+
+	return info, nil
+}
+
+// AuthenticateApp checks the secret of the app, e.g. of a resource server
+// calling the introspection endpoint.
+func (a *Auth) AuthenticateApp(ctx context.Context, appID int64, secret string) (models.App, error) {
+	const op = "auth.AuthenticateApp"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.App{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		}
+
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !appsecret.Verify(secret, app.SecretHash) {
+		a.log.WarnContext(ctx, "invalid app secret", slog.String("op", op), slog.Int64("app_id", appID))
+
+		return models.App{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	return app, nil
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+)
+
+func TestIntrospectToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(t *testing.T, env refreshEnv, accessToken string)
+		expired    bool
+		wantActive bool
+	}{
+		{"active", nil, false, true},
+		{"revoked", func(t *testing.T, env refreshEnv, accessToken string) {
+			if err := env.auth.Logout(context.Background(), accessToken, env.app.ID); err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+		}, false, false},
+		{"expired", nil, true, false},
+		{"disabled user", func(t *testing.T, env refreshEnv, accessToken string) {
+			if err := env.storage.DisableUser(context.Background(), env.user.ID); err != nil {
+				t.Fatalf("disable user: %v", err)
+			}
+		}, false, false},
+		{"deleted user", func(t *testing.T, env refreshEnv, accessToken string) {
+			if err := env.storage.DeleteUser(context.Background(), env.user.ID); err != nil {
+				t.Fatalf("delete user: %v", err)
+			}
+		}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newRefreshEnv(t)
+			ctx := context.Background()
+
+			if tt.expired {
+				env.auth.tokenTTL = -time.Minute
+			}
+
+			accessToken, _, err := env.auth.issueTokens(ctx, env.user, env.app, []string{AuthMethodPassword}, nil)
+			if err != nil {
+				t.Fatalf("issue tokens: %v", err)
+			}
+
+			if tt.prepare != nil {
+				tt.prepare(t, env, accessToken)
+			}
+
+			// the app is taken from the token if it is not given
+			for _, appID := range []int64{env.app.ID, 0} {
+				info, err := env.auth.IntrospectToken(ctx, accessToken, appID)
+				if err != nil {
+					t.Fatalf("IntrospectToken(%d) error = %v", appID, err)
+				}
+				if info.Active != tt.wantActive {
+					t.Fatalf("IntrospectToken(%d) active = %v, want %v", appID, info.Active, tt.wantActive)
+				}
+				if info.Active && (info.UserID != env.user.ID || info.Subject != strconv.FormatInt(env.user.ID, 10) || info.AppID != env.app.ID) {
+					t.Fatalf("IntrospectToken(%d) = %+v, want the user and app of the token", appID, info)
+				}
+				if !info.Active && info.Subject != "" {
+					t.Fatalf("IntrospectToken(%d) of inactive token discloses %+v", appID, info)
+				}
+			}
+		})
+	}
+}
+
+func TestIntrospectTokenMalformed(t *testing.T) {
+	env := newRefreshEnv(t)
+
+	if _, err := env.auth.IntrospectToken(context.Background(), "", env.app.ID); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("IntrospectToken() without a token error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	for _, appID := range []int64{env.app.ID, env.app.ID + 1, 0} {
+		info, err := env.auth.IntrospectToken(context.Background(), "forged", appID)
+		if err != nil || info.Active {
+			t.Fatalf("IntrospectToken(%d) forged token = %+v, %v, want inactive", appID, info, err)
+		}
+	}
+}
+
+func TestAuthenticateApp(t *testing.T) {
+	env := newRefreshEnv(t)
+	ctx := context.Background()
+
+	if err := env.storage.SetAppSecret(ctx, env.app.ID, "secret", appsecret.Hash("secret")); err != nil {
+		t.Fatalf("set app secret: %v", err)
+	}
+
+	if _, err := env.auth.AuthenticateApp(ctx, env.app.ID, "secret"); err != nil {
+		t.Fatalf("AuthenticateApp() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		appID  int64
+		secret string
+	}{
+		{"wrong secret", env.app.ID, "wrong"},
+		{"no secret", env.app.ID, ""},
+		{"unknown app", env.app.ID + 1, "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.auth.AuthenticateApp(ctx, tt.appID, tt.secret); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("AuthenticateApp() error = %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+}
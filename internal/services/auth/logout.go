@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+)
+
+type TokenRevoker interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Logout revokes the given access token and the refresh token family of its session.
+//
+// Logging out with an already revoked token succeeds, so clients can safely retry.
+func (a *Auth) Logout(ctx context.Context, token string, appID int64) error {
+	const op = "auth.Logout"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+		slog.Int64("app_id", appID),
+	)
+
+	log.InfoContext(ctx, "logging out user")
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var claims map[string]interface{}
+	if token == "" {
+		claims, err = jwtn.ValidateToken(ctx, app, a.keys, a.tokenRevoker)
+	} else {
+		claims, err = jwtn.ParseToken(ctx, token, app, a.keys, a.tokenRevoker)
+	}
+	if err != nil {
+		if errors.Is(err, jwtn.ErrTokenRevoked) {
+			log.InfoContext(ctx, "token is already revoked")
+
+			return nil
+		}
+
+		log.WarnContext(ctx, "invalid token", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, ErrInvalidToken)
+	}
+
+	if jti, ok := claims["jti"].(string); ok {
+		exp, _ := claims["exp"].(float64)
+
+		if err = a.tokenRevoker.RevokeToken(ctx, jti, time.Unix(int64(exp), 0)); err != nil {
+			log.ErrorContext(ctx, "failed to revoke token", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if sid, ok := claims["sid"].(string); ok {
+		if err = a.refreshTokenProvider.RevokeRefreshTokenFamily(ctx, sid); err != nil {
+			log.ErrorContext(ctx, "failed to revoke refresh tokens", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	log.InfoContext(ctx, "user logged out")
+
+	return nil
+}
+
+// PurgeRevokedTokens drops revocation entries of tokens that have expired,
+// as expired tokens are rejected by their exp claim anyway.
+func (a *Auth) PurgeRevokedTokens(ctx context.Context) error {
+	const op = "auth.PurgeRevokedTokens"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	deleted, err := a.tokenRevoker.DeleteExpiredRevokedTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.DebugContext(ctx, "purged expired revoked tokens", slog.String("op", op), slog.Int64("deleted", deleted))
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+)
+
+func TestLogout(t *testing.T) {
+	tests := []struct {
+		name   string
+		logout func(env refreshEnv, accessToken string) error
+	}{
+		{"token in the request", func(env refreshEnv, accessToken string) error {
+			return env.auth.Logout(context.Background(), accessToken, env.app.ID)
+		}},
+		{"token in the metadata", func(env refreshEnv, accessToken string) error {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+accessToken))
+			return env.auth.Logout(ctx, "", env.app.ID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newRefreshEnv(t)
+			ctx := context.Background()
+
+			accessToken, refreshToken, err := env.auth.issueTokens(ctx, env.user, env.app, []string{AuthMethodPassword}, nil)
+			if err != nil {
+				t.Fatalf("issue tokens: %v", err)
+			}
+			other := env.login(t)
+
+			if err = tt.logout(env, accessToken); err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+
+			if _, err = jwtn.ParseToken(ctx, accessToken, env.app, env.auth.keys, env.auth.tokenRevoker); !errors.Is(err, jwtn.ErrTokenRevoked) {
+				t.Fatalf("ParseToken() after logout error = %v, want %v", err, jwtn.ErrTokenRevoked)
+			}
+			if _, err = env.refresh(refreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Fatalf("RefreshToken() of the session error = %v, want %v", err, ErrInvalidRefreshToken)
+			}
+
+			// other sessions of the user are not affected
+			if _, err = env.refresh(other); err != nil {
+				t.Fatalf("RefreshToken() other session error = %v", err)
+			}
+
+			// retrying is safe
+			if err = tt.logout(env, accessToken); err != nil {
+				t.Fatalf("Logout() again error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLogoutInvalidToken(t *testing.T) {
+	env := newRefreshEnv(t)
+
+	if err := env.auth.Logout(context.Background(), "forged", env.app.ID); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Logout() error = %v, want %v", err, ErrInvalidToken)
+	}
+	if err := env.auth.Logout(context.Background(), "", env.app.ID); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Logout() without a token error = %v, want %v", err, ErrInvalidToken)
+	}
+}
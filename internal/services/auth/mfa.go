@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"github.com/orenvadi/auth-grpc/internal/lib/totp"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+type MFAProvider interface {
+	SaveTOTP(ctx context.Context, userID int64, secret string) error
+	TOTP(ctx context.Context, userID int64) (models.TOTP, error)
+	ConfirmTOTP(ctx context.Context, userID, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error
+}
+
+var (
+	ErrMFARequired        = errors.New("two-factor authentication required")
+	ErrInvalidMFACode     = errors.New("invalid two-factor authentication code")
+	ErrTOTPAlreadyEnabled = errors.New("totp is already enabled")
+	ErrTOTPNotEnrolled    = errors.New("totp enrollment not started")
+)
+
+// Authentication methods put into the amr claim, as registered by RFC 8176.
+const (
+	AuthMethodPassword = "pwd"
+	AuthMethodOTP      = "otp"
+	AuthMethodMFA      = "mfa"
+)
+
+const (
+	mfaChallengeType  = "mfa_challenge"
+	mfaChallengeTTL   = 5 * time.Minute
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 9
+)
+
+// EnrollTOTP starts TOTP enrollment of the user of the request token and
+// returns the secret with an otpauth URI for authenticator apps.
+//
+// Enrollment takes effect once confirmed with VerifyTOTPEnrollment.
+func (a *Auth) EnrollTOTP(ctx context.Context, appID int64) (secret, uri string, err error) {
+	const op = "auth.EnrollTOTP"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+		slog.Int64("app_id", appID),
+	)
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if secret, err = totp.GenerateSecret(); err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = a.mfaProvider.SaveTOTP(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, storage.ErrTOTPAlreadyConfirmed) {
+			return "", "", fmt.Errorf("%s: %w", op, ErrTOTPAlreadyEnabled)
+		}
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "totp enrollment started", slog.Int64("user_id", user.ID))
+
+	return secret, totp.URI(a.totpIssuer, user.Email, secret), nil
+}
+
+// VerifyTOTPEnrollment confirms TOTP enrollment with a code from the
+// authenticator app and returns one-time recovery codes.
+//
+// Recovery codes are stored hashed and can not be shown again.
+func (a *Auth) VerifyTOTPEnrollment(ctx context.Context, code string, appID int64) (recoveryCodes []string, err error) {
+	const op = "auth.VerifyTOTPEnrollment"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+		slog.Int64("app_id", appID),
+	)
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	enrollment, err := a.mfaProvider.TOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTOTPNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrTOTPNotEnrolled)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if enrollment.ConfirmedAt != nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrTOTPAlreadyEnabled)
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+	if !ok {
+		log.InfoContext(ctx, "invalid totp code")
+
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidMFACode)
+	}
+
+	recoveryCodes = make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		rc, err := newRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		recoveryCodes = append(recoveryCodes, rc)
+		hashes = append(hashes, hashRecoveryCode(rc))
+	}
+
+	if err = a.mfaProvider.ConfirmTOTP(ctx, user.ID, step, hashes); err != nil {
+		if errors.Is(err, storage.ErrTOTPAlreadyConfirmed) {
+			return nil, fmt.Errorf("%s: %w", op, ErrTOTPAlreadyEnabled)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "totp enabled", slog.Int64("user_id", user.ID))
+
+	return recoveryCodes, nil
+}
+
+// CompleteMFALogin exchanges the challenge token returned by Login and a TOTP
+// or recovery code for access and refresh tokens.
+func (a *Auth) CompleteMFALogin(ctx context.Context, mfaToken, code string, appID int64) (accessToken, refreshToken string, err error) {
+	const op = "auth.CompleteMFALogin"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+		slog.Int64("app_id", appID),
+	)
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, amr, err := a.VerifyMFAChallenge(ctx, mfaToken, code, app)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	accessToken, refreshToken, err = a.IssueTokens(ctx, user, app, amr)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to issue tokens", sl.Err(err))
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "user logged in with two-factor authentication")
+
+	return accessToken, refreshToken, nil
+}
+
+// NewMFAChallenge returns a challenge token for the second login step of user
+// in app, or an empty string if the user has no second factor enabled.
+func (a *Auth) NewMFAChallenge(ctx context.Context, user models.User, app models.App) (string, error) {
+	enrollment, err := a.mfaProvider.TOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTOTPNotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	if enrollment.ConfirmedAt == nil {
+		return "", nil
+	}
+
+	key, err := a.keys.SigningKey(ctx, app)
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	return jwtn.NewToken(key, user, app, mfaChallengeTTL, jwtn.WithType(mfaChallengeType))
+}
+
+// VerifyMFAChallenge checks the second factor code for a challenge token and
+// returns the user with the authentication methods used.
+//
+// A challenge can be answered only once, a wrong code requires logging in again.
+func (a *Auth) VerifyMFAChallenge(ctx context.Context, mfaToken, code string, app models.App) (models.User, []string, error) {
+	claims, err := jwtn.ParseTypedToken(ctx, mfaToken, mfaChallengeType, app, a.keys, a.tokenRevoker)
+	if err != nil {
+		return models.User{}, nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// revoke before checking the code, so codes can not be guessed with one challenge
+	if jti, ok := claims["jti"].(string); ok {
+		exp, _ := claims["exp"].(float64)
+
+		if err = a.tokenRevoker.RevokeToken(ctx, jti, time.Unix(int64(exp), 0)); err != nil {
+			return models.User{}, nil, err
+		}
+	}
+
+	userID, err := userIDFromClaims(claims)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	user, err := a.usrProvider.UserAllData(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.User{}, nil, ErrInvalidToken
+		}
+
+		return models.User{}, nil, err
+	}
+
+	amr, err := a.verifySecondFactor(ctx, user.ID, code)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	return user, amr, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code.
+func (a *Auth) verifySecondFactor(ctx context.Context, userID int64, code string) ([]string, error) {
+	enrollment, err := a.mfaProvider.TOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTOTPNotFound) {
+			return nil, ErrInvalidMFACode
+		}
+
+		return nil, err
+	}
+
+	if enrollment.ConfirmedAt == nil {
+		return nil, ErrInvalidMFACode
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+		if !ok {
+			a.log.InfoContext(ctx, "invalid totp code", slog.Int64("user_id", userID))
+
+			return nil, ErrInvalidMFACode
+		}
+
+		if err = a.mfaProvider.UseTOTPStep(ctx, userID, step); err != nil {
+			if errors.Is(err, storage.ErrTOTPStepUsed) {
+				a.log.WarnContext(ctx, "totp code replayed", slog.Int64("user_id", userID))
+
+				return nil, ErrInvalidMFACode
+			}
+
+			return nil, err
+		}
+
+		return []string{AuthMethodPassword, AuthMethodOTP, AuthMethodMFA}, nil
+	}
+
+	if err = a.mfaProvider.UseRecoveryCode(ctx, userID, hashRecoveryCode(code)); err != nil {
+		if errors.Is(err, storage.ErrRecoveryCodeNotFound) {
+			a.log.InfoContext(ctx, "invalid recovery code", slog.Int64("user_id", userID))
+
+			return nil, ErrInvalidMFACode
+		}
+
+		return nil, err
+	}
+
+	a.log.InfoContext(ctx, "recovery code used", slog.Int64("user_id", userID))
+
+	return []string{AuthMethodPassword, AuthMethodMFA}, nil
+}
+
+// requestUser returns the user of the access token of the incoming request.
+func (a *Auth) requestUser(ctx context.Context, appID int64) (models.User, error) {
+	userID, err := a.requestUserID(ctx, appID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := a.usrProvider.UserAllData(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.User{}, ErrInvalidToken
+		}
+
+		return models.User{}, err
+	}
+
+	if user.DisabledAt != nil {
+		return models.User{}, fmt.Errorf("%w: %v", ErrInvalidToken, ErrUserDisabled)
+	}
+
+	return user, nil
+}
+
+// newRecoveryCode returns a random code formatted as xxxx-xxxx-xxxx for readability.
+func newRecoveryCode() (string, error) {
+	token, err := rnd.GenerateToken(recoveryCodeBytes)
+	if err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(strings.NewReplacer("-", "x", "_", "y").Replace(token))
+
+	return code[:4] + "-" + code[4:8] + "-" + code[8:], nil
+}
+
+// hashRecoveryCode normalizes the code, so it can be typed without dashes
+// or in another case, and hashes it like refresh tokens.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
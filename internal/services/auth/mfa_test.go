@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/totp"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+type mfaEnv struct {
+	auth          *Auth
+	storage       *memory.Storage
+	app           models.App
+	user          models.User
+	secret        string
+	step          int64 // of the enrollment code
+	recoveryCodes []string
+}
+
+// newMFAEnv returns a user with confirmed TOTP.
+func newMFAEnv(t *testing.T) mfaEnv {
+	t.Helper()
+
+	ctx := context.Background()
+	s := memory.New()
+	a := newTestAuth(s)
+
+	app, err := s.SaveApp(ctx, models.App{Name: "app", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID := saveTestUser(t, s, "user@example.com")
+	ctx = jwtn.ContextWithPrincipal(ctx, models.Principal{UserID: userID, AppID: app.ID})
+
+	secret, _, err := a.EnrollTOTP(ctx, app.ID)
+	if err != nil {
+		t.Fatalf("EnrollTOTP() error = %v", err)
+	}
+
+	step := totp.Step(time.Now())
+
+	recoveryCodes, err := a.VerifyTOTPEnrollment(ctx, totpCode(t, secret, step), app.ID)
+	if err != nil {
+		t.Fatalf("VerifyTOTPEnrollment() error = %v", err)
+	}
+
+	return mfaEnv{
+		auth:          a,
+		storage:       s,
+		app:           app,
+		user:          models.User{ID: userID, Email: "user@example.com"},
+		secret:        secret,
+		step:          step,
+		recoveryCodes: recoveryCodes,
+	}
+}
+
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+
+	return code
+}
+
+func (e mfaEnv) challenge(t *testing.T) string {
+	t.Helper()
+
+	token, err := e.auth.NewMFAChallenge(context.Background(), e.user, e.app)
+	if err != nil || token == "" {
+		t.Fatalf("NewMFAChallenge() = %q, %v", token, err)
+	}
+
+	return token
+}
+
+func (e mfaEnv) answer(t *testing.T, code string) error {
+	t.Helper()
+
+	_, _, err := e.auth.VerifyMFAChallenge(context.Background(), e.challenge(t), code, e.app)
+	return err
+}
+
+func TestMFATOTPStepReplay(t *testing.T) {
+	env := newMFAEnv(t)
+
+	// the step of the enrollment code is used already
+	if err := env.answer(t, totpCode(t, env.secret, env.step)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyMFAChallenge() with the enrollment code error = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	code := totpCode(t, env.secret, env.step+1)
+	if err := env.answer(t, code); err != nil {
+		t.Fatalf("VerifyMFAChallenge() error = %v", err)
+	}
+	if err := env.answer(t, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyMFAChallenge() replayed error = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	if err := env.storage.UseTOTPStep(context.Background(), env.user.ID, env.step+1); !errors.Is(err, storage.ErrTOTPStepUsed) {
+		t.Fatalf("UseTOTPStep() error = %v, want %v", err, storage.ErrTOTPStepUsed)
+	}
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	env := newMFAEnv(t)
+	code := env.recoveryCodes[0]
+
+	_, amr, err := env.auth.VerifyMFAChallenge(context.Background(), env.challenge(t), code, env.app)
+	if err != nil {
+		t.Fatalf("VerifyMFAChallenge() error = %v", err)
+	}
+	if strings.Join(amr, " ") != AuthMethodPassword+" "+AuthMethodMFA {
+		t.Fatalf("amr = %v, want %v", amr, []string{AuthMethodPassword, AuthMethodMFA})
+	}
+
+	if err = env.answer(t, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyMFAChallenge() reused error = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	// the other codes stay valid
+	if err = env.answer(t, env.recoveryCodes[1]); err != nil {
+		t.Fatalf("VerifyMFAChallenge() other code error = %v", err)
+	}
+}
+
+func TestMFARecoveryCodeNormalization(t *testing.T) {
+	env := newMFAEnv(t)
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"upper case", strings.ToUpper(env.recoveryCodes[0])},
+		{"without dashes", strings.ReplaceAll(env.recoveryCodes[1], "-", "")},
+		{"with spaces", " " + strings.ReplaceAll(env.recoveryCodes[2], "-", " ") + " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := env.answer(t, tt.code); err != nil {
+				t.Fatalf("VerifyMFAChallenge(%q) error = %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestMFAChallengeSingleUse(t *testing.T) {
+	env := newMFAEnv(t)
+	token := env.challenge(t)
+
+	if _, _, err := env.auth.VerifyMFAChallenge(context.Background(), token, "000000", env.app); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyMFAChallenge() wrong code error = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	// a wrong code burns the challenge, the right one can not follow
+	if _, _, err := env.auth.VerifyMFAChallenge(context.Background(), token, totpCode(t, env.secret, env.step+1), env.app); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("VerifyMFAChallenge() reused challenge error = %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,390 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/emails"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// OrgProvider stores organizations, their members and invitations.
+type OrgProvider interface {
+	SaveOrganization(ctx context.Context, name, slug string, ownerID int64) (models.Organization, error)
+	Organization(ctx context.Context, orgID int64) (models.Organization, error)
+	OrgMember(ctx context.Context, orgID, userID int64) (models.OrgMember, error)
+	OrgMembers(ctx context.Context, orgID int64) ([]models.OrgMember, error)
+	UserOrgMemberships(ctx context.Context, userID int64) ([]models.OrgMember, error)
+	RemoveOrgMember(ctx context.Context, orgID, userID int64) error
+	SaveOrgInvitation(ctx context.Context, invitation models.OrgInvitation, email models.OutboxEmail) (int64, error)
+	OrgInvitation(ctx context.Context, tokenHash string) (models.OrgInvitation, error)
+	AcceptOrgInvitation(ctx context.Context, invitationID, userID int64) error
+	DeleteExpiredOrgInvitations(ctx context.Context, before time.Time) error
+}
+
+var (
+	ErrInvalidOrgRole         = errors.New("invalid organization role")
+	ErrInvalidOrgInvitation   = errors.New("invalid or expired organization invitation")
+	ErrOrgInvitationForbidden = errors.New("organization invitation is for another email")
+)
+
+const orgInvitationTokenBytes = 32
+
+// CreateOrganization creates an organization owned by the user of the request token.
+func (a *Auth) CreateOrganization(ctx context.Context, appID int64, name, slug string) (models.Organization, error) {
+	const op = "auth.CreateOrganization"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	org, err := a.orgProvider.SaveOrganization(ctx, name, slug, user.ID)
+	if err != nil {
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "organization created", slog.String("op", op), slog.Int64("org_id", org.ID), slog.Int64("user_id", user.ID))
+
+	return org, nil
+}
+
+// InviteToOrganization emails an invitation token to join the organization
+// with the role. Owners and admins invite, only owners invite owners.
+func (a *Auth) InviteToOrganization(ctx context.Context, appID, orgID int64, email, role string) (invitationID int64, err error) {
+	const op = "auth.InviteToOrganization"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if !validOrgRole(role) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidOrgRole)
+	}
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	inviter, err := a.orgManager(ctx, orgID, user.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if role == models.OrgRoleOwner && inviter.Role != models.OrgRoleOwner {
+		return 0, fmt.Errorf("%s: %w", op, ErrPermissionDenied)
+	}
+
+	token, err := rnd.GenerateToken(orgInvitationTokenBytes)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invitation := models.OrgInvitation{
+		OrgID:     orgID,
+		Email:     strings.ToLower(email),
+		Role:      role,
+		TokenHash: hashOrgInvitationToken(token),
+		InvitedBy: &user.ID,
+		ExpiresAt: time.Now().Add(a.orgInvitationTTL).UTC(),
+	}
+
+	// invitees having an account get the email in their language
+	locale := user.Locale
+	if invitee, err := a.usrProvider.User(ctx, invitation.Email); err == nil {
+		locale = invitee.Locale
+	}
+
+	rendered, err := emails.Render(emails.OrgInvitation, locale, emails.OrgInvitationData{
+		OrgName:     inviter.OrgName,
+		InviterName: strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Role:        role,
+		Token:       token,
+		ExpiresAt:   invitation.ExpiresAt,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invitationID, err = a.orgProvider.SaveOrgInvitation(ctx, invitation, models.OutboxEmail{
+		Recipient: invitation.Email,
+		Subject:   rendered.Subject,
+		HTML:      rendered.HTML,
+		Text:      rendered.Text,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "organization invitation sent",
+		slog.String("op", op),
+		slog.Int64("org_id", orgID),
+		slog.Int64("invitation_id", invitationID),
+		slog.Int64("user_id", user.ID),
+	)
+
+	return invitationID, nil
+}
+
+// AcceptOrgInvitation makes the user of the request token a member of the
+// organization the invitation token was emailed for. The invitation must be
+// for the confirmed email of the user.
+func (a *Auth) AcceptOrgInvitation(ctx context.Context, appID int64, token string) (models.OrgMember, error) {
+	const op = "auth.AcceptOrgInvitation"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invitation, err := a.orgProvider.OrgInvitation(ctx, hashOrgInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, storage.ErrOrgInvitationNotFound) {
+			return models.OrgMember{}, fmt.Errorf("%s: %w", op, ErrInvalidOrgInvitation)
+		}
+
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if time.Now().After(invitation.ExpiresAt) {
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, ErrInvalidOrgInvitation)
+	}
+
+	if !strings.EqualFold(invitation.Email, user.Email) || !user.IsEmailConfirmed {
+		a.log.WarnContext(ctx, "organization invitation presented by another user",
+			slog.String("op", op),
+			slog.Int64("invitation_id", invitation.ID),
+			slog.Int64("user_id", user.ID),
+		)
+
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, ErrOrgInvitationForbidden)
+	}
+
+	if err = a.orgProvider.AcceptOrgInvitation(ctx, invitation.ID, user.ID); err != nil {
+		if errors.Is(err, storage.ErrOrgInvitationNotFound) {
+			return models.OrgMember{}, fmt.Errorf("%s: %w", op, ErrInvalidOrgInvitation)
+		}
+
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	member, err := a.orgProvider.OrgMember(ctx, invitation.OrgID, user.ID)
+	if err != nil {
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "organization invitation accepted",
+		slog.String("op", op),
+		slog.Int64("org_id", member.OrgID),
+		slog.Int64("user_id", user.ID),
+	)
+
+	return member, nil
+}
+
+// RemoveOrgMember removes a user from the organization. Members may leave,
+// owners and admins remove others, only owners remove owners.
+func (a *Auth) RemoveOrgMember(ctx context.Context, appID, orgID, userID int64) error {
+	const op = "auth.RemoveOrgMember"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if userID != user.ID {
+		manager, err := a.orgManager(ctx, orgID, user.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		member, err := a.orgProvider.OrgMember(ctx, orgID, userID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if member.Role == models.OrgRoleOwner && manager.Role != models.OrgRoleOwner {
+			return fmt.Errorf("%s: %w", op, ErrPermissionDenied)
+		}
+	}
+
+	if err = a.orgProvider.RemoveOrgMember(ctx, orgID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "organization member removed",
+		slog.String("op", op),
+		slog.Int64("org_id", orgID),
+		slog.Int64("member_id", userID),
+		slog.Int64("user_id", user.ID),
+	)
+
+	return nil
+}
+
+// OrgMemberships returns the organizations of the user of the request token.
+func (a *Auth) OrgMemberships(ctx context.Context, appID int64) ([]models.OrgMember, error) {
+	const op = "auth.OrgMemberships"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	memberships, err := a.orgProvider.UserOrgMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return memberships, nil
+}
+
+// OrgMembers returns the members of the organization, visible to its members only.
+func (a *Auth) OrgMembers(ctx context.Context, appID, orgID int64) ([]models.OrgMember, error) {
+	const op = "auth.OrgMembers"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	user, err := a.requestUser(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = a.orgMember(ctx, orgID, user.ID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	members, err := a.orgProvider.OrgMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// SwitchOrganization starts a new session of the user of the request token
+// whose tokens carry the org_id and org_role claims of the organization.
+// orgID 0 starts a session without an organization. The authentication
+// methods of the current session are kept.
+func (a *Auth) SwitchOrganization(ctx context.Context, appID, orgID int64) (accessToken, refreshToken string, err error) {
+	const op = "auth.SwitchOrganization"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	p, err := requestPrincipal(ctx, appID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	if p.UserID == 0 {
+		return "", "", fmt.Errorf("%s: %w: missing uid claim", op, ErrInvalidToken)
+	}
+
+	user, err := a.usrProvider.UserAllData(ctx, p.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var member *models.OrgMember
+	if orgID != 0 {
+		m, err := a.orgMember(ctx, orgID, user.ID)
+		if err != nil {
+			return "", "", fmt.Errorf("%s: %w", op, err)
+		}
+		member = &m
+	}
+
+	accessToken, refreshToken, err = a.issueTokens(ctx, user, app, p.AuthMethods, member)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// PurgeExpiredOrgInvitations deletes invitations that can no longer be accepted.
+func (a *Auth) PurgeExpiredOrgInvitations(ctx context.Context) error {
+	const op = "auth.PurgeExpiredOrgInvitations"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := a.orgProvider.DeleteExpiredOrgInvitations(ctx, time.Now()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// orgMember returns the membership of the user, ErrPermissionDenied if the
+// user is not a member.
+func (a *Auth) orgMember(ctx context.Context, orgID, userID int64) (models.OrgMember, error) {
+	member, err := a.orgProvider.OrgMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrOrgMemberNotFound) {
+			return models.OrgMember{}, ErrPermissionDenied
+		}
+
+		return models.OrgMember{}, err
+	}
+
+	return member, nil
+}
+
+// orgManager returns the membership of the user if it is an owner or an admin
+// of the organization.
+func (a *Auth) orgManager(ctx context.Context, orgID, userID int64) (models.OrgMember, error) {
+	member, err := a.orgMember(ctx, orgID, userID)
+	if err != nil {
+		return models.OrgMember{}, err
+	}
+
+	if member.Role != models.OrgRoleOwner && member.Role != models.OrgRoleAdmin {
+		return models.OrgMember{}, ErrPermissionDenied
+	}
+
+	return member, nil
+}
+
+func validOrgRole(role string) bool {
+	switch role {
+	case models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleMember:
+		return true
+	}
+
+	return false
+}
+
+// hashOrgInvitationToken returns the form invitation tokens are stored in.
+func hashOrgInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+func TestSwitchOrganization(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	a := newTestAuth(s)
+
+	app, err := s.SaveApp(ctx, models.App{Name: "app", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID := saveTestUser(t, s, "user@example.com")
+	org, err := s.SaveOrganization(ctx, "Org", "org", userID)
+	if err != nil {
+		t.Fatalf("save organization: %v", err)
+	}
+
+	amr := []string{AuthMethodPassword, AuthMethodMFA}
+	accessToken, _, err := a.issueTokens(ctx, models.User{ID: userID, Email: "user@example.com"}, app, amr, nil)
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+
+	t.Run("bearer token without principal", func(t *testing.T) {
+		// only the auth interceptor verifies tokens
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+accessToken))
+
+		if _, _, err := a.SwitchOrganization(ctx, app.ID, org.ID); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("SwitchOrganization() error = %v, want %v", err, ErrInvalidToken)
+		}
+	})
+
+	t.Run("principal", func(t *testing.T) {
+		ctx := jwtn.ContextWithPrincipal(ctx, models.Principal{UserID: userID, AppID: app.ID, AuthMethods: amr})
+
+		token, _, err := a.SwitchOrganization(ctx, app.ID, org.ID)
+		if err != nil {
+			t.Fatalf("SwitchOrganization() error = %v", err)
+		}
+
+		claims, err := a.VerifyAccessToken(ctx, token)
+		if err != nil {
+			t.Fatalf("VerifyAccessToken() error = %v", err)
+		}
+		if orgID, _ := jwtn.OrgID(claims); orgID != org.ID {
+			t.Fatalf("org_id = %d, want %d", orgID, org.ID)
+		}
+		if got := jwtn.AuthMethods(claims); !slices.Equal(got, amr) {
+			t.Fatalf("amr = %v, want %v", got, amr)
+		}
+	})
+}
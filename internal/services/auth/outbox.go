@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+type OutboxProvider interface {
+	OutboxEmails(ctx context.Context, status string, limit int) ([]models.OutboxEmail, error)
+	RetryOutboxEmail(ctx context.Context, id int64) error
+}
+
+const (
+	defaultOutboxLimit = 100
+	maxOutboxLimit     = 1000
+)
+
+// OutboxEmails lists the latest queued emails with their delivery state,
+// of any status if status is empty. Only admins can list emails.
+func (a *Auth) OutboxEmails(ctx context.Context, appID int64, status string, limit int) ([]models.OutboxEmail, error) {
+	const op = "auth.OutboxEmails"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if _, err := a.authorizeAdmin(ctx, appID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if limit <= 0 {
+		limit = defaultOutboxLimit
+	}
+	limit = min(limit, maxOutboxLimit)
+
+	emails, err := a.outboxProvider.OutboxEmails(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return emails, nil
+}
+
+// RetryOutboxEmail queues an email that was not delivered for immediate
+// delivery. Only admins can retry emails.
+func (a *Auth) RetryOutboxEmail(ctx context.Context, appID, emailID int64) error {
+	const op = "auth.RetryOutboxEmail"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "retrying email delivery",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.Int64("email_id", emailID),
+	)
+
+	if err = a.outboxProvider.RetryOutboxEmail(ctx, emailID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+func TestRetryOutboxEmail(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	a := newTestAuth(s)
+
+	userID := saveTestUser(t, s, "user@example.com")
+	adminID := saveTestUser(t, s, "admin@example.com")
+
+	emails, err := s.OutboxEmails(ctx, models.OutboxPending, 10)
+	if err != nil || len(emails) != 2 {
+		t.Fatalf("outbox emails = %v, %v, want two", emails, err)
+	}
+	email := emails[0]
+
+	// the delivery of the email gave up
+	if err = s.MarkOutboxEmailFailed(ctx, email.ID, "mailer is down", time.Now(), true); err != nil {
+		t.Fatalf("mark email failed: %v", err)
+	}
+
+	user := models.Principal{UserID: userID, AppID: 1}
+	admin := models.Principal{UserID: adminID, AppID: 1, IsAdmin: true, AuthMethods: []string{AuthMethodPassword, AuthMethodMFA}}
+
+	tests := []struct {
+		name      string
+		principal models.Principal
+		emailID   int64
+		wantErr   error
+	}{
+		{"not an admin", user, email.ID, ErrPermissionDenied},
+		{"admin without MFA", models.Principal{UserID: adminID, AppID: 1, IsAdmin: true}, email.ID, ErrMFARequired},
+		{"unknown email", admin, email.ID + 100, storage.ErrOutboxEmailNotFound},
+		{"admin", admin, email.ID, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := jwtn.ContextWithPrincipal(ctx, tt.principal)
+
+			if err := a.RetryOutboxEmail(ctx, 1, tt.emailID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RetryOutboxEmail() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	dead, err := a.OutboxEmails(jwtn.ContextWithPrincipal(ctx, admin), 1, models.OutboxDead, 0)
+	if err != nil || len(dead) != 0 {
+		t.Fatalf("OutboxEmails() dead = %v, %v, want none", dead, err)
+	}
+	if _, err = a.OutboxEmails(jwtn.ContextWithPrincipal(ctx, user), 1, "", 0); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("OutboxEmails() as user error = %v, want %v", err, ErrPermissionDenied)
+	}
+}
@@ -0,0 +1,340 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+)
+
+// RBACProvider stores roles and permissions, scoped per app.
+type RBACProvider interface {
+	SaveRole(ctx context.Context, appID int64, name, description string) (models.Role, error)
+	DeleteRole(ctx context.Context, appID int64, name string) error
+	Roles(ctx context.Context, appID int64) ([]models.Role, error)
+	SavePermission(ctx context.Context, appID int64, name, description string) (models.Permission, error)
+	DeletePermission(ctx context.Context, appID int64, name string) error
+	Permissions(ctx context.Context, appID int64) ([]models.Permission, error)
+	GrantPermission(ctx context.Context, appID int64, role, permission string) error
+	RevokePermission(ctx context.Context, appID int64, role, permission string) error
+	AssignRole(ctx context.Context, userID, appID int64, role string) error
+	UnassignRole(ctx context.Context, userID, appID int64, role string) error
+	UserRoles(ctx context.Context, userID, appID int64) ([]string, error)
+	UserPermissions(ctx context.Context, userID, appID int64) ([]string, error)
+}
+
+// CheckPermission reports whether any role of the user in the app grants the
+// permission. Only admins may check the permissions of other users.
+func (a *Auth) CheckPermission(ctx context.Context, userID, appID int64, permission string) (bool, error) {
+	const op = "auth.CheckPermission"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	// users check their own permissions, admins those of anyone in the app
+	callerID, err := a.requestUserID(ctx, appID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if callerID != userID {
+		if _, err = a.authorizeAdmin(ctx, appID); err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	granted, err := a.rbacProvider.UserPermissions(ctx, userID, appID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return permissionGranted(granted, permission), nil
+}
+
+// permissionGranted reports whether permission is one of granted, or matches
+// a granted permission ending with *, e.g. orders:* grants orders:read.
+func permissionGranted(granted []string, permission string) bool {
+	for _, g := range granted {
+		if g == permission {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(g, "*"); ok && strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rbacClaims returns the token options embedding the roles and permissions
+// of the user in the app. Nothing is added for users without roles.
+func (a *Auth) rbacClaims(ctx context.Context, userID, appID int64) ([]jwtn.Option, error) {
+	roles, err := a.rbacProvider.UserRoles(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, nil
+	}
+
+	permissions, err := a.rbacProvider.UserPermissions(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jwtn.Option{jwtn.WithRoles(roles), jwtn.WithPermissions(permissions)}, nil
+}
+
+// CreateRole creates a role in the app. Only admins can manage roles.
+func (a *Auth) CreateRole(ctx context.Context, appID int64, name, description string) (models.Role, error) {
+	const op = "auth.CreateRole"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return models.Role{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "creating role", slog.String("op", op), slog.Int64("admin_id", adminID), slog.String("role", name))
+
+	role, err := a.rbacProvider.SaveRole(ctx, appID, name, description)
+	if err != nil {
+		return models.Role{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes a role of the app, unassigning it from all users.
+func (a *Auth) DeleteRole(ctx context.Context, appID int64, name string) error {
+	const op = "auth.DeleteRole"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "deleting role", slog.String("op", op), slog.Int64("admin_id", adminID), slog.String("role", name))
+
+	if err = a.rbacProvider.DeleteRole(ctx, appID, name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Roles lists the roles of the app with the permissions they grant.
+func (a *Auth) Roles(ctx context.Context, appID int64) ([]models.Role, error) {
+	const op = "auth.Roles"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if _, err := a.authorizeAdmin(ctx, appID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	roles, err := a.rbacProvider.Roles(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+// CreatePermission creates a permission in the app. Only admins can manage permissions.
+func (a *Auth) CreatePermission(ctx context.Context, appID int64, name, description string) (models.Permission, error) {
+	const op = "auth.CreatePermission"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return models.Permission{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "creating permission", slog.String("op", op), slog.Int64("admin_id", adminID), slog.String("permission", name))
+
+	perm, err := a.rbacProvider.SavePermission(ctx, appID, name, description)
+	if err != nil {
+		return models.Permission{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return perm, nil
+}
+
+// DeletePermission deletes a permission of the app, revoking it from all roles.
+func (a *Auth) DeletePermission(ctx context.Context, appID int64, name string) error {
+	const op = "auth.DeletePermission"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "deleting permission", slog.String("op", op), slog.Int64("admin_id", adminID), slog.String("permission", name))
+
+	if err = a.rbacProvider.DeletePermission(ctx, appID, name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Permissions lists the permissions of the app.
+func (a *Auth) Permissions(ctx context.Context, appID int64) ([]models.Permission, error) {
+	const op = "auth.Permissions"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if _, err := a.authorizeAdmin(ctx, appID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	perms, err := a.rbacProvider.Permissions(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return perms, nil
+}
+
+// GrantPermission makes the role grant the permission.
+func (a *Auth) GrantPermission(ctx context.Context, appID int64, role, permission string) error {
+	const op = "auth.GrantPermission"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "granting permission",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.String("role", role),
+		slog.String("permission", permission),
+	)
+
+	if err = a.rbacProvider.GrantPermission(ctx, appID, role, permission); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokePermission makes the role no longer grant the permission. Tokens
+// issued before keep their permissions claim until they expire.
+func (a *Auth) RevokePermission(ctx context.Context, appID int64, role, permission string) error {
+	const op = "auth.RevokePermission"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "revoking permission",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.String("role", role),
+		slog.String("permission", permission),
+	)
+
+	if err = a.rbacProvider.RevokePermission(ctx, appID, role, permission); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AssignRole assigns a role of the app to the user.
+func (a *Auth) AssignRole(ctx context.Context, appID, userID int64, role string) error {
+	const op = "auth.AssignRole"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "assigning role",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.Int64("user_id", userID),
+		slog.String("role", role),
+	)
+
+	if err = a.rbacProvider.AssignRole(ctx, userID, appID, role); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UnassignRole takes a role of the app away from the user. Tokens issued
+// before keep their roles claim until they expire.
+func (a *Auth) UnassignRole(ctx context.Context, appID, userID int64, role string) error {
+	const op = "auth.UnassignRole"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.InfoContext(ctx, "unassigning role",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.Int64("user_id", userID),
+		slog.String("role", role),
+	)
+
+	if err = a.rbacProvider.UnassignRole(ctx, userID, appID, role); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UserRoles returns the roles of the user in the app and the permissions they grant.
+func (a *Auth) UserRoles(ctx context.Context, appID, userID int64) (roles, permissions []string, err error) {
+	const op = "auth.UserRoles"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if _, err = a.authorizeAdmin(ctx, appID); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if roles, err = a.rbacProvider.UserRoles(ctx, userID, appID); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if permissions, err = a.rbacProvider.UserPermissions(ctx, userID, appID); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, permissions, nil
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+func TestCheckPermission(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	a := newTestAuth(s)
+
+	app, err := s.SaveApp(ctx, models.App{Name: "app", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID := saveTestUser(t, s, "user@example.com")
+	otherID := saveTestUser(t, s, "other@example.com")
+	adminID := saveTestUser(t, s, "admin@example.com")
+	if err = s.SetAdmin(ctx, adminID, true); err != nil {
+		t.Fatalf("set admin: %v", err)
+	}
+
+	if _, err = s.SaveRole(ctx, app.ID, "editor", ""); err != nil {
+		t.Fatalf("save role: %v", err)
+	}
+	if _, err = s.SavePermission(ctx, app.ID, "docs:write", ""); err != nil {
+		t.Fatalf("save permission: %v", err)
+	}
+	if err = s.GrantPermission(ctx, app.ID, "editor", "docs:write"); err != nil {
+		t.Fatalf("grant permission: %v", err)
+	}
+	if err = s.AssignRole(ctx, otherID, app.ID, "editor"); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+
+	user := models.Principal{UserID: userID, AppID: app.ID}
+	other := models.Principal{UserID: otherID, AppID: app.ID}
+	admin := models.Principal{UserID: adminID, AppID: app.ID, IsAdmin: true, AuthMethods: []string{AuthMethodPassword, AuthMethodMFA}}
+
+	tests := []struct {
+		name      string
+		principal models.Principal
+		userID    int64
+		want      bool
+		wantErr   error
+	}{
+		{"own permissions", other, otherID, true, nil},
+		{"own permissions, not granted", user, userID, false, nil},
+		{"other user", user, otherID, false, ErrPermissionDenied},
+		{"admin, other user", admin, otherID, true, nil},
+		{"admin without MFA", models.Principal{UserID: adminID, AppID: app.ID, IsAdmin: true}, otherID, false, ErrMFARequired},
+		{"token of another app", models.Principal{UserID: otherID, AppID: app.ID + 1}, otherID, false, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := jwtn.ContextWithPrincipal(ctx, tt.principal)
+
+			got, err := a.CheckPermission(ctx, tt.userID, app.ID, "docs:write")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckPermission() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("CheckPermission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAdminIgnoresAppRoles(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	a := newTestAuth(s)
+
+	app, err := s.SaveApp(ctx, models.App{Name: "app", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID := saveTestUser(t, s, "user@example.com")
+
+	// any app can create a role named admin
+	if _, err = s.SaveRole(ctx, app.ID, "admin", ""); err != nil {
+		t.Fatalf("save role: %v", err)
+	}
+	if err = s.AssignRole(ctx, userID, app.ID, "admin"); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+
+	isAdmin, err := a.IsAdmin(ctx, userID)
+	if err != nil {
+		t.Fatalf("IsAdmin() error = %v", err)
+	}
+	if isAdmin {
+		t.Fatal("IsAdmin() = true for the admin role of an app")
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+type RefreshTokenProvider interface {
+	SaveRefreshToken(ctx context.Context, token models.RefreshToken) error
+	RefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) error
+}
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+const (
+	refreshTokenBytes = 32
+	tokenFamilyBytes  = 16
+)
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token of the same family.
+//
+// Every refresh token can be used only once. If an already rotated token is
+// presented again, the whole family is revoked and ErrRefreshTokenReused is
+// returned, so both the attacker and the legitimate client have to log in again.
+func (a *Auth) RefreshToken(ctx context.Context, refreshToken string, appID int64) (accessToken, newRefreshToken string, err error) {
+	const op = "auth.RefreshToken"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	log := a.log.With(
+		slog.String("op: ", op),
+		slog.Int64("app_id", appID),
+	)
+
+	log.InfoContext(ctx, "refreshing token")
+
+	current, err := a.refreshTokenProvider.RefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrRefreshTokenNotFound) {
+			log.WarnContext(ctx, "refresh token not found", sl.Err(err))
+
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+		}
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if current.AppID != appID || current.RevokedAt != nil {
+		log.WarnContext(ctx, "refresh token is revoked or belongs to another app")
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+	}
+
+	if current.UsedAt != nil {
+		return "", "", a.revokeReusedFamily(ctx, log, op, current.FamilyID)
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		log.InfoContext(ctx, "refresh token is expired")
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+	}
+
+	user, err := a.usrProvider.UserAllData(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+		}
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DisabledAt != nil {
+		log.InfoContext(ctx, "refresh token of disabled user")
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	amr := strings.Fields(current.AuthMethods)
+	opts := []jwtn.Option{jwtn.WithSessionID(current.FamilyID), jwtn.WithAuthMethods(amr)}
+
+	if current.OrgID != nil {
+		// the role may have changed, or the user may have left the organization
+		member, err := a.orgProvider.OrgMember(ctx, *current.OrgID, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrOrgMemberNotFound) {
+				log.InfoContext(ctx, "user is no longer a member of the organization of the session")
+
+				return "", "", fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+			}
+
+			return "", "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		opts = append(opts, jwtn.WithOrg(member.OrgID, member.Role))
+	}
+
+	newRefreshToken, next, err := a.newRefreshToken(current.UserID, appID, current.FamilyID, amr, current.OrgID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = a.refreshTokenProvider.RotateRefreshToken(ctx, current.ID, next); err != nil {
+		if errors.Is(err, storage.ErrRefreshTokenNotActive) {
+			// lost a race against another request presenting the same token
+			return "", "", a.revokeReusedFamily(ctx, log, op, current.FamilyID)
+		}
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	accessToken, err = a.newAccessToken(ctx, user, app, opts...)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate token", sl.Err(err))
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "token refreshed")
+
+	return accessToken, newRefreshToken, nil
+}
+
+// PurgeExpiredRefreshTokens deletes refresh tokens that can no longer be
+// used. Presenting a deleted token again is no longer detected as reuse,
+// but it is expired anyway.
+func (a *Auth) PurgeExpiredRefreshTokens(ctx context.Context) error {
+	const op = "auth.PurgeExpiredRefreshTokens"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := a.refreshTokenProvider.DeleteExpiredRefreshTokens(ctx, time.Now()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *Auth) revokeReusedFamily(ctx context.Context, log *slog.Logger, op, familyID string) error {
+	log.WarnContext(ctx, "refresh token reuse detected, revoking token family", slog.String("family_id", familyID))
+
+	if err := a.refreshTokenProvider.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		log.ErrorContext(ctx, "failed to revoke token family", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, ErrRefreshTokenReused)
+}
+
+// issueRefreshToken creates and saves a new refresh token and returns it
+// together with its family ID. An empty familyID starts a new token family.
+// A non-nil orgID issues the session for the organization.
+func (a *Auth) issueRefreshToken(ctx context.Context, userID, appID int64, familyID string, amr []string, orgID *int64) (refreshToken, tokenFamilyID string, err error) {
+	refreshToken, token, err := a.newRefreshToken(userID, appID, familyID, amr, orgID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err = a.refreshTokenProvider.SaveRefreshToken(ctx, token); err != nil {
+		return "", "", err
+	}
+
+	return refreshToken, token.FamilyID, nil
+}
+
+func (a *Auth) newRefreshToken(userID, appID int64, familyID string, amr []string, orgID *int64) (string, models.RefreshToken, error) {
+	if familyID == "" {
+		var err error
+		if familyID, err = rnd.GenerateToken(tokenFamilyBytes); err != nil {
+			return "", models.RefreshToken{}, err
+		}
+	}
+
+	refreshToken, err := rnd.GenerateToken(refreshTokenBytes)
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+
+	return refreshToken, models.RefreshToken{
+		UserID:      userID,
+		AppID:       appID,
+		FamilyID:    familyID,
+		TokenHash:   hashRefreshToken(refreshToken),
+		AuthMethods: strings.Join(amr, " "),
+		OrgID:       orgID,
+		ExpiresAt:   time.Now().Add(a.refreshTokenTTL).UTC(),
+	}, nil
+}
+
+// hashRefreshToken returns the form refresh tokens are stored in,
+// so a database leak does not expose usable tokens.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
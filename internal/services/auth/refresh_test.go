@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+type refreshEnv struct {
+	auth    *Auth
+	storage *memory.Storage
+	app     models.App
+	user    models.User
+}
+
+func newRefreshEnv(t *testing.T) refreshEnv {
+	t.Helper()
+
+	s := memory.New()
+
+	app, err := s.SaveApp(context.Background(), models.App{Name: "app", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID := saveTestUser(t, s, "user@example.com")
+
+	return refreshEnv{
+		auth:    newTestAuth(s),
+		storage: s,
+		app:     app,
+		user:    models.User{ID: userID, Email: "user@example.com"},
+	}
+}
+
+func (e refreshEnv) login(t *testing.T) string {
+	t.Helper()
+
+	_, refreshToken, err := e.auth.issueTokens(context.Background(), e.user, e.app, []string{AuthMethodPassword}, nil)
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+
+	return refreshToken
+}
+
+func (e refreshEnv) refresh(token string) (string, error) {
+	_, next, err := e.auth.RefreshToken(context.Background(), token, e.app.ID)
+	return next, err
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	env := newRefreshEnv(t)
+	token := env.login(t)
+
+	for i := 0; i < 3; i++ {
+		next, err := env.refresh(token)
+		if err != nil {
+			t.Fatalf("RefreshToken() #%d error = %v", i+1, err)
+		}
+		if next == token {
+			t.Fatalf("RefreshToken() #%d returned the presented token", i+1)
+		}
+		token = next
+	}
+
+	if _, _, err := env.auth.RefreshToken(context.Background(), token, env.app.ID+1); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("RefreshToken() for another app error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if _, err := env.refresh("unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("RefreshToken() unknown token error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	env := newRefreshEnv(t)
+	stolen := env.login(t)
+
+	next, err := env.refresh(stolen)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+
+	if _, err = env.refresh(stolen); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("RefreshToken() reused error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	// the token of the legitimate client belongs to the revoked family
+	if _, err = env.refresh(next); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("RefreshToken() after reuse error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	// other sessions are not affected
+	if _, err = env.refresh(env.login(t)); err != nil {
+		t.Fatalf("RefreshToken() other session error = %v", err)
+	}
+}
+
+// racingStore rotates every token once behind the back of the caller,
+// like a concurrent request presenting the same token.
+type racingStore struct {
+	*memory.Storage
+	t *testing.T
+}
+
+func (s racingStore) RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error {
+	winner := next
+	winner.TokenHash = hashRefreshToken("winner")
+
+	if err := s.Storage.RotateRefreshToken(ctx, oldID, winner); err != nil {
+		s.t.Fatalf("concurrent rotation: %v", err)
+	}
+
+	return s.Storage.RotateRefreshToken(ctx, oldID, next)
+}
+
+func TestRefreshTokenConcurrentRotation(t *testing.T) {
+	env := newRefreshEnv(t)
+	token := env.login(t)
+
+	env.auth.refreshTokenProvider = racingStore{Storage: env.storage, t: t}
+
+	if _, err := env.refresh(token); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("RefreshToken() error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	winner, err := env.storage.RefreshToken(context.Background(), hashRefreshToken("winner"))
+	if err != nil {
+		t.Fatalf("winner token: %v", err)
+	}
+	if winner.RevokedAt == nil {
+		t.Fatalf("token of the concurrent request is not revoked")
+	}
+}
+
+func TestPurgeExpiredRefreshTokens(t *testing.T) {
+	env := newRefreshEnv(t)
+	ctx := context.Background()
+
+	live := env.login(t)
+
+	expired := models.RefreshToken{
+		UserID:    env.user.ID,
+		AppID:     env.app.ID,
+		FamilyID:  "expired",
+		TokenHash: hashRefreshToken("expired"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	if err := env.storage.SaveRefreshToken(ctx, expired); err != nil {
+		t.Fatalf("save refresh token: %v", err)
+	}
+
+	if err := env.auth.PurgeExpiredRefreshTokens(ctx); err != nil {
+		t.Fatalf("PurgeExpiredRefreshTokens() error = %v", err)
+	}
+
+	if _, err := env.storage.RefreshToken(ctx, hashRefreshToken("expired")); !errors.Is(err, storage.ErrRefreshTokenNotFound) {
+		t.Fatalf("expired token error = %v, want %v", err, storage.ErrRefreshTokenNotFound)
+	}
+	if _, err := env.refresh(live); err != nil {
+		t.Fatalf("RefreshToken() live error = %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+type KeyRotator interface {
+	Rotate(ctx context.Context, immediate bool) (models.SigningKey, error)
+	Keys(ctx context.Context) ([]models.SigningKey, error)
+}
+
+var ErrKeyRotationDisabled = errors.New("signing keys are static, rotation is disabled")
+
+// RotateSigningKeys publishes a new signing key, or activates it right away
+// if immediate is set. Only admins can rotate keys.
+func (a *Auth) RotateSigningKeys(ctx context.Context, appID int64, immediate bool) (models.SigningKey, error) {
+	const op = "auth.RotateSigningKeys"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	adminID, err := a.authorizeAdmin(ctx, appID)
+	if err != nil {
+		return models.SigningKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if a.keyRotator == nil {
+		return models.SigningKey{}, fmt.Errorf("%s: %w", op, ErrKeyRotationDisabled)
+	}
+
+	a.log.InfoContext(ctx, "rotating signing keys",
+		slog.String("op", op),
+		slog.Int64("admin_id", adminID),
+		slog.Bool("immediate", immediate),
+	)
+
+	key, err := a.keyRotator.Rotate(ctx, immediate)
+	if err != nil {
+		return models.SigningKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// SigningKeys lists signing keys with their rotation state. Only admins can list keys.
+func (a *Auth) SigningKeys(ctx context.Context, appID int64) ([]models.SigningKey, error) {
+	const op = "auth.SigningKeys"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if _, err := a.authorizeAdmin(ctx, appID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if a.keyRotator == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrKeyRotationDisabled)
+	}
+
+	keys, err := a.keyRotator.Keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// newAccessToken signs a new access token for user with the current signing key
+// of app. The roles and permissions of the user in app are embedded as claims.
+func (a *Auth) newAccessToken(ctx context.Context, user models.User, app models.App, opts ...jwtn.Option) (string, error) {
+	key, err := a.keys.SigningKey(ctx, app)
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	rbac, err := a.rbacClaims(ctx, user.ID, app.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	return jwtn.NewToken(key, user, app, a.accessTokenTTL(app), append(rbac, opts...)...)
+}
+
+// accessTokenTTL returns the lifetime of access tokens of app, which may
+// override the default.
+func (a *Auth) accessTokenTTL(app models.App) time.Duration {
+	if app.TokenTTLSeconds > 0 {
+		return time.Duration(app.TokenTTLSeconds) * time.Second
+	}
+
+	return a.tokenTTL
+}
+
+// IssueTokens starts a new session of user in app and returns its access
+// token and the first refresh token of the session. amr lists how the user
+// authenticated and is kept for the whole session.
+func (a *Auth) IssueTokens(ctx context.Context, user models.User, app models.App, amr []string, opts ...jwtn.Option) (accessToken, refreshToken string, err error) {
+	return a.issueTokens(ctx, user, app, amr, nil, opts...)
+}
+
+// issueTokens is IssueTokens, issuing the session for the organization of
+// member if it is not nil.
+func (a *Auth) issueTokens(ctx context.Context, user models.User, app models.App, amr []string, member *models.OrgMember, opts ...jwtn.Option) (accessToken, refreshToken string, err error) {
+	if user.DisabledAt != nil {
+		return "", "", ErrUserDisabled
+	}
+
+	var orgID *int64
+	if member != nil {
+		orgID = &member.OrgID
+		opts = append(opts, jwtn.WithOrg(member.OrgID, member.Role))
+	}
+
+	refreshToken, sessionID, err := a.issueRefreshToken(ctx, user.ID, app.ID, "", amr, orgID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+
+	opts = append(opts, jwtn.WithSessionID(sessionID), jwtn.WithAuthMethods(amr))
+
+	accessToken, err = a.newAccessToken(ctx, user, app, opts...)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// VerifyAccessToken validates an access token of any app and returns its claims.
+func (a *Auth) VerifyAccessToken(ctx context.Context, token string) (jwt.MapClaims, error) {
+	const op = "auth.VerifyAccessToken"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	appID, err := jwtn.UnverifiedAppID(token)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", op, ErrInvalidToken, err)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims, err := jwtn.ParseToken(ctx, token, app, a.keys, a.tokenRevoker)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", op, ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public keys tokens can be verified with.
+func (a *Auth) JWKS(ctx context.Context) (jwtn.JWKS, error) {
+	const op = "auth.JWKS"
+
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	keys, err := a.keys.PublicKeys(ctx)
+	if err != nil {
+		return jwtn.JWKS{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	jwks, err := jwtn.NewJWKS(keys)
+	if err != nil {
+		return jwtn.JWKS{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return jwks, nil
+}
@@ -0,0 +1,458 @@
+// Package authz implements relationship-based authorization in the style
+// of Zanzibar: relation tuples object#relation@subject are stored, and
+// namespaces configure how relations are computed from them.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+type TupleStore interface {
+	WriteRelationTuples(ctx context.Context, writes, deletes []models.RelationTuple) error
+	RelationTuples(ctx context.Context, namespace, objectID, relation string) ([]models.RelationTuple, error)
+	RelationObjectIDs(ctx context.Context, namespace string, limit int) ([]string, error)
+}
+
+var (
+	ErrInvalidTuple    = errors.New("invalid relation tuple")
+	ErrUnknownRelation = errors.New("unknown namespace or relation")
+	ErrMaxDepth        = errors.New("relation graph too deep")
+	ErrTooManyObjects  = errors.New("too many objects to list")
+)
+
+const (
+	// UserNamespace is the namespace of users, whose IDs are user IDs.
+	UserNamespace = "user"
+	// ReadScope lets client tokens query the relations of any subject.
+	ReadScope = "authz:read"
+)
+
+const (
+	// maxDepth bounds the recursion of a single request.
+	maxDepth = 32
+	// MaxListObjects is the most objects ListObjects returns.
+	MaxListObjects = 1000
+	// MaxListCandidates is the most objects ListObjects checks. Namespaces
+	// with more objects having tuples can only be queried with Check.
+	MaxListCandidates = 10000
+)
+
+type Service struct {
+	log    *slog.Logger
+	tuples TupleStore
+	config *Config
+}
+
+func New(log *slog.Logger, tuples TupleStore, config *Config) *Service {
+	return &Service{
+		log:    log,
+		tuples: tuples,
+		config: config,
+	}
+}
+
+// Object is an object of a namespace, written namespace:id.
+type Object struct {
+	Namespace string
+	ID        string
+}
+
+func (o Object) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// ParseObject parses namespace:id.
+func ParseObject(s string) (Object, error) {
+	ns, id, ok := strings.Cut(s, ":")
+	if !ok || !nameRe.MatchString(ns) || id == "" || strings.ContainsAny(id, "#@") {
+		return Object{}, fmt.Errorf("%w: invalid object %q", ErrInvalidTuple, s)
+	}
+
+	return Object{Namespace: ns, ID: id}, nil
+}
+
+// Subject is an object, e.g. user:42, or a userset, e.g. group:eng#member,
+// the objects having the relation to the object.
+type Subject struct {
+	Object
+	Relation string
+}
+
+func (s Subject) String() string {
+	if s.Relation == "" {
+		return s.Object.String()
+	}
+
+	return s.Object.String() + "#" + s.Relation
+}
+
+// ParseSubject parses namespace:id or namespace:id#relation.
+func ParseSubject(s string) (Subject, error) {
+	obj, rel, _ := strings.Cut(s, "#")
+
+	o, err := ParseObject(obj)
+	if err != nil {
+		return Subject{}, err
+	}
+
+	if strings.Contains(s, "#") && !nameRe.MatchString(rel) {
+		return Subject{}, fmt.Errorf("%w: invalid subject %q", ErrInvalidTuple, s)
+	}
+
+	return Subject{Object: o, Relation: rel}, nil
+}
+
+// Tuple states that Subject has Relation to Object.
+type Tuple struct {
+	Object   Object
+	Relation string
+	Subject  Subject
+}
+
+func (t Tuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+func (t Tuple) model() models.RelationTuple {
+	return models.RelationTuple{
+		Namespace:        t.Object.Namespace,
+		ObjectID:         t.Object.ID,
+		Relation:         t.Relation,
+		SubjectNamespace: t.Subject.Namespace,
+		SubjectID:        t.Subject.ID,
+		SubjectRelation:  t.Subject.Relation,
+	}
+}
+
+func subjectOf(t models.RelationTuple) Subject {
+	return Subject{
+		Object:   Object{Namespace: t.SubjectNamespace, ID: t.SubjectID},
+		Relation: t.SubjectRelation,
+	}
+}
+
+// WriteTuples inserts and deletes tuples atomically. Tuples must use relations
+// declared in the namespace configuration.
+func (s *Service) WriteTuples(ctx context.Context, writes, deletes []Tuple) error {
+	const op = "authz.WriteTuples"
+
+	for _, t := range writes {
+		if err := s.validateTuple(t); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	toModels := func(tuples []Tuple) []models.RelationTuple {
+		res := make([]models.RelationTuple, 0, len(tuples))
+		for _, t := range tuples {
+			res = append(res, t.model())
+		}
+		return res
+	}
+
+	if err := s.tuples.WriteRelationTuples(ctx, toModels(writes), toModels(deletes)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.InfoContext(ctx, "relation tuples written",
+		slog.String("op", op),
+		slog.Int("writes", len(writes)),
+		slog.Int("deletes", len(deletes)),
+	)
+
+	return nil
+}
+
+func (s *Service) validateTuple(t Tuple) error {
+	if _, ok := s.config.rewrite(t.Object.Namespace, t.Relation); !ok {
+		return fmt.Errorf("%w: %s#%s", ErrUnknownRelation, t.Object.Namespace, t.Relation)
+	}
+
+	// plain subjects, e.g. users, need not be declared
+	if t.Subject.Relation != "" {
+		if _, ok := s.config.rewrite(t.Subject.Namespace, t.Subject.Relation); !ok {
+			return fmt.Errorf("%w: %s#%s", ErrUnknownRelation, t.Subject.Namespace, t.Subject.Relation)
+		}
+	}
+
+	return nil
+}
+
+// Check reports whether subject has the relation to the object, directly
+// or through the userset rewrites of the relation.
+func (s *Service) Check(ctx context.Context, object Object, relation string, subject Subject) (bool, error) {
+	const op = "authz.Check"
+
+	if _, ok := s.config.rewrite(object.Namespace, relation); !ok {
+		return false, fmt.Errorf("%s: %w: %s#%s", op, ErrUnknownRelation, object.Namespace, relation)
+	}
+
+	ok, err := s.check(ctx, object, relation, subject, visited{}, 0)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ok, nil
+}
+
+// visited holds the usersets checked for the subject of a request with
+// their results. Usersets still being checked are false, so a cycle in the
+// relation graph ends as not being a member.
+type visited map[Subject]bool
+
+func (s *Service) check(ctx context.Context, object Object, relation string, subject Subject, seen visited, depth int) (bool, error) {
+	if depth > maxDepth {
+		return false, ErrMaxDepth
+	}
+
+	// a userset contains itself
+	if subject.Object == object && subject.Relation == relation {
+		return true, nil
+	}
+
+	userset := Subject{Object: object, Relation: relation}
+	if ok, done := seen[userset]; done {
+		return ok, nil
+	}
+
+	rewrite, ok := s.config.rewrite(object.Namespace, relation)
+	if !ok {
+		// e.g. a tuple_to_userset pointing to a namespace without the relation
+		return false, nil
+	}
+
+	seen[userset] = false
+
+	ok, err := s.checkRewrite(ctx, object, relation, rewrite, subject, seen, depth)
+	if err != nil {
+		return false, err
+	}
+
+	seen[userset] = ok
+
+	return ok, nil
+}
+
+func (s *Service) checkRewrite(ctx context.Context, object Object, relation string, r Rewrite, subject Subject, seen visited, depth int) (bool, error) {
+	switch {
+	case r.ComputedUserset != "":
+		return s.check(ctx, object, r.ComputedUserset, subject, seen, depth+1)
+
+	case r.TupleToUserset != nil:
+		tuples, err := s.tuples.RelationTuples(ctx, object.Namespace, object.ID, r.TupleToUserset.Tupleset)
+		if err != nil {
+			return false, err
+		}
+		for _, t := range tuples {
+			ok, err := s.check(ctx, subjectOf(t).Object, r.TupleToUserset.ComputedUserset, subject, seen, depth+1)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case r.Union != nil:
+		for _, child := range r.Union {
+			ok, err := s.checkRewrite(ctx, object, relation, child, subject, seen, depth)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case r.Intersection != nil:
+		for _, child := range r.Intersection {
+			ok, err := s.checkRewrite(ctx, object, relation, child, subject, seen, depth)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case r.Exclusion != nil:
+		ok, err := s.checkRewrite(ctx, object, relation, r.Exclusion.Base, subject, seen, depth)
+		if err != nil || !ok {
+			return false, err
+		}
+		excluded, err := s.checkRewrite(ctx, object, relation, r.Exclusion.Subtract, subject, seen, depth)
+		if err != nil {
+			return false, err
+		}
+		return !excluded, nil
+	}
+
+	// this
+	tuples, err := s.tuples.RelationTuples(ctx, object.Namespace, object.ID, relation)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range tuples {
+		sub := subjectOf(t)
+		if sub == subject {
+			return true, nil
+		}
+	}
+
+	for _, t := range tuples {
+		sub := subjectOf(t)
+		if sub.Relation == "" {
+			continue
+		}
+
+		ok, err := s.check(ctx, sub.Object, sub.Relation, subject, seen, depth+1)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// ListObjects returns the IDs of the objects of the namespace the subject has
+// the relation to, at most MaxListObjects. Every object having tuples is
+// checked, objects without tuples can not have any relation. Namespaces with
+// more than MaxListCandidates such objects are rejected with ErrTooManyObjects.
+func (s *Service) ListObjects(ctx context.Context, namespace, relation string, subject Subject) ([]string, error) {
+	const op = "authz.ListObjects"
+
+	if _, ok := s.config.rewrite(namespace, relation); !ok {
+		return nil, fmt.Errorf("%s: %w: %s#%s", op, ErrUnknownRelation, namespace, relation)
+	}
+
+	ids, err := s.tuples.RelationObjectIDs(ctx, namespace, MaxListCandidates+1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(ids) > MaxListCandidates {
+		return nil, fmt.Errorf("%s: %w: %s has more than %d objects", op, ErrTooManyObjects, namespace, MaxListCandidates)
+	}
+
+	objects := []string{}
+	for _, id := range ids {
+		ok, err := s.check(ctx, Object{Namespace: namespace, ID: id}, relation, subject, visited{}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if !ok {
+			continue
+		}
+
+		objects = append(objects, id)
+		if len(objects) == MaxListObjects {
+			break
+		}
+	}
+
+	return objects, nil
+}
+
+const (
+	OpLeaf         = "leaf"
+	OpUnion        = "union"
+	OpIntersection = "intersection"
+	OpExclusion    = "exclusion"
+)
+
+// Node is a node of the userset tree returned by Expand. Leaves list the
+// subjects of tuples, usersets among them are not expanded further.
+type Node struct {
+	Operation string
+	Userset   string
+	Subjects  []string
+	Children  []*Node
+}
+
+// Expand returns the userset tree of the relation of the object, following
+// its rewrites one level of objects deep.
+func (s *Service) Expand(ctx context.Context, object Object, relation string) (*Node, error) {
+	const op = "authz.Expand"
+
+	rewrite, ok := s.config.rewrite(object.Namespace, relation)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w: %s#%s", op, ErrUnknownRelation, object.Namespace, relation)
+	}
+
+	node, err := s.expand(ctx, object, relation, rewrite, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return node, nil
+}
+
+func (s *Service) expand(ctx context.Context, object Object, relation string, r Rewrite, depth int) (*Node, error) {
+	if depth > maxDepth {
+		return nil, ErrMaxDepth
+	}
+
+	userset := Subject{Object: object, Relation: relation}.String()
+
+	children := func(op string, rewrites ...Rewrite) (*Node, error) {
+		node := &Node{Operation: op, Userset: userset}
+		for _, child := range rewrites {
+			n, err := s.expand(ctx, object, relation, child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, n)
+		}
+		return node, nil
+	}
+
+	switch {
+	case r.ComputedUserset != "":
+		computed, ok := s.config.rewrite(object.Namespace, r.ComputedUserset)
+		if !ok {
+			return &Node{Operation: OpLeaf, Userset: Subject{Object: object, Relation: r.ComputedUserset}.String()}, nil
+		}
+		return s.expand(ctx, object, r.ComputedUserset, computed, depth+1)
+
+	case r.TupleToUserset != nil:
+		tuples, err := s.tuples.RelationTuples(ctx, object.Namespace, object.ID, r.TupleToUserset.Tupleset)
+		if err != nil {
+			return nil, err
+		}
+		node := &Node{Operation: OpUnion, Userset: userset}
+		for _, t := range tuples {
+			sub := subjectOf(t).Object
+			rewrite, ok := s.config.rewrite(sub.Namespace, r.TupleToUserset.ComputedUserset)
+			if !ok {
+				continue
+			}
+			n, err := s.expand(ctx, sub, r.TupleToUserset.ComputedUserset, rewrite, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, n)
+		}
+		return node, nil
+
+	case r.Union != nil:
+		return children(OpUnion, r.Union...)
+
+	case r.Intersection != nil:
+		return children(OpIntersection, r.Intersection...)
+
+	case r.Exclusion != nil:
+		return children(OpExclusion, r.Exclusion.Base, r.Exclusion.Subtract)
+	}
+
+	tuples, err := s.tuples.RelationTuples(ctx, object.Namespace, object.ID, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Node{Operation: OpLeaf, Userset: userset, Subjects: []string{}}
+	for _, t := range tuples {
+		node.Subjects = append(node.Subjects, subjectOf(t).String())
+	}
+
+	return node, nil
+}
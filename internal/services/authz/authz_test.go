@@ -0,0 +1,113 @@
+package authz
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+const testConfig = `
+namespaces:
+  group:
+    relations:
+      member: {}
+  folder:
+    relations:
+      parent: {}
+      viewer:
+        union:
+          - this: true
+          - tuple_to_userset: {tupleset: parent, computed_userset: viewer}
+`
+
+func newTestService(t *testing.T, tuples ...string) *Service {
+	t.Helper()
+
+	config, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+
+	s := New(slog.New(slog.NewTextHandler(io.Discard, nil)), memory.New(), config)
+
+	writes := make([]Tuple, 0, len(tuples))
+	for _, str := range tuples {
+		writes = append(writes, parseTuple(t, str))
+	}
+	if err = s.WriteTuples(context.Background(), writes, nil); err != nil {
+		t.Fatalf("write tuples: %v", err)
+	}
+
+	return s
+}
+
+// parseTuple parses object#relation@subject.
+func parseTuple(t *testing.T, str string) Tuple {
+	t.Helper()
+
+	object, subject, _ := strings.Cut(str, "@")
+
+	userset, err := ParseSubject(object)
+	if err != nil {
+		t.Fatalf("parse tuple %q: %v", str, err)
+	}
+	sub, err := ParseSubject(subject)
+	if err != nil {
+		t.Fatalf("parse tuple %q: %v", str, err)
+	}
+
+	return Tuple{Object: userset.Object, Relation: userset.Relation, Subject: sub}
+}
+
+func TestCheckCycle(t *testing.T) {
+	s := newTestService(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+		"group:b#member@user:1",
+	)
+
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"user:1", true},
+		{"user:2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			subject, _ := ParseSubject(tt.subject)
+
+			got, err := s.Check(context.Background(), Object{Namespace: "group", ID: "a"}, "member", subject)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	s := newTestService(t,
+		"folder:root#viewer@user:1",
+		"folder:docs#parent@folder:root",
+		"folder:tmp#parent@folder:tmp",
+		"folder:other#viewer@user:2",
+	)
+
+	got, err := s.ListObjects(context.Background(), "folder", "viewer", Subject{Object: Object{Namespace: "user", ID: "1"}})
+	if err != nil {
+		t.Fatalf("ListObjects() error = %v", err)
+	}
+
+	if want := []string{"docs", "root"}; !slices.Equal(got, want) {
+		t.Fatalf("ListObjects() = %v, want %v", got, want)
+	}
+}
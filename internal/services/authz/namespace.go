@@ -0,0 +1,182 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config declares the namespaces, i.e. the object types, and how their
+// relations are computed. It is read from YAML:
+//
+//	namespaces:
+//	  document:
+//	    relations:
+//	      parent: {}
+//	      owner: {}
+//	      editor:
+//	        union:
+//	          - this: true
+//	          - computed_userset: owner
+//	      viewer:
+//	        union:
+//	          - this: true
+//	          - computed_userset: editor
+//	          - tuple_to_userset: {tupleset: parent, computed_userset: viewer}
+//
+// A relation without a rewrite consists of its tuples only.
+type Config struct {
+	Namespaces map[string]Namespace `yaml:"namespaces"`
+}
+
+type Namespace struct {
+	Relations map[string]Rewrite `yaml:"relations"`
+}
+
+// Rewrite is a userset rewrite rule. Exactly one of the fields is set,
+// none means This.
+type Rewrite struct {
+	// This are the subjects of the tuples of the relation.
+	This bool `yaml:"this"`
+	// ComputedUserset is the userset of another relation of the same object.
+	ComputedUserset string `yaml:"computed_userset"`
+	// TupleToUserset follows the tuples of a relation to other objects.
+	TupleToUserset *TupleToUserset `yaml:"tuple_to_userset"`
+
+	Union        []Rewrite  `yaml:"union"`
+	Intersection []Rewrite  `yaml:"intersection"`
+	Exclusion    *Exclusion `yaml:"exclusion"`
+}
+
+// TupleToUserset is the userset of ComputedUserset of the objects related
+// to the object by Tupleset, e.g. viewers of the parent folder.
+type TupleToUserset struct {
+	Tupleset        string `yaml:"tupleset"`
+	ComputedUserset string `yaml:"computed_userset"`
+}
+
+// Exclusion are the subjects of Base not in Subtract.
+type Exclusion struct {
+	Base     Rewrite `yaml:"base"`
+	Subtract Rewrite `yaml:"subtract"`
+}
+
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// LoadConfig reads the namespace configuration from a YAML file.
+// An empty path means no namespaces.
+func LoadConfig(path string) (*Config, error) {
+	const op = "authz.LoadConfig"
+
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cfg, nil
+}
+
+// ParseConfig parses and validates the YAML namespace configuration.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	for name, ns := range c.Namespaces {
+		if !nameRe.MatchString(name) {
+			return fmt.Errorf("invalid namespace name %q", name)
+		}
+
+		for rel, rewrite := range ns.Relations {
+			if !nameRe.MatchString(rel) {
+				return fmt.Errorf("%s: invalid relation name %q", name, rel)
+			}
+			if err := rewrite.validate(ns); err != nil {
+				return fmt.Errorf("%s#%s: %w", name, rel, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r Rewrite) validate(ns Namespace) error {
+	set := 0
+	for _, ok := range []bool{r.This, r.ComputedUserset != "", r.TupleToUserset != nil, r.Union != nil, r.Intersection != nil, r.Exclusion != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return errors.New("rewrite must have a single operation")
+	}
+
+	switch {
+	case r.ComputedUserset != "":
+		if _, ok := ns.Relations[r.ComputedUserset]; !ok {
+			return fmt.Errorf("unknown relation %q", r.ComputedUserset)
+		}
+	case r.TupleToUserset != nil:
+		// the computed userset is a relation of the related objects, which
+		// may be of any namespace, so only the tupleset can be checked
+		if _, ok := ns.Relations[r.TupleToUserset.Tupleset]; !ok {
+			return fmt.Errorf("unknown tupleset relation %q", r.TupleToUserset.Tupleset)
+		}
+		if !nameRe.MatchString(r.TupleToUserset.ComputedUserset) {
+			return fmt.Errorf("invalid computed userset %q", r.TupleToUserset.ComputedUserset)
+		}
+	case r.Union != nil || r.Intersection != nil:
+		children := slices.Concat(r.Union, r.Intersection)
+		if len(children) == 0 {
+			return errors.New("empty set operation")
+		}
+		for _, child := range children {
+			if err := child.validate(ns); err != nil {
+				return err
+			}
+		}
+	case r.Exclusion != nil:
+		if err := r.Exclusion.Base.validate(ns); err != nil {
+			return err
+		}
+		if err := r.Exclusion.Subtract.validate(ns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewrite returns the rewrite rule of the relation of the namespace.
+func (c *Config) rewrite(namespace, relation string) (Rewrite, bool) {
+	ns, ok := c.Namespaces[namespace]
+	if !ok {
+		return Rewrite{}, false
+	}
+
+	r, ok := ns.Relations[relation]
+
+	return r, ok
+}
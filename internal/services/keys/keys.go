@@ -0,0 +1,336 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// Manager keeps signing keys in storage and rotates them on schedule.
+//
+// It implements jwtn.KeyProvider. Keys are cached in memory and reloaded on
+// every Tick and whenever a token carries an unknown kid, so keys rotated by
+// another instance are picked up quickly.
+type Manager struct {
+	log     *slog.Logger
+	storage KeyStorage
+	alg     string
+
+	rotationInterval time.Duration
+	publishDelay     time.Duration
+	retireAfter      time.Duration
+	legacyUntil      time.Time
+
+	mu           sync.RWMutex
+	signing      *jwtn.Key
+	verification map[string]jwtn.Key
+	lastReload   time.Time
+}
+
+type KeyStorage interface {
+	SaveSigningKey(ctx context.Context, key models.SigningKey) error
+	SigningKeys(ctx context.Context) ([]models.SigningKey, error)
+	ActivateSigningKey(ctx context.Context, kid string) error
+	RetireSigningKeys(ctx context.Context, rotatedBefore time.Time) (int64, error)
+}
+
+var ErrNoSigningKey = errors.New("no active signing key")
+
+// minReloadInterval limits reloads triggered by unknown kids,
+// so forged tokens can not be used to hammer the storage.
+const minReloadInterval = 10 * time.Second
+
+// New returns a new key manager.
+//
+// A new key is published rotationInterval after the current one was
+// activated and starts signing publishDelay later. A rotated key is
+// retired once tokens signed with it expired, i.e. after retireAfter.
+// Tokens signed with app secrets before the switch from HS256 are
+// accepted until legacyUntil.
+func New(
+	log *slog.Logger,
+	keyStorage KeyStorage,
+	alg string,
+	rotationInterval time.Duration,
+	publishDelay time.Duration,
+	retireAfter time.Duration,
+	legacyUntil time.Time,
+) *Manager {
+	return &Manager{
+		log:              log,
+		storage:          keyStorage,
+		alg:              alg,
+		rotationInterval: rotationInterval,
+		publishDelay:     publishDelay,
+		retireAfter:      retireAfter,
+		legacyUntil:      legacyUntil,
+		verification:     map[string]jwtn.Key{},
+	}
+}
+
+func (m *Manager) SigningKey(ctx context.Context, _ models.App) (jwtn.Key, error) {
+	m.mu.RLock()
+	signing := m.signing
+	m.mu.RUnlock()
+
+	if signing == nil {
+		if err := m.reload(ctx); err != nil {
+			return jwtn.Key{}, err
+		}
+
+		m.mu.RLock()
+		signing = m.signing
+		m.mu.RUnlock()
+
+		if signing == nil {
+			return jwtn.Key{}, ErrNoSigningKey
+		}
+	}
+
+	return *signing, nil
+}
+
+func (m *Manager) VerificationKey(ctx context.Context, app models.App, kid string) (jwtn.Key, error) {
+	if kid == "" {
+		// tokens signed before the migration to key rotation, apps created
+		// since have no plain secret to verify them with
+		if app.Secret == "" || !jwtn.LegacyAccepted(m.legacyUntil) {
+			return jwtn.Key{}, jwtn.ErrUnknownKey
+		}
+		return jwtn.AppSecretKey(app), nil
+	}
+
+	m.mu.RLock()
+	key, ok := m.verification[kid]
+	stale := time.Since(m.lastReload) > minReloadInterval
+	m.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if stale {
+		if err := m.reload(ctx); err != nil {
+			return jwtn.Key{}, err
+		}
+
+		m.mu.RLock()
+		key, ok = m.verification[kid]
+		m.mu.RUnlock()
+
+		if ok {
+			return key, nil
+		}
+	}
+
+	return jwtn.Key{}, jwtn.ErrUnknownKey
+}
+
+func (m *Manager) PublicKeys(ctx context.Context) ([]jwtn.Key, error) {
+	m.mu.RLock()
+	keys := make([]jwtn.Key, 0, len(m.verification))
+	for _, k := range m.verification {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+
+	return keys, nil
+}
+
+// Keys returns all keys with their rotation state. Private keys are stripped.
+func (m *Manager) Keys(ctx context.Context) ([]models.SigningKey, error) {
+	const op = "keys.Keys"
+
+	keys, err := m.storage.SigningKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := range keys {
+		keys[i].PrivateKey = nil
+	}
+
+	return keys, nil
+}
+
+// Rotate publishes a new pending key, or reuses one already pending.
+// With immediate set the key starts signing right away, which is meant
+// for a compromised signing key.
+func (m *Manager) Rotate(ctx context.Context, immediate bool) (models.SigningKey, error) {
+	const op = "keys.Rotate"
+
+	log := m.log.With(slog.String("op", op), slog.Bool("immediate", immediate))
+
+	pending, err := m.pendingKey(ctx)
+	if err != nil {
+		return models.SigningKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if immediate {
+		if err = m.storage.ActivateSigningKey(ctx, pending.KID); err != nil {
+			return models.SigningKey{}, fmt.Errorf("%s: %w", op, err)
+		}
+		pending.State = models.SigningKeyActive
+	}
+
+	if err = m.reload(ctx); err != nil {
+		return models.SigningKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "signing keys rotated", slog.String("kid", pending.KID))
+
+	pending.PrivateKey = nil
+
+	return pending, nil
+}
+
+// Tick advances scheduled rotation: it creates the first key, publishes
+// the next one in time, activates it after publishDelay and retires keys
+// whose tokens have expired.
+func (m *Manager) Tick(ctx context.Context) error {
+	const op = "keys.Tick"
+
+	log := m.log.With(slog.String("op", op))
+
+	keys, err := m.storage.SigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var current, pending *models.SigningKey
+	for i := range keys {
+		switch {
+		case keys[i].State == models.SigningKeyPending:
+			pending = &keys[i]
+		case keys[i].State == models.SigningKeyActive && keys[i].RotatedAt == nil && current == nil:
+			current = &keys[i]
+		}
+	}
+
+	now := time.Now()
+
+	switch {
+	case current == nil:
+		// nothing signs yet, there is no one to publish the key to in advance
+		log.InfoContext(ctx, "no active signing key, activating new one")
+
+		if _, err = m.Rotate(ctx, true); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	case pending == nil && current.ActivatedAt != nil && now.Sub(*current.ActivatedAt) >= m.rotationInterval:
+		log.InfoContext(ctx, "publishing next signing key")
+
+		if _, err = m.Rotate(ctx, false); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	case pending != nil && now.Sub(pending.CreatedAt) >= m.publishDelay:
+		log.InfoContext(ctx, "activating published signing key", slog.String("kid", pending.KID))
+
+		if err = m.storage.ActivateSigningKey(ctx, pending.KID); err != nil && !errors.Is(err, storage.ErrSigningKeyNotFound) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	retired, err := m.storage.RetireSigningKeys(ctx, now.Add(-m.retireAfter))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if retired > 0 {
+		log.InfoContext(ctx, "retired signing keys", slog.Int64("count", retired))
+	}
+
+	if err = m.reload(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// pendingKey returns the pending key, generating one if there is none.
+func (m *Manager) pendingKey(ctx context.Context) (models.SigningKey, error) {
+	key, err := jwtn.GenerateKey(m.alg)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	pem, err := jwtn.MarshalKey(key)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	pending := models.SigningKey{
+		KID:        key.ID,
+		Alg:        m.alg,
+		PrivateKey: pem,
+		State:      models.SigningKeyPending,
+		CreatedAt:  time.Now(),
+	}
+
+	err = m.storage.SaveSigningKey(ctx, pending)
+	if err == nil {
+		return pending, nil
+	}
+	if !errors.Is(err, storage.ErrSigningKeyExists) {
+		return models.SigningKey{}, err
+	}
+
+	// another rotation already published a key
+	keys, err := m.storage.SigningKeys(ctx)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	for _, k := range keys {
+		if k.State == models.SigningKeyPending {
+			return k, nil
+		}
+	}
+
+	return models.SigningKey{}, storage.ErrSigningKeyNotFound
+}
+
+// reload replaces cached keys with the non-retired keys from storage.
+func (m *Manager) reload(ctx context.Context) error {
+	keys, err := m.storage.SigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var signing *jwtn.Key
+	verification := make(map[string]jwtn.Key, len(keys))
+
+	for _, k := range keys {
+		if k.State == models.SigningKeyRetired {
+			continue
+		}
+
+		key, err := jwtn.ParseKey(k.Alg, k.PrivateKey)
+		if err != nil {
+			m.log.ErrorContext(ctx, "failed to parse signing key", slog.String("kid", k.KID), sl.Err(err))
+			continue
+		}
+
+		verification[key.ID] = key
+
+		// keys are ordered newest first
+		if signing == nil && k.State == models.SigningKeyActive && k.RotatedAt == nil {
+			signing = &key
+		}
+	}
+
+	m.mu.Lock()
+	m.signing = signing
+	m.verification = verification
+	m.lastReload = time.Now()
+	m.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,580 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// Provider implements the OpenID Connect authorization code flow with PKCE
+// on top of the Auth service.
+type Provider struct {
+	log         *slog.Logger
+	auth        Authenticator
+	usrProvider UserProvider
+	appProvider AppProvider
+	codes       AuthorizationCodeProvider
+	keys        jwtn.KeyProvider
+	revoked     jwtn.RevocationChecker
+	issuer      string
+	tokenTTL    time.Duration
+	sessionTTL  time.Duration
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, email, password string) (models.User, error)
+	IssueTokens(ctx context.Context, user models.User, app models.App, amr []string, opts ...jwtn.Option) (accessToken, refreshToken string, err error)
+	NewMFAChallenge(ctx context.Context, user models.User, app models.App) (string, error)
+	VerifyMFAChallenge(ctx context.Context, mfaToken, code string, app models.App) (models.User, []string, error)
+	RefreshToken(ctx context.Context, refreshToken string, appID int64) (accessToken, newRefreshToken string, err error)
+	VerifyAccessToken(ctx context.Context, token string) (jwt.MapClaims, error)
+}
+
+type UserProvider interface {
+	UserAllData(ctx context.Context, id int64) (models.User, error)
+}
+
+type AppProvider interface {
+	App(ctx context.Context, appID int64) (models.App, error)
+	AppRedirectURIs(ctx context.Context, appID int64) ([]string, error)
+	AppScopes(ctx context.Context, appID int64) ([]string, error)
+}
+
+type AuthorizationCodeProvider interface {
+	SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) error
+	AuthorizationCode(ctx context.Context, codeHash string) (models.AuthorizationCode, error)
+	UseAuthorizationCode(ctx context.Context, id int64) error
+}
+
+// Errors use the OAuth 2.0 error codes, so handlers can return them as is.
+var (
+	ErrInvalidRequest       = errors.New("invalid_request")
+	ErrInvalidClient        = errors.New("invalid_client")
+	ErrInvalidGrant         = errors.New("invalid_grant")
+	ErrUnsupportedGrantType = errors.New("unsupported_grant_type")
+	ErrUnsupportedResponse  = errors.New("unsupported_response_type")
+	ErrAccessDenied         = errors.New("access_denied")
+	ErrLoginRequired        = errors.New("login_required")
+	ErrInvalidToken         = errors.New("invalid_token")
+	ErrInvalidScope         = errors.New("invalid_scope")
+	ErrUnauthorizedClient   = errors.New("unauthorized_client")
+)
+
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+
+	CodeChallengeS256 = "S256"
+
+	authorizationCodeTTL   = 5 * time.Minute
+	authorizationCodeBytes = 32
+	sessionTokenType       = "sso_session"
+)
+
+// SupportedScopes are the scopes advertised through discovery.
+var SupportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+
+func New(
+	log *slog.Logger,
+	auth Authenticator,
+	userProvider UserProvider,
+	appProvider AppProvider,
+	codes AuthorizationCodeProvider,
+	keys jwtn.KeyProvider,
+	revoked jwtn.RevocationChecker,
+	issuer string,
+	tokenTTL time.Duration,
+	sessionTTL time.Duration,
+) *Provider {
+	return &Provider{
+		log:         log,
+		auth:        auth,
+		usrProvider: userProvider,
+		appProvider: appProvider,
+		codes:       codes,
+		keys:        keys,
+		revoked:     revoked,
+		issuer:      issuer,
+		tokenTTL:    tokenTTL,
+		sessionTTL:  sessionTTL,
+	}
+}
+
+// Issuer returns the issuer identifier put into ID tokens.
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// AuthorizationRequest holds the parameters of an authorization request.
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Prompt              string
+}
+
+// ValidateClient checks the client and its redirect URI. Until it succeeds,
+// errors must be shown to the user instead of being sent to the redirect URI.
+func (p *Provider) ValidateClient(ctx context.Context, req AuthorizationRequest) (models.App, error) {
+	appID, err := strconv.ParseInt(req.ClientID, 10, 64)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%w: malformed client_id", ErrInvalidClient)
+	}
+
+	app, err := p.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.App{}, fmt.Errorf("%w: unknown client_id", ErrInvalidClient)
+		}
+		return models.App{}, err
+	}
+
+	uris, err := p.appProvider.AppRedirectURIs(ctx, app.ID)
+	if err != nil {
+		return models.App{}, err
+	}
+
+	// redirect URIs are compared exactly, as recommended by OAuth 2.0 Security BCP
+	if !slices.Contains(uris, req.RedirectURI) {
+		return models.App{}, fmt.Errorf("%w: redirect_uri is not registered", ErrInvalidRequest)
+	}
+
+	if !allowsGrant(app, models.GrantAuthorizationCode) {
+		return models.App{}, fmt.Errorf("%w: the authorization code flow is not allowed", ErrUnauthorizedClient)
+	}
+
+	return app, nil
+}
+
+// ValidateRequest checks the rest of the authorization request parameters
+// of a client validated with ValidateClient.
+func (p *Provider) ValidateRequest(ctx context.Context, app models.App, req AuthorizationRequest) error {
+	if req.ResponseType != "code" {
+		return fmt.Errorf("%w: only the code response type is supported", ErrUnsupportedResponse)
+	}
+
+	if req.CodeChallenge == "" {
+		return fmt.Errorf("%w: code_challenge is required", ErrInvalidRequest)
+	}
+
+	if req.CodeChallengeMethod != CodeChallengeS256 {
+		return fmt.Errorf("%w: code_challenge_method must be S256", ErrInvalidRequest)
+	}
+
+	// the scope ends up in the access token, resource servers trust it
+	appScopes, err := p.appProvider.AppScopes(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	for _, s := range strings.Fields(req.Scope) {
+		if !slices.Contains(SupportedScopes, s) && !slices.Contains(appScopes, s) {
+			return fmt.Errorf("%w: scope %q is not allowed", ErrInvalidScope, s)
+		}
+	}
+
+	return nil
+}
+
+// LoginSession is a user logged in to the provider.
+type LoginSession struct {
+	User        models.User
+	AuthTime    time.Time
+	AuthMethods []string // amr values
+}
+
+// IssueCode creates an authorization code for the logged in user.
+func (p *Provider) IssueCode(ctx context.Context, app models.App, session LoginSession, req AuthorizationRequest) (string, error) {
+	const op = "oidc.IssueCode"
+
+	code, err := rnd.GenerateToken(authorizationCodeBytes)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+
+	err = p.codes.SaveAuthorizationCode(ctx, models.AuthorizationCode{
+		CodeHash:            hashCode(code),
+		AppID:               app.ID,
+		UserID:              session.User.ID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            session.AuthTime,
+		AuthMethods:         strings.Join(session.AuthMethods, " "),
+		ExpiresAt:           now.Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	p.log.InfoContext(ctx, "authorization code issued", slog.String("op", op), slog.Int64("app_id", app.ID), slog.Int64("user_id", session.User.ID))
+
+	return code, nil
+}
+
+// TokenResponse is the successful response of the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// ExchangeCode redeems an authorization code for tokens (authorization_code grant).
+//
+// Confidential clients must send their secret. Public clients have none,
+// PKCE, which is required from every client, binds the code to them.
+func (p *Provider) ExchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI, codeVerifier string) (TokenResponse, error) {
+	const op = "oidc.ExchangeCode"
+
+	log := p.log.With(slog.String("op", op), slog.String("client_id", clientID))
+
+	app, err := p.authenticateClient(ctx, clientID, clientSecret, models.GrantAuthorizationCode)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	authCode, err := p.codes.AuthorizationCode(ctx, hashCode(code))
+	if err != nil {
+		if errors.Is(err, storage.ErrAuthCodeNotFound) {
+			return TokenResponse{}, fmt.Errorf("%w: unknown code", ErrInvalidGrant)
+		}
+		return TokenResponse{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch {
+	case authCode.AppID != app.ID:
+		return TokenResponse{}, fmt.Errorf("%w: code was issued to another client", ErrInvalidGrant)
+	case authCode.RedirectURI != redirectURI:
+		return TokenResponse{}, fmt.Errorf("%w: redirect_uri mismatch", ErrInvalidGrant)
+	case time.Now().After(authCode.ExpiresAt):
+		return TokenResponse{}, fmt.Errorf("%w: code expired", ErrInvalidGrant)
+	case !verifyCodeChallenge(authCode.CodeChallenge, codeVerifier):
+		return TokenResponse{}, fmt.Errorf("%w: invalid code_verifier", ErrInvalidGrant)
+	}
+
+	if err = p.codes.UseAuthorizationCode(ctx, authCode.ID); err != nil {
+		if errors.Is(err, storage.ErrAuthCodeUsed) {
+			log.WarnContext(ctx, "authorization code reused")
+
+			return TokenResponse{}, fmt.Errorf("%w: code already used", ErrInvalidGrant)
+		}
+		return TokenResponse{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := p.usrProvider.UserAllData(ctx, authCode.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return TokenResponse{}, fmt.Errorf("%w: user no longer exists", ErrInvalidGrant)
+		}
+		return TokenResponse{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	accessToken, refreshToken, err := p.auth.IssueTokens(ctx, user, app, strings.Fields(authCode.AuthMethods), jwtn.WithScope(authCode.Scope))
+	if err != nil {
+		return TokenResponse{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp := TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(p.accessTokenTTL(app).Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        authCode.Scope,
+	}
+
+	if hasScope(authCode.Scope, ScopeOpenID) {
+		resp.IDToken, err = p.idToken(ctx, app, user, authCode)
+		if err != nil {
+			return TokenResponse{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	log.InfoContext(ctx, "authorization code exchanged", slog.Int64("user_id", user.ID))
+
+	return resp, nil
+}
+
+// Refresh handles the refresh_token grant.
+func (p *Provider) Refresh(ctx context.Context, clientID, clientSecret, refreshToken string) (TokenResponse, error) {
+	const op = "oidc.Refresh"
+
+	app, err := p.authenticateClient(ctx, clientID, clientSecret, models.GrantRefreshToken)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	accessToken, newRefreshToken, err := p.auth.RefreshToken(ctx, refreshToken, app.ID)
+	if err != nil {
+		return TokenResponse{}, fmt.Errorf("%s: %w: %v", op, ErrInvalidGrant, err)
+	}
+
+	return TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(p.accessTokenTTL(app).Seconds()),
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// UserInfo returns the claims about the owner of the access token allowed by its scope.
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	const op = "oidc.UserInfo"
+
+	claims, err := p.auth.VerifyAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", op, ErrInvalidToken, err)
+	}
+
+	scope, _ := claims["scope"].(string)
+	if !hasScope(scope, ScopeOpenID) {
+		return nil, fmt.Errorf("%s: %w: token has no openid scope", op, ErrInvalidToken)
+	}
+
+	uid, _ := claims["uid"].(float64)
+
+	user, err := p.usrProvider.UserAllData(ctx, int64(uid))
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	info := map[string]interface{}{"sub": subject(user)}
+	addUserClaims(info, user, scope)
+
+	return info, nil
+}
+
+// Login checks user credentials for the login page. If the user has
+// two-factor authentication enabled, only mfaToken is returned, to be
+// answered with CompleteLogin.
+func (p *Provider) Login(ctx context.Context, app models.App, email, password string) (session LoginSession, mfaToken string, err error) {
+	user, err := p.auth.Authenticate(ctx, email, password)
+	if err != nil {
+		return LoginSession{}, "", err
+	}
+
+	mfaToken, err = p.auth.NewMFAChallenge(ctx, user, app)
+	if err != nil || mfaToken != "" {
+		return LoginSession{}, mfaToken, err
+	}
+
+	return LoginSession{
+		User:        user,
+		AuthTime:    time.Now(),
+		AuthMethods: []string{auth.AuthMethodPassword},
+	}, "", nil
+}
+
+// CompleteLogin checks the second factor code for a challenge returned by Login.
+func (p *Provider) CompleteLogin(ctx context.Context, app models.App, mfaToken, code string) (LoginSession, error) {
+	user, amr, err := p.auth.VerifyMFAChallenge(ctx, mfaToken, code, app)
+	if err != nil {
+		return LoginSession{}, err
+	}
+
+	return LoginSession{
+		User:        user,
+		AuthTime:    time.Now(),
+		AuthMethods: amr,
+	}, nil
+}
+
+// NewSession creates an SSO session token to be kept in a cookie, so the
+// user does not have to log in again for every app.
+func (p *Provider) NewSession(ctx context.Context, session LoginSession) (string, error) {
+	key, err := p.keys.SigningKey(ctx, models.App{})
+	if err != nil {
+		return "", err
+	}
+
+	return jwtn.NewToken(key, session.User, models.App{}, p.sessionTTL,
+		jwtn.WithType(sessionTokenType), jwtn.WithAuthMethods(session.AuthMethods))
+}
+
+// Session returns the login session of a valid SSO session token.
+func (p *Provider) Session(ctx context.Context, session string) (LoginSession, error) {
+	claims, err := jwtn.ParseTypedToken(ctx, session, sessionTokenType, models.App{}, p.keys, p.revoked)
+	if err != nil {
+		return LoginSession{}, fmt.Errorf("%w: %v", ErrLoginRequired, err)
+	}
+
+	uid, _ := claims["uid"].(float64)
+	iat, _ := claims["iat"].(float64)
+
+	user, err := p.usrProvider.UserAllData(ctx, int64(uid))
+	if err != nil {
+		return LoginSession{}, fmt.Errorf("%w: %v", ErrLoginRequired, err)
+	}
+
+	return LoginSession{
+		User:        user,
+		AuthTime:    time.Unix(int64(iat), 0),
+		AuthMethods: jwtn.AuthMethods(claims),
+	}, nil
+}
+
+// SessionTTL returns how long SSO sessions last.
+func (p *Provider) SessionTTL() time.Duration {
+	return p.sessionTTL
+}
+
+// authenticateClient checks the client secret and that the app may use
+// grantType. Only apps registered as public authenticate without a secret
+// (auth method none), they must not send one.
+func (p *Provider) authenticateClient(ctx context.Context, clientID, clientSecret, grantType string) (models.App, error) {
+	appID, err := strconv.ParseInt(clientID, 10, 64)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%w: malformed client_id", ErrInvalidClient)
+	}
+
+	app, err := p.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.App{}, fmt.Errorf("%w: unknown client", ErrInvalidClient)
+		}
+		return models.App{}, err
+	}
+
+	switch {
+	case app.Public:
+		if clientSecret != "" {
+			return models.App{}, fmt.Errorf("%w: public clients have no secret", ErrInvalidClient)
+		}
+		// nothing proves who a public client is without a user
+		if grantType == models.GrantClientCredentials {
+			return models.App{}, fmt.Errorf("%w: public clients can not use the client credentials grant", ErrUnauthorizedClient)
+		}
+	case clientSecret == "":
+		return models.App{}, fmt.Errorf("%w: client authentication is required", ErrInvalidClient)
+	case app.SecretHash == "" || !appsecret.Verify(clientSecret, app.SecretHash):
+		p.log.WarnContext(ctx, "invalid client secret", slog.Int64("app_id", app.ID))
+
+		return models.App{}, fmt.Errorf("%w: invalid client secret", ErrInvalidClient)
+	}
+
+	if !allowsGrant(app, grantType) {
+		p.log.WarnContext(ctx, "grant type is not allowed for the app", slog.Int64("app_id", app.ID), slog.String("grant_type", grantType))
+
+		return models.App{}, fmt.Errorf("%w: grant type %s is not allowed", ErrUnauthorizedClient, grantType)
+	}
+
+	return app, nil
+}
+
+func allowsGrant(app models.App, grantType string) bool {
+	return slices.Contains(strings.Fields(app.GrantTypes), grantType)
+}
+
+// accessTokenTTL returns the lifetime of access tokens of app, which may
+// override the default.
+func (p *Provider) accessTokenTTL(app models.App) time.Duration {
+	if app.TokenTTLSeconds > 0 {
+		return time.Duration(app.TokenTTLSeconds) * time.Second
+	}
+
+	return p.tokenTTL
+}
+
+func (p *Provider) idToken(ctx context.Context, app models.App, user models.User, code models.AuthorizationCode) (string, error) {
+	key, err := p.keys.SigningKey(ctx, app)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"iss":       p.issuer,
+		"sub":       subject(user),
+		"aud":       strconv.FormatInt(app.ID, 10),
+		"iat":       now.Unix(),
+		"exp":       now.Add(p.tokenTTL).Unix(),
+		"auth_time": code.AuthTime.Unix(),
+	}
+	if code.Nonce != "" {
+		claims["nonce"] = code.Nonce
+	}
+	if code.AuthMethods != "" {
+		claims["amr"] = strings.Fields(code.AuthMethods)
+	}
+
+	addUserClaims(claims, user, code.Scope)
+
+	return jwtn.Sign(key, claims)
+}
+
+func addUserClaims(claims map[string]interface{}, user models.User, scope string) {
+	if hasScope(scope, ScopeProfile) {
+		claims["given_name"] = user.FirstName
+		claims["family_name"] = user.LastName
+		claims["name"] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+
+	if hasScope(scope, ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.IsEmailConfirmed
+	}
+
+	if hasScope(scope, ScopePhone) {
+		claims["phone_number"] = user.PhoneNumber
+	}
+}
+
+func subject(user models.User) string {
+	return strconv.FormatInt(user.ID, 10)
+}
+
+func hasScope(scope, want string) bool {
+	return slices.Contains(strings.Fields(scope), want)
+}
+
+// verifyCodeChallenge checks the PKCE code verifier against the S256 challenge.
+func verifyCodeChallenge(challenge, verifier string) bool {
+	// RFC 7636: 43 to 128 characters
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,315 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/appsecret"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+const (
+	testSecret      = "test-secret"
+	testRedirectURI = "https://app.example.com/callback"
+	// RFC 7636 appendix B
+	testVerifier = "dBjftJeZ4CK-pURK0GDrhcIWF7hGbCSDvAmKjJzQVuw"
+)
+
+// fakeAuth issues fixed tokens, the tests cover client authentication only.
+type fakeAuth struct{}
+
+func (fakeAuth) Authenticate(ctx context.Context, email, password string) (models.User, error) {
+	return models.User{}, errors.New("not implemented")
+}
+
+func (fakeAuth) IssueTokens(ctx context.Context, user models.User, app models.App, amr []string, opts ...jwtn.Option) (string, string, error) {
+	return "access", "refresh", nil
+}
+
+func (fakeAuth) NewMFAChallenge(ctx context.Context, user models.User, app models.App) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (fakeAuth) VerifyMFAChallenge(ctx context.Context, mfaToken, code string, app models.App) (models.User, []string, error) {
+	return models.User{}, nil, errors.New("not implemented")
+}
+
+func (fakeAuth) RefreshToken(ctx context.Context, refreshToken string, appID int64) (string, string, error) {
+	return "access", "refresh", nil
+}
+
+func (fakeAuth) VerifyAccessToken(ctx context.Context, token string) (jwt.MapClaims, error) {
+	return nil, errors.New("not implemented")
+}
+
+type testEnv struct {
+	provider *Provider
+	storage  *memory.Storage
+	user     models.User
+}
+
+func newTestEnv(t *testing.T) testEnv {
+	t.Helper()
+
+	s := memory.New()
+
+	user := models.User{Email: "user@example.com", PasswordHash: []byte("hash")}
+	id, err := s.SaveUser(context.Background(), user, "123456", models.OutboxEmail{Recipient: user.Email})
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	user.ID = id
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	provider := New(log, fakeAuth{}, s, s, s, jwtn.NewStaticKeys(nil, time.Time{}), nil, "http://localhost", time.Hour, time.Hour)
+
+	return testEnv{provider: provider, storage: s, user: user}
+}
+
+func (e testEnv) saveApp(t *testing.T, public bool) models.App {
+	t.Helper()
+
+	app := models.App{
+		Name:         "app " + strconv.FormatBool(public),
+		GrantTypes:   "authorization_code refresh_token",
+		RedirectURIs: []string{testRedirectURI},
+		Public:       public,
+	}
+	if !public {
+		app.SecretHash = appsecret.Hash(testSecret)
+	}
+
+	app, err := e.storage.SaveApp(context.Background(), app)
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	return app
+}
+
+func (e testEnv) issueCode(t *testing.T, app models.App) string {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(testVerifier))
+
+	code, err := e.provider.IssueCode(context.Background(), app, LoginSession{User: e.user, AuthTime: time.Now()}, AuthorizationRequest{
+		ClientID:            strconv.FormatInt(app.ID, 10),
+		RedirectURI:         testRedirectURI,
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		CodeChallengeMethod: CodeChallengeS256,
+	})
+	if err != nil {
+		t.Fatalf("issue code: %v", err)
+	}
+
+	return code
+}
+
+func TestExchangeCodeConfidentialClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr error
+	}{
+		{"omitted secret", "", ErrInvalidClient},
+		{"wrong secret", "wrong-secret", ErrInvalidClient},
+		{"valid secret", testSecret, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			app := env.saveApp(t, false)
+			code := env.issueCode(t, app)
+
+			_, err := env.provider.ExchangeCode(context.Background(), strconv.FormatInt(app.ID, 10), tt.secret, code, testRedirectURI, testVerifier)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExchangeCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshConfidentialClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr error
+	}{
+		{"omitted secret", "", ErrInvalidClient},
+		{"wrong secret", "wrong-secret", ErrInvalidClient},
+		{"valid secret", testSecret, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			app := env.saveApp(t, false)
+
+			_, err := env.provider.Refresh(context.Background(), strconv.FormatInt(app.ID, 10), tt.secret, "refresh")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeCodePublicClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		verifier string
+		wantErr  error
+	}{
+		{"without secret", "", testVerifier, nil},
+		{"with secret", testSecret, testVerifier, ErrInvalidClient},
+		{"without code verifier", "", "", ErrInvalidGrant},
+		{"wrong code verifier", "", testVerifier[1:] + "x", ErrInvalidGrant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			app := env.saveApp(t, true)
+			code := env.issueCode(t, app)
+
+			_, err := env.provider.ExchangeCode(context.Background(), strconv.FormatInt(app.ID, 10), tt.secret, code, testRedirectURI, tt.verifier)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExchangeCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublicClientCanNotUseClientCredentials(t *testing.T) {
+	env := newTestEnv(t)
+
+	app, err := env.storage.SaveApp(context.Background(), models.App{
+		Name:       "public",
+		GrantTypes: models.GrantClientCredentials,
+		Public:     true,
+	})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	_, err = env.provider.ClientCredentials(context.Background(), strconv.FormatInt(app.ID, 10), "", "")
+	if !errors.Is(err, ErrUnauthorizedClient) {
+		t.Fatalf("ClientCredentials() error = %v, want %v", err, ErrUnauthorizedClient)
+	}
+}
+
+func TestValidateRequestScope(t *testing.T) {
+	env := newTestEnv(t)
+
+	app, err := env.storage.SaveApp(context.Background(), models.App{
+		Name:         "app",
+		GrantTypes:   "authorization_code",
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{"docs:read"},
+	})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		scope   string
+		wantErr error
+	}{
+		{"no scope", "", nil},
+		{"supported scopes", "openid email profile", nil},
+		{"app scope", "openid docs:read", nil},
+		{"unknown scope", "openid docs:write", ErrInvalidScope},
+		{"scope of another app", "admin", ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := env.provider.ValidateRequest(context.Background(), app, AuthorizationRequest{
+				ResponseType:        "code",
+				ClientID:            strconv.FormatInt(app.ID, 10),
+				RedirectURI:         testRedirectURI,
+				Scope:               tt.scope,
+				CodeChallenge:       "challenge",
+				CodeChallengeMethod: CodeChallengeS256,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateRequest() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	env := newTestEnv(t)
+
+	app, err := env.storage.SaveApp(context.Background(), models.App{
+		Name:       "service",
+		GrantTypes: models.GrantClientCredentials,
+		Scopes:     []string{"docs:read", "docs:write"},
+		Secret:     testSecret,
+		SecretHash: appsecret.Hash(testSecret),
+	})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+	clientID := strconv.FormatInt(app.ID, 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		scope     string
+		wantScope string
+		wantErr   error
+	}{
+		{"all app scopes", testSecret, "", "docs:read docs:write", nil},
+		{"narrowed scope", testSecret, "docs:read", "docs:read", nil},
+		{"unknown scope", testSecret, "docs:read docs:delete", "", ErrInvalidScope},
+		{"wrong secret", "wrong-secret", "docs:read", "", ErrInvalidClient},
+		{"omitted secret", "", "", "", ErrInvalidClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := env.provider.ClientCredentials(context.Background(), clientID, tt.secret, tt.scope)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClientCredentials() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if resp.Scope != tt.wantScope {
+				t.Fatalf("scope = %q, want %q", resp.Scope, tt.wantScope)
+			}
+			if resp.RefreshToken != "" {
+				t.Fatalf("client credentials grant returned a refresh token")
+			}
+
+			claims := jwt.MapClaims{}
+			if _, err = jwt.ParseWithClaims(resp.AccessToken, claims, func(*jwt.Token) (any, error) {
+				return []byte(testSecret), nil
+			}); err != nil {
+				t.Fatalf("parse access token: %v", err)
+			}
+			if claims["scope"] != tt.wantScope {
+				t.Fatalf("scope claim = %v, want %q", claims["scope"], tt.wantScope)
+			}
+			if _, ok := claims["uid"]; ok {
+				t.Fatalf("client token has a uid claim")
+			}
+		})
+	}
+}
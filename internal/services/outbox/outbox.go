@@ -0,0 +1,160 @@
+// Package outbox delivers the emails queued in the email outbox.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/lib/mailer"
+)
+
+// Mailer delivers the emails of the service.
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+}
+
+type Storage interface {
+	ClaimOutboxEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error)
+	MarkOutboxEmailSent(ctx context.Context, id int64) error
+	MarkOutboxEmailFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error
+	DeleteSentOutboxEmails(ctx context.Context, sentBefore time.Time) (int64, error)
+}
+
+const (
+	batchSize = 50
+	// sendTimeout bounds a single delivery, claimed emails are leased
+	// for longer so they are not picked up again while being sent.
+	sendTimeout = 30 * time.Second
+	lease       = 2 * sendTimeout
+	// maxErrorLen keeps the recorded delivery error readable.
+	maxErrorLen = 1000
+)
+
+// Worker sends pending emails, retrying failed deliveries with exponential
+// backoff until maxAttempts is reached and the email is marked dead.
+type Worker struct {
+	log         *slog.Logger
+	storage     Storage
+	mailer      Mailer
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	retention   time.Duration
+}
+
+// New returns a new outbox worker. backoff is the delay after the first
+// failed attempt, doubled after every next one up to maxBackoff.
+// Sent emails are deleted after retention.
+func New(
+	log *slog.Logger,
+	storage Storage,
+	mailer Mailer,
+	maxAttempts int,
+	backoff time.Duration,
+	maxBackoff time.Duration,
+	retention time.Duration,
+) *Worker {
+	return &Worker{
+		log:         log,
+		storage:     storage,
+		mailer:      mailer,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+		retention:   retention,
+	}
+}
+
+// Deliver sends emails due for delivery until none are left.
+func (w *Worker) Deliver(ctx context.Context) error {
+	const op = "outbox.Deliver"
+
+	for {
+		emails, err := w.storage.ClaimOutboxEmails(ctx, batchSize, lease)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, email := range emails {
+			if err = w.send(ctx, email); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		if len(emails) < batchSize {
+			return nil
+		}
+	}
+}
+
+// send delivers a single email and records the outcome. Only failures
+// to record it are returned, delivery errors are retried later.
+func (w *Worker) send(ctx context.Context, email models.OutboxEmail) error {
+	log := w.log.With(
+		slog.Int64("email_id", email.ID),
+		slog.Int("attempt", email.Attempts),
+	)
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	err := w.mailer.Send(sendCtx, mailer.Message{
+		To:      email.Recipient,
+		Subject: email.Subject,
+		HTML:    email.HTML,
+		Text:    email.Text,
+	})
+	cancel()
+
+	if err == nil {
+		log.InfoContext(ctx, "email sent")
+
+		return w.storage.MarkOutboxEmailSent(ctx, email.ID)
+	}
+
+	if ctx.Err() != nil {
+		// shutting down, the lease expires and the email is retried
+		return ctx.Err()
+	}
+
+	dead := email.Attempts >= w.maxAttempts
+	if dead {
+		log.ErrorContext(ctx, "email delivery failed, giving up", sl.Err(err))
+	} else {
+		log.WarnContext(ctx, "email delivery failed, will retry", sl.Err(err))
+	}
+
+	msg := err.Error()
+	if len(msg) > maxErrorLen {
+		msg = msg[:maxErrorLen]
+	}
+
+	return w.storage.MarkOutboxEmailFailed(ctx, email.ID, msg, time.Now().Add(w.retryDelay(email.Attempts)), dead)
+}
+
+// retryDelay returns the delay before the next attempt after the given
+// number of failed ones.
+func (w *Worker) retryDelay(attempts int) time.Duration {
+	delay := w.backoff
+	for i := 1; i < attempts && delay < w.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, w.maxBackoff)
+}
+
+// PurgeSent deletes emails sent longer than the retention period ago.
+func (w *Worker) PurgeSent(ctx context.Context) error {
+	const op = "outbox.PurgeSent"
+
+	deleted, err := w.storage.DeleteSentOutboxEmails(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.log.DebugContext(ctx, "purged sent emails", slog.String("op", op), slog.Int64("deleted", deleted))
+
+	return nil
+}
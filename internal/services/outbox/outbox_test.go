@@ -0,0 +1,194 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/mailer"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+var errMailerDown = errors.New("mailer is down")
+
+// flakyMailer fails every delivery while down is set.
+type flakyMailer struct {
+	down  bool
+	sends int
+	sent  []mailer.Message
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sends++
+	if m.down {
+		return errMailerDown
+	}
+
+	m.sent = append(m.sent, msg)
+
+	return nil
+}
+
+func newTestWorker(s *memory.Storage, m Mailer, backoff time.Duration) *Worker {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), s, m, 3, backoff, 4*backoff, time.Hour)
+}
+
+// queueEmail queues the confirmation email of a new user.
+func queueEmail(t *testing.T, s *memory.Storage) {
+	t.Helper()
+
+	email := models.OutboxEmail{Recipient: "user@example.com", Subject: "Confirm your email", Text: "123456"}
+	if _, err := s.SaveUser(context.Background(), models.User{Email: email.Recipient, PasswordHash: []byte("hash")}, "123456", email); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+}
+
+// queued returns the only email of the outbox.
+func queued(t *testing.T, s *memory.Storage) models.OutboxEmail {
+	t.Helper()
+
+	emails, err := s.OutboxEmails(context.Background(), "", 10)
+	if err != nil || len(emails) != 1 {
+		t.Fatalf("outbox emails = %v, %v, want one", emails, err)
+	}
+
+	return emails[0]
+}
+
+func TestRetryDelay(t *testing.T) {
+	w := New(nil, nil, nil, 10, time.Minute, 10*time.Minute, time.Hour)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := w.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverBacksOff(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	m := &flakyMailer{down: true}
+	w := newTestWorker(s, m, time.Hour)
+
+	queueEmail(t, s)
+
+	if err := w.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	email := queued(t, s)
+	if email.Status != models.OutboxPending || email.Attempts != 1 || email.LastError != errMailerDown.Error() {
+		t.Fatalf("email after failed delivery = %+v", email)
+	}
+	if delay := time.Until(email.NextAttemptAt); delay < 59*time.Minute || delay > time.Hour {
+		t.Fatalf("next attempt in %v, want in an hour", delay)
+	}
+
+	// the email is not due yet
+	if err := w.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if m.sends != 1 {
+		t.Fatalf("sent %d times, want 1", m.sends)
+	}
+}
+
+func TestDeliverDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	m := &flakyMailer{down: true}
+	// without backoff the email is due again right away
+	w := newTestWorker(s, m, 0)
+
+	queueEmail(t, s)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if err := w.Deliver(ctx); err != nil {
+			t.Fatalf("Deliver() #%d error = %v", attempt, err)
+		}
+
+		want := models.OutboxPending
+		if attempt == 3 {
+			want = models.OutboxDead
+		}
+		if email := queued(t, s); email.Status != want || email.Attempts != attempt {
+			t.Fatalf("email after attempt %d = %+v, want %s", attempt, email, want)
+		}
+	}
+
+	if err := w.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if m.sends != 3 {
+		t.Fatalf("sent %d times, want 3", m.sends)
+	}
+
+	// a retry gets a fresh attempt budget
+	email := queued(t, s)
+	if err := s.RetryOutboxEmail(ctx, email.ID); err != nil {
+		t.Fatalf("RetryOutboxEmail() error = %v", err)
+	}
+	if email = queued(t, s); email.Status != models.OutboxPending || email.Attempts != 0 {
+		t.Fatalf("email after retry = %+v", email)
+	}
+
+	m.down = false
+	if err := w.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	email = queued(t, s)
+	if email.Status != models.OutboxSent || email.SentAt == nil || email.LastError != "" {
+		t.Fatalf("email after delivery = %+v", email)
+	}
+	if len(m.sent) != 1 || m.sent[0].To != "user@example.com" {
+		t.Fatalf("sent messages = %+v", m.sent)
+	}
+
+	if err := s.RetryOutboxEmail(ctx, email.ID); !errors.Is(err, storage.ErrOutboxEmailNotFound) {
+		t.Fatalf("RetryOutboxEmail() of sent email error = %v, want %v", err, storage.ErrOutboxEmailNotFound)
+	}
+}
+
+func TestDeliverSkipsLeasedEmails(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	m := &flakyMailer{}
+	w := newTestWorker(s, m, time.Minute)
+
+	queueEmail(t, s)
+
+	// another worker is sending the email
+	claimed, err := s.ClaimOutboxEmails(ctx, batchSize, lease)
+	if err != nil || len(claimed) != 1 || claimed[0].Attempts != 1 {
+		t.Fatalf("ClaimOutboxEmails() = %+v, %v, want the email on its first attempt", claimed, err)
+	}
+	if claimed, err = s.ClaimOutboxEmails(ctx, batchSize, lease); err != nil || len(claimed) != 0 {
+		t.Fatalf("ClaimOutboxEmails() again = %+v, %v, want none", claimed, err)
+	}
+
+	if err = w.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if m.sends != 0 {
+		t.Fatalf("leased email sent %d times", m.sends)
+	}
+}
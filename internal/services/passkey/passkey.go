@@ -0,0 +1,462 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt/logger/sl"
+	"github.com/orenvadi/auth-grpc/internal/lib/rnd"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// Service implements passwordless login with passkeys (WebAuthn).
+type Service struct {
+	log         *slog.Logger
+	webAuthn    *webauthn.WebAuthn
+	auth        Authenticator
+	usrProvider UserProvider
+	appProvider AppProvider
+	credentials CredentialProvider
+	sessions    SessionProvider
+}
+
+type Authenticator interface {
+	IssueTokens(ctx context.Context, user models.User, app models.App, amr []string, opts ...jwtn.Option) (accessToken, refreshToken string, err error)
+}
+
+type UserProvider interface {
+	UserAllData(ctx context.Context, id int64) (models.User, error)
+}
+
+type AppProvider interface {
+	App(ctx context.Context, appID int64) (models.App, error)
+}
+
+type CredentialProvider interface {
+	SaveWebAuthnCredential(ctx context.Context, cred models.WebAuthnCredential) error
+	WebAuthnCredentials(ctx context.Context, userID int64) ([]models.WebAuthnCredential, error)
+	UpdateWebAuthnCredential(ctx context.Context, id int64, data []byte) error
+}
+
+type SessionProvider interface {
+	SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) error
+	TakeWebAuthnSession(ctx context.Context, id string) (models.WebAuthnSession, error)
+	DeleteExpiredWebAuthnSessions(ctx context.Context) (int64, error)
+}
+
+var (
+	ErrInvalidToken        = errors.New("invalid token")
+	ErrInvalidSession      = errors.New("invalid or expired webauthn session")
+	ErrInvalidCredential   = errors.New("invalid webauthn credential")
+	ErrCredentialExists    = errors.New("passkey is already registered")
+	ErrClonedAuthenticator = errors.New("authenticator may be cloned")
+)
+
+// Authentication methods of passkey logins (RFC 8176). User verification is
+// required, so a passkey is both something the user has and is or knows.
+var authMethods = []string{"hwk", "mfa"}
+
+const (
+	sessionTTL   = 5 * time.Minute
+	sessionBytes = 32
+)
+
+func New(
+	log *slog.Logger,
+	webAuthn *webauthn.WebAuthn,
+	auth Authenticator,
+	userProvider UserProvider,
+	appProvider AppProvider,
+	credentials CredentialProvider,
+	sessions SessionProvider,
+) *Service {
+	return &Service{
+		log:         log,
+		webAuthn:    webAuthn,
+		auth:        auth,
+		usrProvider: userProvider,
+		appProvider: appProvider,
+		credentials: credentials,
+		sessions:    sessions,
+	}
+}
+
+// NewWebAuthn configures the relying party, i.e. the site passkeys are bound to.
+func NewWebAuthn(rpID, rpName string, origins []string) (*webauthn.WebAuthn, error) {
+	return webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: rpName,
+		RPOrigins:     origins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: sessionTTL, TimeoutUVD: sessionTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: sessionTTL, TimeoutUVD: sessionTTL},
+		},
+	})
+}
+
+// BeginRegistration starts registration of a passkey for the authenticated
+// user of the request. It returns the options for navigator.credentials.create()
+// as JSON and the ID of the ceremony to finish it with.
+func (s *Service) BeginRegistration(ctx context.Context, appID int64) (optionsJSON []byte, sessionID string, err error) {
+	const op = "passkey.BeginRegistration"
+
+	user, err := s.requestUser(ctx, appID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	wUser, err := s.webAuthnUser(ctx, user)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(wUser.credentials))
+	for _, c := range wUser.credentials {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(wUser, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessionID, err = s.saveSession(ctx, &user.ID, appID, models.WebAuthnRegistration, session)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if optionsJSON, err = json.Marshal(creation); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return optionsJSON, sessionID, nil
+}
+
+// FinishRegistration verifies the response of navigator.credentials.create()
+// and stores the new passkey under the given name.
+func (s *Service) FinishRegistration(ctx context.Context, appID int64, sessionID string, credentialJSON []byte, name string) (credentialID []byte, err error) {
+	const op = "passkey.FinishRegistration"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("app_id", appID))
+
+	user, err := s.requestUser(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	session, err := s.takeSession(ctx, sessionID, appID, models.WebAuthnRegistration)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wUser, err := s.webAuthnUser(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(credentialJSON))
+	if err != nil {
+		log.InfoContext(ctx, "malformed credential", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidCredential)
+	}
+
+	// also checks the session was started by this user
+	cred, err := s.webAuthn.CreateCredential(wUser, session, parsed)
+	if err != nil {
+		log.InfoContext(ctx, "credential verification failed", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidCredential)
+	}
+
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.credentials.SaveWebAuthnCredential(ctx, models.WebAuthnCredential{
+		UserID:       user.ID,
+		CredentialID: cred.ID,
+		Name:         strings.TrimSpace(name),
+		Data:         data,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrCredentialExists) {
+			return nil, fmt.Errorf("%s: %w", op, ErrCredentialExists)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "passkey registered", slog.Int64("user_id", user.ID))
+
+	return cred.ID, nil
+}
+
+// BeginLogin starts a passkey login. The user is not known until the
+// authenticator responds with one of the passkeys stored on it.
+func (s *Service) BeginLogin(ctx context.Context, appID int64) (optionsJSON []byte, sessionID string, err error) {
+	const op = "passkey.BeginLogin"
+
+	if _, err = s.appProvider.App(ctx, appID); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessionID, err = s.saveSession(ctx, nil, appID, models.WebAuthnLogin, session)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if optionsJSON, err = json.Marshal(assertion); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return optionsJSON, sessionID, nil
+}
+
+// FinishLogin verifies the response of navigator.credentials.get() and
+// issues tokens the same way a password login does.
+func (s *Service) FinishLogin(ctx context.Context, appID int64, sessionID string, credentialJSON []byte) (accessToken, refreshToken string, err error) {
+	const op = "passkey.FinishLogin"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("app_id", appID))
+
+	session, err := s.takeSession(ctx, sessionID, appID, models.WebAuthnLogin)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := s.appProvider.App(ctx, appID)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(credentialJSON))
+	if err != nil {
+		log.InfoContext(ctx, "malformed assertion", sl.Err(err))
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredential)
+	}
+
+	var wUser *user
+	cred, err := s.webAuthn.ValidateDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		userID, err := userIDFromHandle(userHandle)
+		if err != nil {
+			return nil, err
+		}
+
+		u, err := s.usrProvider.UserAllData(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		wUser, err = s.webAuthnUser(ctx, u)
+		return wUser, err
+	}, session, parsed)
+	if err != nil {
+		log.InfoContext(ctx, "assertion verification failed", sl.Err(err))
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredential)
+	}
+
+	if cred.Authenticator.CloneWarning {
+		log.WarnContext(ctx, "sign counter went backwards, authenticator may be cloned", slog.Int64("user_id", wUser.ID))
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrClonedAuthenticator)
+	}
+
+	if err = s.updateCredential(ctx, wUser, cred); err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	accessToken, refreshToken, err = s.auth.IssueTokens(ctx, wUser.User, app, authMethods)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to issue tokens", sl.Err(err))
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.InfoContext(ctx, "user logged in with passkey", slog.Int64("user_id", wUser.ID))
+
+	return accessToken, refreshToken, nil
+}
+
+// PurgeSessions drops expired registration and login ceremonies.
+func (s *Service) PurgeSessions(ctx context.Context) error {
+	const op = "passkey.PurgeSessions"
+
+	deleted, err := s.sessions.DeleteExpiredWebAuthnSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.DebugContext(ctx, "purged expired webauthn sessions", slog.String("op", op), slog.Int64("deleted", deleted))
+
+	return nil
+}
+
+// requestUser returns the user the auth interceptor authenticated, if the
+// access token was issued for app.
+func (s *Service) requestUser(ctx context.Context, appID int64) (models.User, error) {
+	p, ok := jwtn.PrincipalFromContext(ctx)
+	if !ok || p.UserID == 0 || p.AppID != appID {
+		return models.User{}, ErrInvalidToken
+	}
+
+	u, err := s.usrProvider.UserAllData(ctx, p.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.User{}, ErrInvalidToken
+		}
+
+		return models.User{}, err
+	}
+
+	return u, nil
+}
+
+func (s *Service) saveSession(ctx context.Context, userID *int64, appID int64, kind string, session *webauthn.SessionData) (string, error) {
+	id, err := rnd.GenerateToken(sessionBytes)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.sessions.SaveWebAuthnSession(ctx, models.WebAuthnSession{
+		ID:        id,
+		UserID:    userID,
+		AppID:     appID,
+		Kind:      kind,
+		Data:      data,
+		ExpiresAt: time.Now().Add(sessionTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *Service) takeSession(ctx context.Context, id string, appID int64, kind string) (webauthn.SessionData, error) {
+	session, err := s.sessions.TakeWebAuthnSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrWebAuthnSessionNotFound) {
+			return webauthn.SessionData{}, ErrInvalidSession
+		}
+
+		return webauthn.SessionData{}, err
+	}
+
+	if session.AppID != appID || session.Kind != kind || time.Now().After(session.ExpiresAt) {
+		return webauthn.SessionData{}, ErrInvalidSession
+	}
+
+	var data webauthn.SessionData
+	if err = json.Unmarshal(session.Data, &data); err != nil {
+		return webauthn.SessionData{}, err
+	}
+
+	return data, nil
+}
+
+// updateCredential persists the sign counter and flags after a login.
+func (s *Service) updateCredential(ctx context.Context, u *user, cred *webauthn.Credential) error {
+	for _, stored := range u.stored {
+		if !bytes.Equal(stored.CredentialID, cred.ID) {
+			continue
+		}
+
+		data, err := json.Marshal(cred)
+		if err != nil {
+			return err
+		}
+
+		return s.credentials.UpdateWebAuthnCredential(ctx, stored.ID, data)
+	}
+
+	return ErrInvalidCredential
+}
+
+// user adapts models.User to webauthn.User.
+type user struct {
+	models.User
+	stored      []models.WebAuthnCredential
+	credentials []webauthn.Credential
+}
+
+func (s *Service) webAuthnUser(ctx context.Context, u models.User) (*user, error) {
+	stored, err := s.credentials.WebAuthnCredentials(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for _, c := range stored {
+		var cred webauthn.Credential
+		if err = json.Unmarshal(c.Data, &cred); err != nil {
+			return nil, fmt.Errorf("failed to decode credential %d: %w", c.ID, err)
+		}
+
+		credentials = append(credentials, cred)
+	}
+
+	return &user{User: u, stored: stored, credentials: credentials}, nil
+}
+
+// WebAuthnID is the user handle stored on authenticators. It is the user ID,
+// so discoverable logins can find the user without any other input.
+func (u *user) WebAuthnID() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(u.ID))
+}
+
+func (u *user) WebAuthnName() string {
+	return u.Email
+}
+
+func (u *user) WebAuthnDisplayName() string {
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+
+	return u.Email
+}
+
+func (u *user) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func (u *user) WebAuthnIcon() string {
+	return ""
+}
+
+func userIDFromHandle(handle []byte) (int64, error) {
+	if len(handle) != 8 {
+		return 0, fmt.Errorf("malformed user handle")
+	}
+
+	return int64(binary.BigEndian.Uint64(handle)), nil
+}
@@ -0,0 +1,346 @@
+package passkey
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/lib/jwt"
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "https://localhost"
+)
+
+// fakeAuth issues fixed tokens, the tests cover the WebAuthn ceremonies only.
+type fakeAuth struct{}
+
+func (fakeAuth) IssueTokens(ctx context.Context, user models.User, app models.App, amr []string, opts ...jwtn.Option) (string, string, error) {
+	return "access", "refresh", nil
+}
+
+// softAuthenticator is a software platform authenticator holding a single
+// discoverable P-256 credential. It verifies the user on every ceremony.
+type softAuthenticator struct {
+	t          *testing.T
+	origin     string
+	key        *ecdsa.PrivateKey
+	credID     []byte
+	userHandle []byte
+	signCount  uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	credID := make([]byte, 16)
+	if _, err = rand.Read(credID); err != nil {
+		t.Fatalf("generate credential ID: %v", err)
+	}
+
+	return &softAuthenticator{t: t, origin: testOrigin, key: key, credID: credID}
+}
+
+// options are the fields of the creation and request options the
+// authenticator uses.
+type options struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RPID      string `json:"rpId"`
+		RP        struct {
+			ID string `json:"id"`
+		} `json:"rp"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"publicKey"`
+}
+
+func (a *softAuthenticator) parseOptions(optionsJSON []byte) options {
+	a.t.Helper()
+
+	var opts options
+	if err := json.Unmarshal(optionsJSON, &opts); err != nil {
+		a.t.Fatalf("parse options: %v", err)
+	}
+
+	return opts
+}
+
+// create answers navigator.credentials.create() with none attestation.
+func (a *softAuthenticator) create(optionsJSON []byte) []byte {
+	a.t.Helper()
+
+	opts := a.parseOptions(optionsJSON)
+
+	handle, err := base64.RawURLEncoding.DecodeString(opts.PublicKey.User.ID)
+	if err != nil {
+		a.t.Fatalf("decode user handle: %v", err)
+	}
+	a.userHandle = handle
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatalf("encode public key: %v", err)
+	}
+
+	// attested credential data: AAGUID, credential ID length, ID and key
+	attested := make([]byte, 16)
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credID)))
+	attested = append(attested, a.credID...)
+	attested = append(attested, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(struct {
+		Fmt      string         `cbor:"fmt"`
+		AttStmt  map[string]any `cbor:"attStmt"`
+		AuthData []byte         `cbor:"authData"`
+	}{
+		Fmt:      "none",
+		AttStmt:  map[string]any{},
+		AuthData: a.authData(opts.PublicKey.RP.ID, 0x40, attested),
+	})
+	if err != nil {
+		a.t.Fatalf("encode attestation: %v", err)
+	}
+
+	return a.marshal(map[string]string{
+		"clientDataJSON":    a.encode(a.clientData("webauthn.create", opts.PublicKey.Challenge)),
+		"attestationObject": a.encode(attestation),
+	})
+}
+
+// get answers navigator.credentials.get(), counting the signature.
+func (a *softAuthenticator) get(optionsJSON []byte) []byte {
+	a.t.Helper()
+
+	opts := a.parseOptions(optionsJSON)
+
+	a.signCount++
+	authData := a.authData(opts.PublicKey.RPID, 0, nil)
+	clientData := a.clientData("webauthn.get", opts.PublicKey.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatalf("sign assertion: %v", err)
+	}
+
+	return a.marshal(map[string]string{
+		"clientDataJSON":    a.encode(clientData),
+		"authenticatorData": a.encode(authData),
+		"signature":         a.encode(signature),
+		"userHandle":        a.encode(a.userHandle),
+	})
+}
+
+// authData returns the authenticator data with the user present and
+// verified flags set in addition to flags.
+func (a *softAuthenticator) authData(rpID string, flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := append(rpIDHash[:], 0x01|0x04|flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(typ, challenge string) []byte {
+	a.t.Helper()
+
+	data, err := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	if err != nil {
+		a.t.Fatalf("encode client data: %v", err)
+	}
+
+	return data
+}
+
+func (a *softAuthenticator) marshal(response map[string]string) []byte {
+	a.t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"id":       a.encode(a.credID),
+		"rawId":    a.encode(a.credID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		a.t.Fatalf("encode credential: %v", err)
+	}
+
+	return data
+}
+
+func (a *softAuthenticator) encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+type testEnv struct {
+	service *Service
+	app     models.App
+	ctx     context.Context // authenticated as the user
+}
+
+func newTestEnv(t *testing.T) testEnv {
+	t.Helper()
+
+	ctx := context.Background()
+	s := memory.New()
+
+	app, err := s.SaveApp(ctx, models.App{Name: "app"})
+	if err != nil {
+		t.Fatalf("save app: %v", err)
+	}
+
+	userID, err := s.SaveUser(ctx, models.User{Email: "user@example.com", PasswordHash: []byte("hash")}, "123456", models.OutboxEmail{Recipient: "user@example.com"})
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	webAuthn, err := NewWebAuthn(testRPID, "Test", []string{testOrigin})
+	if err != nil {
+		t.Fatalf("webauthn: %v", err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return testEnv{
+		service: New(log, webAuthn, fakeAuth{}, s, s, s, s),
+		app:     app,
+		ctx:     jwtn.ContextWithPrincipal(ctx, models.Principal{UserID: userID, AppID: app.ID}),
+	}
+}
+
+func (e testEnv) register(t *testing.T, a *softAuthenticator) error {
+	t.Helper()
+
+	optionsJSON, sessionID, err := e.service.BeginRegistration(e.ctx, e.app.ID)
+	if err != nil {
+		t.Fatalf("BeginRegistration() error = %v", err)
+	}
+
+	_, err = e.service.FinishRegistration(e.ctx, e.app.ID, sessionID, a.create(optionsJSON), "laptop")
+	return err
+}
+
+func (e testEnv) login(t *testing.T, a *softAuthenticator) error {
+	t.Helper()
+
+	optionsJSON, sessionID, err := e.service.BeginLogin(context.Background(), e.app.ID)
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+
+	_, _, err = e.service.FinishLogin(context.Background(), e.app.ID, sessionID, a.get(optionsJSON))
+	return err
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	env := newTestEnv(t)
+	a := newSoftAuthenticator(t)
+
+	if err := env.register(t, a); err != nil {
+		t.Fatalf("FinishRegistration() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := env.login(t, a); err != nil {
+			t.Fatalf("FinishLogin() #%d error = %v", i+1, err)
+		}
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	env := newTestEnv(t)
+	a := newSoftAuthenticator(t)
+
+	if err := env.register(t, a); err != nil {
+		t.Fatalf("FinishRegistration() error = %v", err)
+	}
+
+	// the credential is excluded, a browser would refuse to create it again
+	if err := env.register(t, a); !errors.Is(err, ErrCredentialExists) {
+		t.Fatalf("FinishRegistration() error = %v, want %v", err, ErrCredentialExists)
+	}
+}
+
+func TestLoginSignCountRegression(t *testing.T) {
+	env := newTestEnv(t)
+	a := newSoftAuthenticator(t)
+
+	if err := env.register(t, a); err != nil {
+		t.Fatalf("FinishRegistration() error = %v", err)
+	}
+	if err := env.login(t, a); err != nil {
+		t.Fatalf("FinishLogin() error = %v", err)
+	}
+
+	// a clone of the authenticator signing with a counter it already used
+	clone := *a
+	clone.signCount = 0
+
+	if err := env.login(t, &clone); !errors.Is(err, ErrClonedAuthenticator) {
+		t.Fatalf("FinishLogin() error = %v, want %v", err, ErrClonedAuthenticator)
+	}
+}
+
+func TestWrongOrigin(t *testing.T) {
+	t.Run("registration", func(t *testing.T) {
+		env := newTestEnv(t)
+		a := newSoftAuthenticator(t)
+		a.origin = "https://evil.example"
+
+		if err := env.register(t, a); !errors.Is(err, ErrInvalidCredential) {
+			t.Fatalf("FinishRegistration() error = %v, want %v", err, ErrInvalidCredential)
+		}
+	})
+
+	t.Run("login", func(t *testing.T) {
+		env := newTestEnv(t)
+		a := newSoftAuthenticator(t)
+
+		if err := env.register(t, a); err != nil {
+			t.Fatalf("FinishRegistration() error = %v", err)
+		}
+
+		a.origin = "https://evil.example"
+
+		if err := env.login(t, a); !errors.Is(err, ErrInvalidCredential) {
+			t.Fatalf("FinishLogin() error = %v, want %v", err, ErrInvalidCredential)
+		}
+	})
+}
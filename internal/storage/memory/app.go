@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) App(ctx context.Context, id int64) (models.App, error) {
+	const op = "storage.memory.App"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	app, ok := s.apps[id]
+	if !ok {
+		return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	// the settings are loaded separately, like from the database
+	app.RedirectURIs = nil
+	app.Scopes = nil
+
+	return app, nil
+}
+
+func (s *Storage) AppRedirectURIs(ctx context.Context, appID int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return cloneStrings(s.apps[appID].RedirectURIs), nil
+}
+
+// AppScopes returns the scopes the app may request with the client credentials grant.
+func (s *Storage) AppScopes(ctx context.Context, appID int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return cloneStrings(s.apps[appID].Scopes), nil
+}
+
+// SaveApp creates the app with its redirect URIs and scopes.
+func (s *Storage) SaveApp(ctx context.Context, app models.App) (models.App, error) {
+	const op = "storage.memory.SaveApp"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.appNameTaken(app.Name, 0) {
+		return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+	}
+
+	app.ID = s.nextID("apps")
+	app.CreatedAt = now()
+	app.RedirectURIs = sortedSet(app.RedirectURIs)
+	app.Scopes = sortedSet(app.Scopes)
+	s.apps[app.ID] = cloneApp(app)
+
+	return app, nil
+}
+
+// Apps returns all apps with their redirect URIs and scopes ordered by ID.
+func (s *Storage) Apps(ctx context.Context) ([]models.App, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	apps := make([]models.App, 0, len(s.apps))
+	for _, app := range s.apps {
+		apps = append(apps, cloneApp(app))
+	}
+
+	slices.SortFunc(apps, func(a, b models.App) int { return cmp.Compare(a.ID, b.ID) })
+
+	return apps, nil
+}
+
+// UpdateApp replaces the metadata, redirect URIs and scopes of the app.
+func (s *Storage) UpdateApp(ctx context.Context, app models.App) error {
+	const op = "storage.memory.UpdateApp"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.apps[app.ID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	if s.appNameTaken(app.Name, app.ID) {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+	}
+
+	stored.Name = app.Name
+	stored.GrantTypes = app.GrantTypes
+	stored.TokenTTLSeconds = app.TokenTTLSeconds
+	stored.LogoURI = app.LogoURI
+	stored.RedirectURIs = sortedSet(app.RedirectURIs)
+	stored.Scopes = sortedSet(app.Scopes)
+	s.apps[app.ID] = stored
+
+	return nil
+}
+
+// SetAppSecret replaces the secret of the app. secret is the plain secret
+// kept for HS256 signing, empty to keep only the hash.
+func (s *Storage) SetAppSecret(ctx context.Context, appID int64, secret, secretHash string) error {
+	const op = "storage.memory.SetAppSecret"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.apps[appID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	app.Secret = secret
+	app.SecretHash = secretHash
+	s.apps[appID] = app
+
+	return nil
+}
+
+// ClearAppSecrets drops the plain secrets of all apps, which are only
+// needed as signing keys with HS256. The hashes are kept.
+func (s *Storage) ClearAppSecrets(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cleared int64
+	for id, app := range s.apps {
+		if app.Secret != "" {
+			app.Secret = ""
+			s.apps[id] = app
+			cleared++
+		}
+	}
+
+	return cleared, nil
+}
+
+// DeleteApp deletes the app with its sessions, codes, roles and permissions.
+func (s *Storage) DeleteApp(ctx context.Context, appID int64) error {
+	const op = "storage.memory.DeleteApp"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.apps[appID]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	delete(s.apps, appID)
+	deleteWhere(s.authCodes, func(c models.AuthorizationCode) bool { return c.AppID == appID })
+	deleteWhere(s.refreshTokens, func(t models.RefreshToken) bool { return t.AppID == appID })
+	deleteWhere(s.sessions, func(ws models.WebAuthnSession) bool { return ws.AppID == appID })
+	for id, role := range s.roles {
+		if role.AppID == appID {
+			s.deleteRole(id)
+		}
+	}
+	for id, perm := range s.permissions {
+		if perm.AppID == appID {
+			s.deletePermission(id)
+		}
+	}
+
+	return nil
+}
+
+// appNameTaken reports whether an app other than exceptID has the name.
+// The caller must hold the lock.
+func (s *Storage) appNameTaken(name string, exceptID int64) bool {
+	for _, app := range s.apps {
+		if app.Name == name && app.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func cloneApp(app models.App) models.App {
+	app.RedirectURIs = cloneStrings(app.RedirectURIs)
+	app.Scopes = cloneStrings(app.Scopes)
+	return app
+}
+
+// sortedSet returns the distinct values sorted, like rows read back from
+// a table with a unique key.
+func sortedSet(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	set := slices.Clone(values)
+	slices.Sort(set)
+	return slices.Compact(set)
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code.ID = s.nextID("authorization_codes")
+	code.AuthTime = code.AuthTime.UTC()
+	code.ExpiresAt = code.ExpiresAt.UTC()
+	code.UsedAt = nil
+	code.CreatedAt = now()
+	s.authCodes[code.ID] = code
+
+	return nil
+}
+
+func (s *Storage) AuthorizationCode(ctx context.Context, codeHash string) (models.AuthorizationCode, error) {
+	const op = "storage.memory.AuthorizationCode"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, code := range s.authCodes {
+		if code.CodeHash == codeHash {
+			code.UsedAt = clonePtr(code.UsedAt)
+			return code, nil
+		}
+	}
+
+	return models.AuthorizationCode{}, fmt.Errorf("%s: %w", op, storage.ErrAuthCodeNotFound)
+}
+
+// UseAuthorizationCode marks the code as used. Codes can be used only once,
+// storage.ErrAuthCodeUsed is returned for the second attempt.
+func (s *Storage) UseAuthorizationCode(ctx context.Context, id int64) error {
+	const op = "storage.memory.UseAuthorizationCode"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, ok := s.authCodes[id]
+	if !ok || code.UsedAt != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrAuthCodeUsed)
+	}
+
+	t := now()
+	code.UsedAt = &t
+	s.authCodes[id] = code
+
+	return nil
+}
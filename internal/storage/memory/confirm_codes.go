@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// SaveConfirmationCode saves the code and queues the email with it.
+func (s *Storage) SaveConfirmationCode(ctx context.Context, userID int64, code string, email models.OutboxEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveConfirmationCode(userID, code, email)
+
+	return nil
+}
+
+// saveConfirmationCode saves the code and queues the email. The caller must
+// hold the write lock.
+func (s *Storage) saveConfirmationCode(userID int64, code string, email models.OutboxEmail) {
+	id := s.nextID("email_confirmation")
+	s.confirmCodes[id] = models.ConfirmCode{
+		ID:        id,
+		UserID:    userID,
+		Code:      code,
+		CreatedAt: now(),
+	}
+
+	s.enqueueEmail(email)
+}
+
+// ConfirmationCode returns the latest confirmation code of the user.
+func (s *Storage) ConfirmationCode(ctx context.Context, userID int64) (confCodeModel models.ConfirmCode, err error) {
+	const op = "storage.memory.ConfirmationCode"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return models.ConfirmCode{}, fmt.Errorf("%s: %w", op, storage.ErrConfirmCodeNotFound)
+	}
+
+	var latest models.ConfirmCode
+	for _, code := range s.confirmCodes {
+		if code.UserID == userID && code.ID > latest.ID {
+			latest = code
+		}
+	}
+	if latest.ID == 0 {
+		return models.ConfirmCode{}, fmt.Errorf("%s: %w", op, storage.ErrConfirmCodeNotFound)
+	}
+
+	latest.Email = user.Email
+
+	return latest, nil
+}
+
+// DeleteConfirmationCode deletes the confirmation codes of the user.
+func (s *Storage) DeleteConfirmationCode(ctx context.Context, user_id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleteWhere(s.confirmCodes, func(c models.ConfirmCode) bool { return c.UserID == user_id })
+
+	return nil
+}
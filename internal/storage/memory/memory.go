@@ -0,0 +1,121 @@
+// Package memory is a storage keeping all data in memory, for tests and
+// throwaway local runs. It is safe for concurrent use and returns the same
+// errors as the database backends. The data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+type Storage struct {
+	mu sync.RWMutex
+
+	// lastIDs are the last IDs given out by table, like database sequences
+	lastIDs map[string]int64
+
+	users         map[int64]models.User
+	confirmCodes  map[int64]models.ConfirmCode
+	apps          map[int64]models.App
+	authCodes     map[int64]models.AuthorizationCode
+	refreshTokens map[int64]models.RefreshToken
+	// revokedTokens are the expiry times of revoked tokens by jti
+	revokedTokens   map[string]time.Time
+	signingKeys     map[int64]models.SigningKey
+	totps           map[int64]models.TOTP
+	recoveryCodes   map[int64]recoveryCode
+	credentials     map[int64]models.WebAuthnCredential
+	sessions        map[string]models.WebAuthnSession
+	outbox          map[int64]models.OutboxEmail
+	roles           map[int64]models.Role
+	permissions     map[int64]models.Permission
+	rolePermissions map[rolePermission]struct{}
+	userRoles       map[userRole]struct{}
+	tuples          map[int64]models.RelationTuple
+	orgs            map[int64]models.Organization
+	orgMembers      map[orgMemberKey]models.OrgMember
+	invitations     map[int64]models.OrgInvitation
+}
+
+func New() *Storage {
+	return &Storage{
+		lastIDs:         make(map[string]int64),
+		users:           make(map[int64]models.User),
+		confirmCodes:    make(map[int64]models.ConfirmCode),
+		apps:            make(map[int64]models.App),
+		authCodes:       make(map[int64]models.AuthorizationCode),
+		refreshTokens:   make(map[int64]models.RefreshToken),
+		revokedTokens:   make(map[string]time.Time),
+		signingKeys:     make(map[int64]models.SigningKey),
+		totps:           make(map[int64]models.TOTP),
+		recoveryCodes:   make(map[int64]recoveryCode),
+		credentials:     make(map[int64]models.WebAuthnCredential),
+		sessions:        make(map[string]models.WebAuthnSession),
+		outbox:          make(map[int64]models.OutboxEmail),
+		roles:           make(map[int64]models.Role),
+		permissions:     make(map[int64]models.Permission),
+		rolePermissions: make(map[rolePermission]struct{}),
+		userRoles:       make(map[userRole]struct{}),
+		tuples:          make(map[int64]models.RelationTuple),
+		orgs:            make(map[int64]models.Organization),
+		orgMembers:      make(map[orgMemberKey]models.OrgMember),
+		invitations:     make(map[int64]models.OrgInvitation),
+	}
+}
+
+// Stop does nothing, there is no connection to close.
+func (s *Storage) Stop() error {
+	return nil
+}
+
+// Ping always succeeds, the storage can not be unreachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	return nil
+}
+
+// nextID returns the next ID of the table. The caller must hold the write lock.
+func (s *Storage) nextID(table string) int64 {
+	s.lastIDs[table]++
+	return s.lastIDs[table]
+}
+
+// now is the current time as stored, in UTC.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// clonePtr copies the value p points to, so stored values are not changed
+// through pointers shared with callers.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// deleteWhere deletes the values matching from m, like cascading deletes.
+func deleteWhere[K comparable, V any](m map[K]V, match func(V) bool) {
+	for k, v := range m {
+		if match(v) {
+			delete(m, k)
+		}
+	}
+}
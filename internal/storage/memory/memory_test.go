@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/orenvadi/auth-grpc/internal/storage/memory"
+	"github.com/orenvadi/auth-grpc/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, memory.New())
+}
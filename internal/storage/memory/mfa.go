@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// recoveryCode is a one-time code for logins without the TOTP device.
+type recoveryCode struct {
+	userID   int64
+	codeHash string
+	usedAt   *time.Time
+}
+
+// SaveTOTP starts a TOTP enrollment, replacing an unconfirmed one.
+// storage.ErrTOTPAlreadyConfirmed is returned if the user already has TOTP enabled.
+func (s *Storage) SaveTOTP(ctx context.Context, userID int64, secret string) error {
+	const op = "storage.memory.SaveTOTP"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	if totp, ok := s.totps[userID]; ok && totp.ConfirmedAt != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrTOTPAlreadyConfirmed)
+	}
+
+	s.totps[userID] = models.TOTP{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now(),
+	}
+
+	return nil
+}
+
+func (s *Storage) TOTP(ctx context.Context, userID int64) (models.TOTP, error) {
+	const op = "storage.memory.TOTP"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totp, ok := s.totps[userID]
+	if !ok {
+		return models.TOTP{}, fmt.Errorf("%s: %w", op, storage.ErrTOTPNotFound)
+	}
+
+	totp.ConfirmedAt = clonePtr(totp.ConfirmedAt)
+
+	return totp, nil
+}
+
+// ConfirmTOTP enables TOTP of the user, marking step as used, and replaces
+// the recovery codes of the user at once.
+func (s *Storage) ConfirmTOTP(ctx context.Context, userID, step int64, recoveryCodeHashes []string) error {
+	const op = "storage.memory.ConfirmTOTP"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totp, ok := s.totps[userID]
+	if !ok || totp.ConfirmedAt != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrTOTPAlreadyConfirmed)
+	}
+
+	t := now()
+	totp.ConfirmedAt = &t
+	totp.LastUsedStep = step
+	s.totps[userID] = totp
+
+	deleteWhere(s.recoveryCodes, func(c recoveryCode) bool { return c.userID == userID })
+	for _, hash := range recoveryCodeHashes {
+		s.recoveryCodes[s.nextID("recovery_codes")] = recoveryCode{userID: userID, codeHash: hash}
+	}
+
+	return nil
+}
+
+// UseTOTPStep records that the code of step was used. Codes of the same or
+// earlier steps are rejected with storage.ErrTOTPStepUsed, so a code can not be replayed.
+func (s *Storage) UseTOTPStep(ctx context.Context, userID, step int64) error {
+	const op = "storage.memory.UseTOTPStep"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totp, ok := s.totps[userID]
+	if !ok || totp.ConfirmedAt == nil || totp.LastUsedStep >= step {
+		return fmt.Errorf("%s: %w", op, storage.ErrTOTPStepUsed)
+	}
+
+	totp.LastUsedStep = step
+	s.totps[userID] = totp
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the user as used.
+func (s *Storage) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	const op = "storage.memory.UseRecoveryCode"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, code := range s.recoveryCodes {
+		if code.userID == userID && code.codeHash == codeHash && code.usedAt == nil {
+			t := now()
+			code.usedAt = &t
+			s.recoveryCodes[id] = code
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: %w", op, storage.ErrRecoveryCodeNotFound)
+}
@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+type orgMemberKey struct {
+	orgID  int64
+	userID int64
+}
+
+// SaveOrganization creates an organization owned by the user of ownerID.
+func (s *Storage) SaveOrganization(ctx context.Context, name, slug string, ownerID int64) (models.Organization, error) {
+	const op = "storage.memory.SaveOrganization"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, org := range s.orgs {
+		if org.Slug == slug {
+			return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrOrgExists)
+		}
+	}
+	if _, ok := s.users[ownerID]; !ok {
+		return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	org := models.Organization{
+		ID:        s.nextID("organizations"),
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: now(),
+	}
+	s.orgs[org.ID] = org
+
+	s.orgMembers[orgMemberKey{orgID: org.ID, userID: ownerID}] = models.OrgMember{
+		OrgID:     org.ID,
+		UserID:    ownerID,
+		Role:      models.OrgRoleOwner,
+		CreatedAt: org.CreatedAt,
+	}
+
+	return org, nil
+}
+
+func (s *Storage) Organization(ctx context.Context, orgID int64) (models.Organization, error) {
+	const op = "storage.memory.Organization"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	org, ok := s.orgs[orgID]
+	if !ok {
+		return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrOrgNotFound)
+	}
+
+	return org, nil
+}
+
+func (s *Storage) OrgMember(ctx context.Context, orgID, userID int64) (models.OrgMember, error) {
+	const op = "storage.memory.OrgMember"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, ok := s.orgMembers[orgMemberKey{orgID: orgID, userID: userID}]
+	if !ok {
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, storage.ErrOrgMemberNotFound)
+	}
+
+	return s.fillOrgMember(member), nil
+}
+
+// OrgMembers returns the members of the organization.
+func (s *Storage) OrgMembers(ctx context.Context, orgID int64) ([]models.OrgMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var members []models.OrgMember
+	for key, member := range s.orgMembers {
+		if key.orgID == orgID {
+			members = append(members, s.fillOrgMember(member))
+		}
+	}
+
+	slices.SortFunc(members, func(a, b models.OrgMember) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return members, nil
+}
+
+// UserOrgMemberships returns the memberships of the user in all organizations.
+func (s *Storage) UserOrgMemberships(ctx context.Context, userID int64) ([]models.OrgMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var members []models.OrgMember
+	for key, member := range s.orgMembers {
+		if key.userID == userID {
+			members = append(members, s.fillOrgMember(member))
+		}
+	}
+
+	slices.SortFunc(members, func(a, b models.OrgMember) int {
+		return cmp.Or(cmp.Compare(a.OrgName, b.OrgName), cmp.Compare(a.OrgID, b.OrgID))
+	})
+
+	return members, nil
+}
+
+// RemoveOrgMember removes the user from the organization. The last owner can
+// not be removed, storage.ErrLastOrgOwner is returned instead.
+func (s *Storage) RemoveOrgMember(ctx context.Context, orgID, userID int64) error {
+	const op = "storage.memory.RemoveOrgMember"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := orgMemberKey{orgID: orgID, userID: userID}
+
+	member, ok := s.orgMembers[key]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrOrgMemberNotFound)
+	}
+
+	if member.Role == models.OrgRoleOwner {
+		owners := 0
+		for k, m := range s.orgMembers {
+			if k.orgID == orgID && m.Role == models.OrgRoleOwner {
+				owners++
+			}
+		}
+		if owners == 1 {
+			return fmt.Errorf("%s: %w", op, storage.ErrLastOrgOwner)
+		}
+	}
+
+	delete(s.orgMembers, key)
+
+	return nil
+}
+
+// SaveOrgInvitation saves the invitation and queues the email with its token.
+func (s *Storage) SaveOrgInvitation(ctx context.Context, invitation models.OrgInvitation, email models.OutboxEmail) (int64, error) {
+	const op = "storage.memory.SaveOrgInvitation"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orgs[invitation.OrgID]; !ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrOrgNotFound)
+	}
+
+	invitation.ID = s.nextID("organization_invitations")
+	invitation.InvitedBy = clonePtr(invitation.InvitedBy)
+	invitation.ExpiresAt = invitation.ExpiresAt.UTC()
+	invitation.AcceptedAt = nil
+	invitation.CreatedAt = now()
+	s.invitations[invitation.ID] = invitation
+
+	s.enqueueEmail(email)
+
+	return invitation.ID, nil
+}
+
+// OrgInvitation returns the pending invitation with the token hash.
+func (s *Storage) OrgInvitation(ctx context.Context, tokenHash string) (models.OrgInvitation, error) {
+	const op = "storage.memory.OrgInvitation"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, invitation := range s.invitations {
+		if invitation.TokenHash == tokenHash && invitation.AcceptedAt == nil {
+			invitation.InvitedBy = clonePtr(invitation.InvitedBy)
+			return invitation, nil
+		}
+	}
+
+	return models.OrgInvitation{}, fmt.Errorf("%s: %w", op, storage.ErrOrgInvitationNotFound)
+}
+
+// AcceptOrgInvitation marks the invitation accepted and makes the user a member
+// with its role. Users already being members keep their role.
+func (s *Storage) AcceptOrgInvitation(ctx context.Context, invitationID, userID int64) error {
+	const op = "storage.memory.AcceptOrgInvitation"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invitation, ok := s.invitations[invitationID]
+	if !ok || invitation.AcceptedAt != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrOrgInvitationNotFound)
+	}
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	t := now()
+	invitation.AcceptedAt = &t
+	s.invitations[invitationID] = invitation
+
+	key := orgMemberKey{orgID: invitation.OrgID, userID: userID}
+	if _, ok := s.orgMembers[key]; !ok {
+		s.orgMembers[key] = models.OrgMember{
+			OrgID:     invitation.OrgID,
+			UserID:    userID,
+			Role:      invitation.Role,
+			CreatedAt: t,
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpiredOrgInvitations deletes invitations expired before the given time.
+func (s *Storage) DeleteExpiredOrgInvitations(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleteWhere(s.invitations, func(inv models.OrgInvitation) bool {
+		return inv.AcceptedAt == nil && inv.ExpiresAt.Before(before)
+	})
+
+	return nil
+}
+
+// fillOrgMember adds the names joined in by the database backends.
+// The caller must hold the lock.
+func (s *Storage) fillOrgMember(member models.OrgMember) models.OrgMember {
+	member.OrgName = s.orgs[member.OrgID].Name
+	member.Email = s.users[member.UserID].Email
+	return member
+}
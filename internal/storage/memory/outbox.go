@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// enqueueEmail adds the email to the outbox. The caller must hold the write lock.
+func (s *Storage) enqueueEmail(email models.OutboxEmail) {
+	t := now()
+
+	id := s.nextID("email_outbox")
+	s.outbox[id] = models.OutboxEmail{
+		ID:            id,
+		Recipient:     email.Recipient,
+		Subject:       email.Subject,
+		HTML:          email.HTML,
+		Text:          email.Text,
+		Status:        models.OutboxPending,
+		NextAttemptAt: t,
+		CreatedAt:     t,
+	}
+}
+
+// ClaimOutboxEmails returns pending emails due for delivery and postpones them
+// by lease, so concurrent workers do not send them twice. Attempts are counted
+// when claimed, so an email crashing the worker ends up dead as well.
+func (s *Storage) ClaimOutboxEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+
+	var due []models.OutboxEmail
+	for _, email := range s.outbox {
+		if email.Status == models.OutboxPending && !email.NextAttemptAt.After(t) {
+			due = append(due, email)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b models.OutboxEmail) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].Attempts++
+		due[i].NextAttemptAt = t.Add(lease)
+		s.outbox[due[i].ID] = due[i]
+		due[i].SentAt = clonePtr(due[i].SentAt)
+	}
+
+	return due, nil
+}
+
+func (s *Storage) MarkOutboxEmailSent(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if email, ok := s.outbox[id]; ok {
+		t := now()
+		email.Status = models.OutboxSent
+		email.SentAt = &t
+		email.LastError = ""
+		s.outbox[id] = email
+	}
+
+	return nil
+}
+
+// MarkOutboxEmailFailed records a failed delivery. The email is retried at
+// nextAttemptAt, or never again if dead is set.
+func (s *Storage) MarkOutboxEmailFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if email, ok := s.outbox[id]; ok {
+		email.Status = models.OutboxPending
+		if dead {
+			email.Status = models.OutboxDead
+		}
+		email.LastError = lastError
+		email.NextAttemptAt = nextAttemptAt.UTC()
+		s.outbox[id] = email
+	}
+
+	return nil
+}
+
+// OutboxEmails lists the latest emails, of any status if status is empty.
+func (s *Storage) OutboxEmails(ctx context.Context, status string, limit int) ([]models.OutboxEmail, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var emails []models.OutboxEmail
+	for _, email := range s.outbox {
+		if status == "" || email.Status == status {
+			email.SentAt = clonePtr(email.SentAt)
+			emails = append(emails, email)
+		}
+	}
+
+	slices.SortFunc(emails, func(a, b models.OutboxEmail) int { return cmp.Compare(b.ID, a.ID) })
+	if len(emails) > limit {
+		emails = emails[:limit]
+	}
+
+	return emails, nil
+}
+
+// RetryOutboxEmail queues an email that was not sent for immediate delivery
+// with a fresh attempt budget.
+func (s *Storage) RetryOutboxEmail(ctx context.Context, id int64) error {
+	const op = "storage.memory.RetryOutboxEmail"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	email, ok := s.outbox[id]
+	if !ok || email.Status == models.OutboxSent {
+		return fmt.Errorf("%s: %w", op, storage.ErrOutboxEmailNotFound)
+	}
+
+	email.Status = models.OutboxPending
+	email.Attempts = 0
+	email.NextAttemptAt = now()
+	s.outbox[id] = email
+
+	return nil
+}
+
+// DeleteSentOutboxEmails drops emails sent before the given time,
+// they contain confirmation codes.
+func (s *Storage) DeleteSentOutboxEmails(ctx context.Context, sentBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, email := range s.outbox {
+		if email.Status == models.OutboxSent && email.SentAt != nil && email.SentAt.Before(sentBefore) {
+			delete(s.outbox, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,320 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+type rolePermission struct {
+	roleID       int64
+	permissionID int64
+}
+
+type userRole struct {
+	userID int64
+	roleID int64
+}
+
+func (s *Storage) SaveRole(ctx context.Context, appID int64, name, description string) (models.Role, error) {
+	const op = "storage.memory.SaveRole"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.apps[appID]; !ok {
+		return models.Role{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+	if _, ok := s.roleByName(appID, name); ok {
+		return models.Role{}, fmt.Errorf("%s: %w", op, storage.ErrRoleExists)
+	}
+
+	role := models.Role{
+		ID:          s.nextID("roles"),
+		AppID:       appID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now(),
+	}
+	s.roles[role.ID] = role
+
+	return role, nil
+}
+
+// DeleteRole deletes the role, unassigning it from all users.
+func (s *Storage) DeleteRole(ctx context.Context, appID int64, name string) error {
+	const op = "storage.memory.DeleteRole"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, ok := s.roleByName(appID, name)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrRoleNotFound)
+	}
+
+	s.deleteRole(role.ID)
+
+	return nil
+}
+
+// Roles returns the roles of the app together with the permissions they grant.
+func (s *Storage) Roles(ctx context.Context, appID int64) ([]models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var roles []models.Role
+	for _, role := range s.roles {
+		if role.AppID != appID {
+			continue
+		}
+
+		for key := range s.rolePermissions {
+			if key.roleID == role.ID {
+				role.Permissions = append(role.Permissions, s.permissions[key.permissionID].Name)
+			}
+		}
+		slices.Sort(role.Permissions)
+
+		roles = append(roles, role)
+	}
+
+	slices.SortFunc(roles, func(a, b models.Role) int { return cmp.Compare(a.Name, b.Name) })
+
+	return roles, nil
+}
+
+func (s *Storage) SavePermission(ctx context.Context, appID int64, name, description string) (models.Permission, error) {
+	const op = "storage.memory.SavePermission"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.apps[appID]; !ok {
+		return models.Permission{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+	if _, ok := s.permissionByName(appID, name); ok {
+		return models.Permission{}, fmt.Errorf("%s: %w", op, storage.ErrPermissionExists)
+	}
+
+	perm := models.Permission{
+		ID:          s.nextID("permissions"),
+		AppID:       appID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now(),
+	}
+	s.permissions[perm.ID] = perm
+
+	return perm, nil
+}
+
+// DeletePermission deletes the permission, revoking it from all roles.
+func (s *Storage) DeletePermission(ctx context.Context, appID int64, name string) error {
+	const op = "storage.memory.DeletePermission"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perm, ok := s.permissionByName(appID, name)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrPermissionNotFound)
+	}
+
+	s.deletePermission(perm.ID)
+
+	return nil
+}
+
+func (s *Storage) Permissions(ctx context.Context, appID int64) ([]models.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var perms []models.Permission
+	for _, perm := range s.permissions {
+		if perm.AppID == appID {
+			perms = append(perms, perm)
+		}
+	}
+
+	slices.SortFunc(perms, func(a, b models.Permission) int { return cmp.Compare(a.Name, b.Name) })
+
+	return perms, nil
+}
+
+// GrantPermission binds the permission to the role. Granting it again is a no-op.
+func (s *Storage) GrantPermission(ctx context.Context, appID int64, role, permission string) error {
+	const op = "storage.memory.GrantPermission"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.rolePermission(appID, role, permission)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.rolePermissions[key] = struct{}{}
+
+	return nil
+}
+
+// RevokePermission unbinds the permission from the role.
+func (s *Storage) RevokePermission(ctx context.Context, appID int64, role, permission string) error {
+	const op = "storage.memory.RevokePermission"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.rolePermission(appID, role, permission)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	delete(s.rolePermissions, key)
+
+	return nil
+}
+
+// AssignRole assigns the role of the app to the user. Assigning it again is a no-op.
+func (s *Storage) AssignRole(ctx context.Context, userID, appID int64, role string) error {
+	const op = "storage.memory.AssignRole"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.roleByName(appID, role)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrRoleNotFound)
+	}
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	s.userRoles[userRole{userID: userID, roleID: r.ID}] = struct{}{}
+
+	return nil
+}
+
+func (s *Storage) UnassignRole(ctx context.Context, userID, appID int64, role string) error {
+	const op = "storage.memory.UnassignRole"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.roleByName(appID, role)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrRoleNotFound)
+	}
+
+	delete(s.userRoles, userRole{userID: userID, roleID: r.ID})
+
+	return nil
+}
+
+// UserRoles returns the names of the roles of the user in the app.
+func (s *Storage) UserRoles(ctx context.Context, userID, appID int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roles := []string{}
+	for key := range s.userRoles {
+		if role := s.roles[key.roleID]; key.userID == userID && role.AppID == appID {
+			roles = append(roles, role.Name)
+		}
+	}
+
+	slices.Sort(roles)
+
+	return roles, nil
+}
+
+// UserPermissions returns the names of the permissions granted to the user
+// in the app by any of their roles.
+func (s *Storage) UserPermissions(ctx context.Context, userID, appID int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	perms := []string{}
+	for ur := range s.userRoles {
+		if ur.userID != userID || s.roles[ur.roleID].AppID != appID {
+			continue
+		}
+		for rp := range s.rolePermissions {
+			if rp.roleID == ur.roleID {
+				perms = append(perms, s.permissions[rp.permissionID].Name)
+			}
+		}
+	}
+
+	slices.Sort(perms)
+
+	return slices.Compact(perms), nil
+}
+
+// roleByName finds the role of the app. The caller must hold the lock.
+func (s *Storage) roleByName(appID int64, name string) (models.Role, bool) {
+	for _, role := range s.roles {
+		if role.AppID == appID && role.Name == name {
+			return role, true
+		}
+	}
+
+	return models.Role{}, false
+}
+
+// permissionByName finds the permission of the app. The caller must hold the lock.
+func (s *Storage) permissionByName(appID int64, name string) (models.Permission, bool) {
+	for _, perm := range s.permissions {
+		if perm.AppID == appID && perm.Name == name {
+			return perm, true
+		}
+	}
+
+	return models.Permission{}, false
+}
+
+func (s *Storage) rolePermission(appID int64, role, permission string) (rolePermission, error) {
+	r, ok := s.roleByName(appID, role)
+	if !ok {
+		return rolePermission{}, storage.ErrRoleNotFound
+	}
+
+	perm, ok := s.permissionByName(appID, permission)
+	if !ok {
+		return rolePermission{}, storage.ErrPermissionNotFound
+	}
+
+	return rolePermission{roleID: r.ID, permissionID: perm.ID}, nil
+}
+
+// deleteRole deletes the role with its grants and assignments.
+// The caller must hold the write lock.
+func (s *Storage) deleteRole(id int64) {
+	delete(s.roles, id)
+	for key := range s.rolePermissions {
+		if key.roleID == id {
+			delete(s.rolePermissions, key)
+		}
+	}
+	for key := range s.userRoles {
+		if key.roleID == id {
+			delete(s.userRoles, key)
+		}
+	}
+}
+
+// deletePermission deletes the permission with its grants.
+// The caller must hold the write lock.
+func (s *Storage) deletePermission(id int64) {
+	delete(s.permissions, id)
+	for key := range s.rolePermissions {
+		if key.permissionID == id {
+			delete(s.rolePermissions, key)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveRefreshToken(token)
+
+	return nil
+}
+
+func (s *Storage) RefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	const op = "storage.memory.RefreshToken"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return cloneRefreshToken(token), nil
+		}
+	}
+
+	return models.RefreshToken{}, fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotFound)
+}
+
+// RotateRefreshToken marks the token with oldID as used and saves its successor
+// at once. If the old token was used or revoked concurrently,
+// storage.ErrRefreshTokenNotActive is returned and nothing is saved.
+func (s *Storage) RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error {
+	const op = "storage.memory.RotateRefreshToken"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.refreshTokens[oldID]
+	if !ok || old.UsedAt != nil || old.RevokedAt != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotActive)
+	}
+
+	t := now()
+	old.UsedAt = &t
+	s.refreshTokens[oldID] = old
+
+	s.saveRefreshToken(next)
+
+	return nil
+}
+
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+
+	for id, token := range s.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = clonePtr(&t)
+			s.refreshTokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshTokens deletes refresh tokens expired before the given
+// time, used and revoked ones included.
+func (s *Storage) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleteWhere(s.refreshTokens, func(t models.RefreshToken) bool { return t.ExpiresAt.Before(before) })
+
+	return nil
+}
+
+// saveRefreshToken saves a new token. The caller must hold the write lock.
+func (s *Storage) saveRefreshToken(token models.RefreshToken) {
+	token.ID = s.nextID("refresh_tokens")
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.UsedAt = nil
+	token.RevokedAt = nil
+	token.CreatedAt = now()
+	s.refreshTokens[token.ID] = cloneRefreshToken(token)
+}
+
+func cloneRefreshToken(token models.RefreshToken) models.RefreshToken {
+	token.OrgID = clonePtr(token.OrgID)
+	token.UsedAt = clonePtr(token.UsedAt)
+	token.RevokedAt = clonePtr(token.RevokedAt)
+	return token
+}
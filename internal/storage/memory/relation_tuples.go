@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+// WriteRelationTuples inserts and deletes tuples at once. Inserting
+// an existing tuple or deleting a missing one is a no-op.
+func (s *Storage) WriteRelationTuples(ctx context.Context, writes, deletes []models.RelationTuple) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range deletes {
+		deleteWhere(s.tuples, func(stored models.RelationTuple) bool { return sameTuple(stored, t) })
+	}
+
+	for _, t := range writes {
+		if s.hasTuple(t) {
+			continue
+		}
+
+		t.ID = s.nextID("relation_tuples")
+		t.CreatedAt = now()
+		s.tuples[t.ID] = t
+	}
+
+	return nil
+}
+
+// RelationTuples returns the tuples of the object with the given relation.
+func (s *Storage) RelationTuples(ctx context.Context, namespace, objectID, relation string) ([]models.RelationTuple, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tuples []models.RelationTuple
+	for _, t := range s.tuples {
+		if t.Namespace == namespace && t.ObjectID == objectID && t.Relation == relation {
+			tuples = append(tuples, t)
+		}
+	}
+
+	slices.SortFunc(tuples, func(a, b models.RelationTuple) int { return cmp.Compare(a.ID, b.ID) })
+
+	return tuples, nil
+}
+
+// RelationObjectIDs returns the IDs of the objects of the namespace having any
+// tuples, the first limit of them ordered by ID.
+func (s *Storage) RelationObjectIDs(ctx context.Context, namespace string, limit int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	for _, t := range s.tuples {
+		if t.Namespace == namespace {
+			ids = append(ids, t.ObjectID)
+		}
+	}
+
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	return ids, nil
+}
+
+// hasTuple reports whether the tuple is stored. The caller must hold the lock.
+func (s *Storage) hasTuple(t models.RelationTuple) bool {
+	for _, stored := range s.tuples {
+		if sameTuple(stored, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameTuple compares tuples by their content, ignoring IDs and times.
+func sameTuple(a, b models.RelationTuple) bool {
+	return a.Namespace == b.Namespace && a.ObjectID == b.ObjectID && a.Relation == b.Relation &&
+		a.SubjectNamespace == b.SubjectNamespace && a.SubjectID == b.SubjectID && a.SubjectRelation == b.SubjectRelation
+}
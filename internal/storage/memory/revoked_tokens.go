@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+func (s *Storage) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.revokedTokens[jti]; !ok {
+		s.revokedTokens[jti] = expiresAt.UTC()
+	}
+
+	return nil
+}
+
+func (s *Storage) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, revoked := s.revokedTokens[jti]
+
+	return revoked, nil
+}
+
+// DeleteExpiredRevokedTokens removes revocation entries of tokens that have
+// expired anyway and returns how many were removed.
+func (s *Storage) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+
+	var deleted int64
+	for jti, expiresAt := range s.revokedTokens {
+		if expiresAt.Before(t) {
+			delete(s.revokedTokens, jti)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
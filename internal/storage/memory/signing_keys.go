@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// SaveSigningKey saves a new key. Kids are unique and there is at most one
+// pending key, storage.ErrSigningKeyExists is returned otherwise.
+func (s *Storage) SaveSigningKey(ctx context.Context, key models.SigningKey) error {
+	const op = "storage.memory.SaveSigningKey"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key.State == "" {
+		key.State = models.SigningKeyPending
+	}
+
+	for _, other := range s.signingKeys {
+		if other.KID == key.KID || (key.State == models.SigningKeyPending && other.State == models.SigningKeyPending) {
+			return fmt.Errorf("%s: %w", op, storage.ErrSigningKeyExists)
+		}
+	}
+
+	key.ID = s.nextID("signing_keys")
+	key.PrivateKey = cloneBytes(key.PrivateKey)
+	key.CreatedAt = now()
+	key.ActivatedAt = nil
+	key.RotatedAt = nil
+	key.RetiredAt = nil
+	s.signingKeys[key.ID] = key
+
+	return nil
+}
+
+// SigningKeys returns all signing keys, newest first.
+func (s *Storage) SigningKeys(ctx context.Context) ([]models.SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]models.SigningKey, 0, len(s.signingKeys))
+	for _, key := range s.signingKeys {
+		key.PrivateKey = cloneBytes(key.PrivateKey)
+		key.ActivatedAt = clonePtr(key.ActivatedAt)
+		key.RotatedAt = clonePtr(key.RotatedAt)
+		key.RetiredAt = clonePtr(key.RetiredAt)
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b models.SigningKey) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	return keys, nil
+}
+
+// ActivateSigningKey makes the pending key with the given kid the signing key.
+// The previous signing key stays active for verification, marked as rotated.
+func (s *Storage) ActivateSigningKey(ctx context.Context, kid string) error {
+	const op = "storage.memory.ActivateSigningKey"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending models.SigningKey
+	for _, key := range s.signingKeys {
+		if key.KID == kid && key.State == models.SigningKeyPending {
+			pending = key
+		}
+	}
+	if pending.ID == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSigningKeyNotFound)
+	}
+
+	t := now()
+
+	for id, key := range s.signingKeys {
+		if key.State == models.SigningKeyActive && key.RotatedAt == nil {
+			key.RotatedAt = clonePtr(&t)
+			s.signingKeys[id] = key
+		}
+	}
+
+	pending.State = models.SigningKeyActive
+	pending.ActivatedAt = &t
+	s.signingKeys[pending.ID] = pending
+
+	return nil
+}
+
+// RetireSigningKeys retires keys rotated out before the given time
+// and returns how many were retired.
+func (s *Storage) RetireSigningKeys(ctx context.Context, rotatedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+
+	var retired int64
+	for id, key := range s.signingKeys {
+		if key.State == models.SigningKeyActive && key.RotatedAt != nil && key.RotatedAt.Before(rotatedBefore) {
+			key.State = models.SigningKeyRetired
+			key.RetiredAt = clonePtr(&t)
+			s.signingKeys[id] = key
+			retired++
+		}
+	}
+
+	return retired, nil
+}
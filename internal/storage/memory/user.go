@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// SaveUser saves the user together with the email confirmation code and
+// queues the email with the code.
+func (s *Storage) SaveUser(ctx context.Context, user models.User, confirmCode string, confirmEmail models.OutboxEmail) (uid int64, err error) {
+	const op = "storage.memory.SaveUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userByEmail(user.Email); ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+	}
+
+	if user.Locale == "" {
+		user.Locale = "en"
+	}
+	user.ID = s.nextID("users")
+	user.CreatedAt = now()
+	user.UpdatedAt = user.CreatedAt
+	user.IsAdmin = false
+	user.IsEmailConfirmed = false
+	user.DisabledAt = nil
+	s.users[user.ID] = cloneUser(user)
+
+	s.saveConfirmationCode(user.ID, confirmCode, confirmEmail)
+
+	return user.ID, nil
+}
+
+func (s *Storage) User(ctx context.Context, email string) (models.User, error) {
+	const op = "storage.memory.User"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.userByEmail(email)
+	if !ok {
+		return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return cloneUser(user), nil
+}
+
+func (s *Storage) UserAllData(ctx context.Context, uid int64) (models.User, error) {
+	const op = "storage.memory.UserAllData"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[uid]
+	if !ok {
+		return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return cloneUser(user), nil
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, user models.User) error {
+	const op = "storage.memory.UpdateUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.users[user.ID]
+	if !ok {
+		return nil
+	}
+
+	if other, ok := s.userByEmail(user.Email); ok && other.ID != user.ID {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+	}
+
+	stored.FirstName = user.FirstName
+	stored.LastName = user.LastName
+	stored.PhoneNumber = user.PhoneNumber
+	stored.Email = user.Email
+	stored.Locale = user.Locale
+	s.users[user.ID] = stored
+
+	return nil
+}
+
+func (s *Storage) UserEmailConfirm(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.users[userID]; ok {
+		user.IsEmailConfirmed = true
+		s.users[userID] = user
+	}
+
+	return nil
+}
+
+// DeleteUser deletes the user with all their data.
+func (s *Storage) DeleteUser(ctx context.Context, userID int64) error {
+	const op = "storage.memory.DeleteUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	delete(s.users, userID)
+	delete(s.totps, userID)
+	deleteWhere(s.confirmCodes, func(c models.ConfirmCode) bool { return c.UserID == userID })
+	deleteWhere(s.authCodes, func(c models.AuthorizationCode) bool { return c.UserID == userID })
+	deleteWhere(s.refreshTokens, func(t models.RefreshToken) bool { return t.UserID == userID })
+	deleteWhere(s.recoveryCodes, func(c recoveryCode) bool { return c.userID == userID })
+	deleteWhere(s.credentials, func(c models.WebAuthnCredential) bool { return c.UserID == userID })
+	deleteWhere(s.sessions, func(ws models.WebAuthnSession) bool { return ws.UserID != nil && *ws.UserID == userID })
+	for key := range s.userRoles {
+		if key.userID == userID {
+			delete(s.userRoles, key)
+		}
+	}
+	for key := range s.orgMembers {
+		if key.userID == userID {
+			delete(s.orgMembers, key)
+		}
+	}
+	for id, inv := range s.invitations {
+		if inv.InvitedBy != nil && *inv.InvitedBy == userID {
+			inv.InvitedBy = nil
+			s.invitations[id] = inv
+		}
+	}
+
+	return nil
+}
+
+// Users returns the users matching the filter ordered by ID.
+func (s *Storage) Users(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix := strings.ToLower(filter.EmailPrefix)
+
+	var users []models.User
+	for _, user := range s.users {
+		switch {
+		case user.ID <= filter.AfterID,
+			!strings.HasPrefix(strings.ToLower(user.Email), prefix),
+			filter.Confirmed != nil && user.IsEmailConfirmed != *filter.Confirmed,
+			filter.Admin != nil && user.IsAdmin != *filter.Admin,
+			filter.CreatedAfter != nil && user.CreatedAt.Before(*filter.CreatedAfter),
+			filter.CreatedBefore != nil && !user.CreatedAt.Before(*filter.CreatedBefore):
+			continue
+		}
+		users = append(users, cloneUser(user))
+	}
+
+	slices.SortFunc(users, func(a, b models.User) int { return cmp.Compare(a.ID, b.ID) })
+
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+
+	return users, nil
+}
+
+// DisableUser disables the user and revokes the refresh tokens of all sessions.
+func (s *Storage) DisableUser(ctx context.Context, userID int64) error {
+	const op = "storage.memory.DisableUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	t := now()
+
+	if user.DisabledAt == nil {
+		user.DisabledAt = &t
+		s.users[userID] = user
+	}
+
+	for id, token := range s.refreshTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = clonePtr(&t)
+			s.refreshTokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) EnableUser(ctx context.Context, userID int64) error {
+	const op = "storage.memory.EnableUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	user.DisabledAt = nil
+	s.users[userID] = user
+
+	return nil
+}
+
+func (s *Storage) SetAdmin(ctx context.Context, userID int64, isAdmin bool) error {
+	const op = "storage.memory.SetAdmin"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	user.IsAdmin = isAdmin
+	s.users[userID] = user
+
+	return nil
+}
+
+func (s *Storage) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	const op = "storage.memory.IsAdmin"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	// disabled admins lose their permissions
+	return user.IsAdmin && user.DisabledAt == nil, nil
+}
+
+func (s *Storage) ChangePassword(ctx context.Context, email string, newPasswordHash []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.userByEmail(email); ok {
+		user.PasswordHash = cloneBytes(newPasswordHash)
+		s.users[user.ID] = user
+	}
+
+	return nil
+}
+
+func (s *Storage) IsEmailConfirmed(ctx context.Context, email string) (bool, error) {
+	const op = "storage.memory.IsEmailConfirmed"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.userByEmail(email)
+	if !ok {
+		return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return user.IsEmailConfirmed, nil
+}
+
+// userByEmail finds the user with the email. The caller must hold the lock.
+func (s *Storage) userByEmail(email string) (models.User, bool) {
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, true
+		}
+	}
+
+	return models.User{}, false
+}
+
+func cloneUser(user models.User) models.User {
+	user.PasswordHash = cloneBytes(user.PasswordHash)
+	user.DisabledAt = clonePtr(user.DisabledAt)
+	return user
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveWebAuthnCredential(ctx context.Context, cred models.WebAuthnCredential) error {
+	const op = "storage.memory.SaveWebAuthnCredential"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[cred.UserID]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	for _, other := range s.credentials {
+		if bytes.Equal(other.CredentialID, cred.CredentialID) {
+			return fmt.Errorf("%s: %w", op, storage.ErrCredentialExists)
+		}
+	}
+
+	cred.ID = s.nextID("webauthn_credentials")
+	cred.CreatedAt = now()
+	cred.LastUsedAt = nil
+	s.credentials[cred.ID] = cloneCredential(cred)
+
+	return nil
+}
+
+func (s *Storage) WebAuthnCredentials(ctx context.Context, userID int64) ([]models.WebAuthnCredential, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var creds []models.WebAuthnCredential
+	for _, cred := range s.credentials {
+		if cred.UserID == userID {
+			creds = append(creds, cloneCredential(cred))
+		}
+	}
+
+	slices.SortFunc(creds, func(a, b models.WebAuthnCredential) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return creds, nil
+}
+
+// UpdateWebAuthnCredential stores the credential data changed by a login,
+// i.e. the sign counter, and records its use.
+func (s *Storage) UpdateWebAuthnCredential(ctx context.Context, id int64, data []byte) error {
+	const op = "storage.memory.UpdateWebAuthnCredential"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cred, ok := s.credentials[id]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrCredentialNotFound)
+	}
+
+	t := now()
+	cred.Data = cloneBytes(data)
+	cred.LastUsedAt = &t
+	s.credentials[id] = cred
+
+	return nil
+}
+
+func (s *Storage) SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session.UserID = clonePtr(session.UserID)
+	session.Data = cloneBytes(session.Data)
+	session.ExpiresAt = session.ExpiresAt.UTC()
+	session.CreatedAt = now()
+	s.sessions[session.ID] = session
+
+	return nil
+}
+
+// TakeWebAuthnSession deletes and returns the session, so every challenge
+// can be answered only once.
+func (s *Storage) TakeWebAuthnSession(ctx context.Context, id string) (models.WebAuthnSession, error) {
+	const op = "storage.memory.TakeWebAuthnSession"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return models.WebAuthnSession{}, fmt.Errorf("%s: %w", op, storage.ErrWebAuthnSessionNotFound)
+	}
+
+	delete(s.sessions, id)
+
+	return session, nil
+}
+
+// DeleteExpiredWebAuthnSessions drops abandoned ceremonies.
+func (s *Storage) DeleteExpiredWebAuthnSessions(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+
+	var deleted int64
+	for id, session := range s.sessions {
+		if session.ExpiresAt.Before(t) {
+			delete(s.sessions, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func cloneCredential(cred models.WebAuthnCredential) models.WebAuthnCredential {
+	cred.CredentialID = cloneBytes(cred.CredentialID)
+	cred.Data = cloneBytes(cred.Data)
+	cred.LastUsedAt = clonePtr(cred.LastUsedAt)
+	return cred
+}
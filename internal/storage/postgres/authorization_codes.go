@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) error {
+	const op = "storage.postgres.SaveAuthorizationCode"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO authorization_codes(code_hash, app_id, user_id, redirect_uri, scope, nonce, code_challenge, code_challenge_method, auth_time, auth_methods, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, code.CodeHash, code.AppID, code.UserID, code.RedirectURI, code.Scope, code.Nonce,
+		code.CodeChallenge, code.CodeChallengeMethod, code.AuthTime.UTC(), code.AuthMethods, code.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) AuthorizationCode(ctx context.Context, codeHash string) (models.AuthorizationCode, error) {
+	const op = "storage.postgres.AuthorizationCode"
+
+	var code models.AuthorizationCode
+	err := s.db.GetContext(ctx, &code, `
+		SELECT id, code_hash, app_id, user_id, redirect_uri, scope, nonce, code_challenge, code_challenge_method, auth_time, auth_methods, expires_at, used_at, created_at
+		FROM authorization_codes
+		WHERE code_hash = $1
+	`, codeHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthorizationCode{}, fmt.Errorf("%s: %w", op, storage.ErrAuthCodeNotFound)
+		}
+		return models.AuthorizationCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+// UseAuthorizationCode marks the code as used. Codes can be used only once,
+// storage.ErrAuthCodeUsed is returned for the second attempt.
+func (s *Storage) UseAuthorizationCode(ctx context.Context, id int64) error {
+	const op = "storage.postgres.UseAuthorizationCode"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE authorization_codes
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL
+	`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAuthCodeUsed)
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// SaveOrganization creates an organization owned by the user of ownerID.
+func (s *Storage) SaveOrganization(ctx context.Context, name, slug string, ownerID int64) (models.Organization, error) {
+	const op = "storage.postgres.SaveOrganization"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var org models.Organization
+	err = tx.GetContext(ctx, &org, `
+		INSERT INTO organizations(name, slug)
+		VALUES($1, $2)
+		RETURNING id, name, slug, created_at
+	`, name, slug)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrOrgExists)
+		}
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members(org_id, user_id, role)
+		VALUES($1, $2, $3)
+	`, org.ID, ownerID, models.OrgRoleOwner)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return org, nil
+}
+
+func (s *Storage) Organization(ctx context.Context, orgID int64) (models.Organization, error) {
+	const op = "storage.postgres.Organization"
+
+	var org models.Organization
+	err := s.db.GetContext(ctx, &org, "SELECT id, name, slug, created_at FROM organizations WHERE id = $1", orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrOrgNotFound)
+		}
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return org, nil
+}
+
+const orgMemberColumns = `m.org_id, o.name AS org_name, m.user_id, u.email, m.role, m.created_at`
+
+const orgMemberJoins = `
+	FROM organization_members m
+	INNER JOIN organizations o ON m.org_id = o.id
+	INNER JOIN users u ON m.user_id = u.id
+`
+
+func (s *Storage) OrgMember(ctx context.Context, orgID, userID int64) (models.OrgMember, error) {
+	const op = "storage.postgres.OrgMember"
+
+	var member models.OrgMember
+	err := s.db.GetContext(ctx, &member, `SELECT `+orgMemberColumns+orgMemberJoins+`
+		WHERE m.org_id = $1 AND m.user_id = $2
+	`, orgID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrgMember{}, fmt.Errorf("%s: %w", op, storage.ErrOrgMemberNotFound)
+		}
+		return models.OrgMember{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return member, nil
+}
+
+// OrgMembers returns the members of the organization.
+func (s *Storage) OrgMembers(ctx context.Context, orgID int64) ([]models.OrgMember, error) {
+	const op = "storage.postgres.OrgMembers"
+
+	var members []models.OrgMember
+	err := s.db.SelectContext(ctx, &members, `SELECT `+orgMemberColumns+orgMemberJoins+`
+		WHERE m.org_id = $1
+		ORDER BY m.created_at, m.user_id
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// UserOrgMemberships returns the memberships of the user in all organizations.
+func (s *Storage) UserOrgMemberships(ctx context.Context, userID int64) ([]models.OrgMember, error) {
+	const op = "storage.postgres.UserOrgMemberships"
+
+	var members []models.OrgMember
+	err := s.db.SelectContext(ctx, &members, `SELECT `+orgMemberColumns+orgMemberJoins+`
+		WHERE m.user_id = $1
+		ORDER BY o.name, m.org_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// RemoveOrgMember removes the user from the organization. The last owner can
+// not be removed, storage.ErrLastOrgOwner is returned instead.
+func (s *Storage) RemoveOrgMember(ctx context.Context, orgID, userID int64) error {
+	const op = "storage.postgres.RemoveOrgMember"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// lock the memberships, so concurrent removals can not remove all owners
+	var roles []string
+	err = tx.SelectContext(ctx, &roles, `
+		SELECT role FROM organization_members WHERE org_id = $1 AND role = $2 FOR UPDATE
+	`, orgID, models.OrgRoleOwner)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var role string
+	err = tx.GetContext(ctx, &role, `
+		DELETE FROM organization_members
+		WHERE org_id = $1 AND user_id = $2
+		RETURNING role
+	`, orgID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrOrgMemberNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if role == models.OrgRoleOwner && len(roles) == 1 {
+		return fmt.Errorf("%s: %w", op, storage.ErrLastOrgOwner)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SaveOrgInvitation saves the invitation and queues the email with its token
+// in one transaction.
+func (s *Storage) SaveOrgInvitation(ctx context.Context, invitation models.OrgInvitation, email models.OutboxEmail) (int64, error) {
+	const op = "storage.postgres.SaveOrgInvitation"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.GetContext(ctx, &id, `
+		INSERT INTO organization_invitations(org_id, email, role, token_hash, invited_by, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, invitation.OrgID, invitation.Email, invitation.Role, invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrOrgNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = enqueueEmail(ctx, tx, email); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// OrgInvitation returns the pending invitation with the token hash.
+func (s *Storage) OrgInvitation(ctx context.Context, tokenHash string) (models.OrgInvitation, error) {
+	const op = "storage.postgres.OrgInvitation"
+
+	var invitation models.OrgInvitation
+	err := s.db.GetContext(ctx, &invitation, `
+		SELECT id, org_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM organization_invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL
+	`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrgInvitation{}, fmt.Errorf("%s: %w", op, storage.ErrOrgInvitationNotFound)
+		}
+		return models.OrgInvitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invitation, nil
+}
+
+// AcceptOrgInvitation marks the invitation accepted and makes the user a member
+// with its role. Users already being members keep their role.
+func (s *Storage) AcceptOrgInvitation(ctx context.Context, invitationID, userID int64) error {
+	const op = "storage.postgres.AcceptOrgInvitation"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var invitation models.OrgInvitation
+	err = tx.GetContext(ctx, &invitation, `
+		UPDATE organization_invitations
+		SET accepted_at = $1
+		WHERE id = $2 AND accepted_at IS NULL
+		RETURNING org_id, role
+	`, time.Now().UTC(), invitationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrOrgInvitationNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members(org_id, user_id, role)
+		VALUES($1, $2, $3)
+		ON CONFLICT (org_id, user_id) DO NOTHING
+	`, invitation.OrgID, userID, invitation.Role)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredOrgInvitations deletes invitations expired before the given time.
+func (s *Storage) DeleteExpiredOrgInvitations(ctx context.Context, before time.Time) error {
+	const op = "storage.postgres.DeleteExpiredOrgInvitations"
+
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM organization_invitations WHERE expires_at < $1 AND accepted_at IS NULL
+	`, before.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
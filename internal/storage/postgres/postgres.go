@@ -1,13 +1,17 @@
 package postgres
 
 import (
-	// "context"
-	// "database/sql"
+	"context"
+	"database/sql"
+	"errors"
 	// "fmt"
 	// "time"
 
+	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgconn"
 	_ "github.com/jackc/pgx/v4/stdlib"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	// "github.com/orenvadi/auth-grpc/internal/domain/models"
 	// "github.com/orenvadi/auth-grpc/internal/storage"
 )
@@ -16,15 +20,47 @@ type Storage struct {
 	db *sqlx.DB
 }
 
+// New connects to the database, every query is traced with a span.
 func New(dsn string) (*Storage, error) {
-	db, err := sqlx.Connect("pgx", dsn)
+	sqlDB, err := otelsql.Open("pgx", dsn, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
 	if err != nil {
 		return nil, err
 	}
 
+	db := sqlx.NewDb(sqlDB, "pgx")
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
 	return &Storage{db: db}, nil
 }
 
 func (s *Storage) Stop() error {
 	return s.db.Close()
 }
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}
+
+// Ping checks that the database is reachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Stats returns the statistics of the connection pool.
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
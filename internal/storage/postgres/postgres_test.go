@@ -0,0 +1,45 @@
+package postgres_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"github.com/orenvadi/auth-grpc/internal/storage/postgres"
+	"github.com/orenvadi/auth-grpc/internal/storage/storagetest"
+)
+
+const migrationsPath = "../../../migrations/postgres"
+
+// TestStorage runs against the database of STORAGETEST_POSTGRES_DSN, e.g.
+// postgres:postgres@localhost/elif_grpc_test. The database is migrated and
+// keeps the test users, so it should be a throwaway one.
+func TestStorage(t *testing.T) {
+	dsn := os.Getenv("STORAGETEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("STORAGETEST_POSTGRES_DSN is not set")
+	}
+
+	m, err := migrate.New("file://"+migrationsPath, fmt.Sprintf("postgres://%s?x-migrations-table=migrations&sslmode=disable", dsn))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	defer m.Close()
+
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	s, err := postgres.New(fmt.Sprintf("postgres://%s?sslmode=disable", dsn))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { s.Stop() })
+
+	storagetest.Run(t, s)
+}
@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveRole(ctx context.Context, appID int64, name, description string) (models.Role, error) {
+	const op = "storage.postgres.SaveRole"
+
+	var role models.Role
+	err := s.db.GetContext(ctx, &role, `
+		INSERT INTO roles(app_id, name, description)
+		VALUES($1, $2, $3)
+		RETURNING id, app_id, name, description, created_at
+	`, appID, name, description)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.Role{}, fmt.Errorf("%s: %w", op, storage.ErrRoleExists)
+		case isForeignKeyViolation(err):
+			return models.Role{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+		}
+		return models.Role{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes the role, unassigning it from all users.
+func (s *Storage) DeleteRole(ctx context.Context, appID int64, name string) error {
+	const op = "storage.postgres.DeleteRole"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM roles WHERE app_id = $1 AND name = $2", appID, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRoleNotFound)
+	}
+
+	return nil
+}
+
+// Roles returns the roles of the app together with the permissions they grant.
+func (s *Storage) Roles(ctx context.Context, appID int64) ([]models.Role, error) {
+	const op = "storage.postgres.Roles"
+
+	var roles []models.Role
+	err := s.db.SelectContext(ctx, &roles, `
+		SELECT id, app_id, name, description, created_at
+		FROM roles
+		WHERE app_id = $1
+		ORDER BY name
+	`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var grants []struct {
+		RoleID     int64  `db:"role_id"`
+		Permission string `db:"name"`
+	}
+	err = s.db.SelectContext(ctx, &grants, `
+		SELECT rp.role_id, p.name
+		FROM role_permissions rp
+		INNER JOIN permissions p ON rp.permission_id = p.id
+		WHERE p.app_id = $1
+		ORDER BY p.name
+	`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byID := make(map[int64]*models.Role, len(roles))
+	for i := range roles {
+		byID[roles[i].ID] = &roles[i]
+	}
+	for _, g := range grants {
+		if role, ok := byID[g.RoleID]; ok {
+			role.Permissions = append(role.Permissions, g.Permission)
+		}
+	}
+
+	return roles, nil
+}
+
+func (s *Storage) SavePermission(ctx context.Context, appID int64, name, description string) (models.Permission, error) {
+	const op = "storage.postgres.SavePermission"
+
+	var perm models.Permission
+	err := s.db.GetContext(ctx, &perm, `
+		INSERT INTO permissions(app_id, name, description)
+		VALUES($1, $2, $3)
+		RETURNING id, app_id, name, description, created_at
+	`, appID, name, description)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.Permission{}, fmt.Errorf("%s: %w", op, storage.ErrPermissionExists)
+		case isForeignKeyViolation(err):
+			return models.Permission{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+		}
+		return models.Permission{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return perm, nil
+}
+
+// DeletePermission deletes the permission, revoking it from all roles.
+func (s *Storage) DeletePermission(ctx context.Context, appID int64, name string) error {
+	const op = "storage.postgres.DeletePermission"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM permissions WHERE app_id = $1 AND name = $2", appID, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrPermissionNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) Permissions(ctx context.Context, appID int64) ([]models.Permission, error) {
+	const op = "storage.postgres.Permissions"
+
+	var perms []models.Permission
+	err := s.db.SelectContext(ctx, &perms, `
+		SELECT id, app_id, name, description, created_at
+		FROM permissions
+		WHERE app_id = $1
+		ORDER BY name
+	`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return perms, nil
+}
+
+// GrantPermission binds the permission to the role. Granting it again is a no-op.
+func (s *Storage) GrantPermission(ctx context.Context, appID int64, role, permission string) error {
+	const op = "storage.postgres.GrantPermission"
+
+	roleID, permID, err := s.roleAndPermissionIDs(ctx, appID, role, permission)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO role_permissions(role_id, permission_id)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING
+	`, roleID, permID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			// deleted concurrently
+			return fmt.Errorf("%s: %w", op, storage.ErrRoleNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokePermission unbinds the permission from the role.
+func (s *Storage) RevokePermission(ctx context.Context, appID int64, role, permission string) error {
+	const op = "storage.postgres.RevokePermission"
+
+	roleID, permID, err := s.roleAndPermissionIDs(ctx, appID, role, permission)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, "DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2", roleID, permID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AssignRole assigns the role of the app to the user. Assigning it again is a no-op.
+func (s *Storage) AssignRole(ctx context.Context, userID, appID int64, role string) error {
+	const op = "storage.postgres.AssignRole"
+
+	roleID, err := s.roleID(ctx, appID, role)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO user_roles(user_id, role_id)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING
+	`, userID, roleID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) UnassignRole(ctx context.Context, userID, appID int64, role string) error {
+	const op = "storage.postgres.UnassignRole"
+
+	roleID, err := s.roleID(ctx, appID, role)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, "DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2", userID, roleID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UserRoles returns the names of the roles of the user in the app.
+func (s *Storage) UserRoles(ctx context.Context, userID, appID int64) ([]string, error) {
+	const op = "storage.postgres.UserRoles"
+
+	roles := []string{}
+	err := s.db.SelectContext(ctx, &roles, `
+		SELECT r.name
+		FROM user_roles ur
+		INNER JOIN roles r ON ur.role_id = r.id
+		WHERE ur.user_id = $1 AND r.app_id = $2
+		ORDER BY r.name
+	`, userID, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+// UserPermissions returns the names of the permissions granted to the user
+// in the app by any of their roles.
+func (s *Storage) UserPermissions(ctx context.Context, userID, appID int64) ([]string, error) {
+	const op = "storage.postgres.UserPermissions"
+
+	perms := []string{}
+	err := s.db.SelectContext(ctx, &perms, `
+		SELECT DISTINCT p.name
+		FROM user_roles ur
+		INNER JOIN roles r ON ur.role_id = r.id
+		INNER JOIN role_permissions rp ON rp.role_id = r.id
+		INNER JOIN permissions p ON rp.permission_id = p.id
+		WHERE ur.user_id = $1 AND r.app_id = $2
+		ORDER BY p.name
+	`, userID, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return perms, nil
+}
+
+func (s *Storage) roleID(ctx context.Context, appID int64, role string) (int64, error) {
+	var id int64
+	err := s.db.GetContext(ctx, &id, "SELECT id FROM roles WHERE app_id = $1 AND name = $2", appID, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrRoleNotFound
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *Storage) roleAndPermissionIDs(ctx context.Context, appID int64, role, permission string) (roleID, permID int64, err error) {
+	if roleID, err = s.roleID(ctx, appID, role); err != nil {
+		return 0, 0, err
+	}
+
+	err = s.db.GetContext(ctx, &permID, "SELECT id FROM permissions WHERE app_id = $1 AND name = $2", appID, permission)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, storage.ErrPermissionNotFound
+		}
+		return 0, 0, err
+	}
+
+	return roleID, permID, nil
+}
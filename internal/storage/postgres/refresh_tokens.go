@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	const op = "storage.postgres.SaveRefreshToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens(user_id, app_id, family_id, token_hash, auth_methods, org_id, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`, token.UserID, token.AppID, token.FamilyID, token.TokenHash, token.AuthMethods, token.OrgID, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	const op = "storage.postgres.RefreshToken"
+
+	var token models.RefreshToken
+	err := s.db.GetContext(ctx, &token, `
+		SELECT id, user_id, app_id, family_id, token_hash, auth_methods, org_id, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshToken{}, fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotFound)
+		}
+		return models.RefreshToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken marks the token with oldID as used and saves its successor
+// in one transaction. If the old token was used or revoked concurrently,
+// storage.ErrRefreshTokenNotActive is returned and nothing is saved.
+func (s *Storage) RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error {
+	const op = "storage.postgres.RotateRefreshToken"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL
+	`, time.Now().UTC(), oldID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotActive)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens(user_id, app_id, family_id, token_hash, auth_methods, org_id, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`, next.UserID, next.AppID, next.FamilyID, next.TokenHash, next.AuthMethods, next.OrgID, next.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	const op = "storage.postgres.RevokeRefreshTokenFamily"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL
+	`, time.Now().UTC(), familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshTokens deletes refresh tokens expired before the given
+// time, used and revoked ones included.
+func (s *Storage) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) error {
+	const op = "storage.postgres.DeleteExpiredRefreshTokens"
+
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM refresh_tokens WHERE expires_at < $1
+	`, before.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
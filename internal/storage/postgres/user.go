@@ -5,32 +5,55 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/orenvadi/auth-grpc/internal/domain/models"
 	"github.com/orenvadi/auth-grpc/internal/storage"
 )
 
-func (s *Storage) SaveUser(ctx context.Context, firstName, lastName, phoneNumber, email string, passwordHash []byte) (uid int64, err error) {
+// SaveUser saves the user together with the email confirmation code and
+// queues the email with the code in one transaction.
+func (s *Storage) SaveUser(ctx context.Context, user models.User, confirmCode string, confirmEmail models.OutboxEmail) (uid int64, err error) {
 	const op = "storage.postgres.SaveUser"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
 	var id int64
-	err = s.db.QueryRowContext(ctx, `
-		INSERT INTO users(first_name, last_name, phone_number, created_at, updated_at, email, pass_hash)
-		VALUES($1, $2, $3, $4, $5, $6, $7)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users(first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, locale)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
-	`, firstName, lastName, phoneNumber, time.Now(), time.Now(), email, passwordHash).Scan(&id)
+	`, user.FirstName, user.LastName, user.PhoneNumber, time.Now(), time.Now(), user.Email, user.PasswordHash, user.Locale).Scan(&id)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = saveConfirmationCode(ctx, tx, id, confirmCode, confirmEmail); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return id, nil
 }
 
+const userColumns = "id, first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, is_admin, is_email_confirmed, locale, disabled_at"
+
 func (s *Storage) User(ctx context.Context, email string) (models.User, error) {
 	const op = "storage.postgres.User"
 
 	var user models.User
-	err := s.db.GetContext(ctx, &user, "SELECT id, email, pass_hash FROM users WHERE email = $1", email)
+	err := s.db.GetContext(ctx, &user, "SELECT id, first_name, email, pass_hash, locale, disabled_at FROM users WHERE email = $1", email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
@@ -45,7 +68,7 @@ func (s *Storage) UserAllData(ctx context.Context, uid int64) (models.User, erro
 	const op = "storage.postgres.User"
 
 	var user models.User
-	err := s.db.GetContext(ctx, &user, "SELECT id, first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, is_admin, is_email_confirmed FROM users WHERE id = $1", uid)
+	err := s.db.GetContext(ctx, &user, "SELECT "+userColumns+" FROM users WHERE id = $1", uid)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, fmt.Errorf("%s: %w, user = %v", op, storage.ErrUserNotFound, user)
@@ -61,10 +84,13 @@ func (s *Storage) UpdateUser(ctx context.Context, user models.User) error {
 
 	_, err := s.db.ExecContext(ctx, `
 		UPDATE users
-		SET first_name = $1, last_name = $2, phone_number = $3, email = $4 
-		WHERE id = $5
-	`, user.FirstName, user.LastName, user.PhoneNumber, user.Email, user.ID)
+		SET first_name = $1, last_name = $2, phone_number = $3, email = $4, locale = $5
+		WHERE id = $6
+	`, user.FirstName, user.LastName, user.PhoneNumber, user.Email, user.Locale, user.ID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -89,19 +115,135 @@ func (s *Storage) UserEmailConfirm(ctx context.Context, userID int64) error {
 func (s *Storage) DeleteUser(ctx context.Context, userID int64) error {
 	const op = "storage.postgres.DeleteUser"
 
-	_, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID)
+	res, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	return userAffected(op, res)
+}
+
+// Users returns the users matching the filter ordered by ID.
+func (s *Storage) Users(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	const op = "storage.postgres.Users"
+
+	query := "SELECT " + userColumns + " FROM users WHERE id > $1"
+	args := []any{filter.AfterID}
+
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+cond, len(args))
+	}
+
+	if filter.EmailPrefix != "" {
+		where(`lower(email) LIKE $%d ESCAPE '\'`, escapeLike(strings.ToLower(filter.EmailPrefix))+"%")
+	}
+	if filter.Confirmed != nil {
+		where("is_email_confirmed = $%d", *filter.Confirmed)
+	}
+	if filter.Admin != nil {
+		where("is_admin = $%d", *filter.Admin)
+	}
+	if filter.CreatedAfter != nil {
+		where("created_at >= $%d", filter.CreatedAfter.UTC())
+	}
+	if filter.CreatedBefore != nil {
+		where("created_at < $%d", filter.CreatedBefore.UTC())
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args))
+
+	var users []models.User
+	if err := s.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// DisableUser disables the user and revokes the refresh tokens of all sessions.
+func (s *Storage) DisableUser(ctx context.Context, userID int64) error {
+	const op = "storage.postgres.DisableUser"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET disabled_at = COALESCE(disabled_at, $1) WHERE id = $2", now, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = userAffected(op, res); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) EnableUser(ctx context.Context, userID int64) error {
+	const op = "storage.postgres.EnableUser"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET disabled_at = NULL WHERE id = $1", userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+func (s *Storage) SetAdmin(ctx context.Context, userID int64, isAdmin bool) error {
+	const op = "storage.postgres.SetAdmin"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET is_admin = $1 WHERE id = $2", isAdmin, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+// userAffected returns storage.ErrUserNotFound if the statement changed no user.
+func userAffected(op string, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
 	return nil
 }
 
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func (s *Storage) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	const op = "storage.postgres.IsAdmin"
 
 	var isAdmin bool
-	err := s.db.GetContext(ctx, &isAdmin, "SELECT is_admin FROM users WHERE id = $1", userID)
+	// disabled admins lose their permissions
+	err := s.db.GetContext(ctx, &isAdmin, "SELECT is_admin AND disabled_at IS NULL FROM users WHERE id = $1", userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
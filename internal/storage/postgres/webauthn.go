@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveWebAuthnCredential(ctx context.Context, cred models.WebAuthnCredential) error {
+	const op = "storage.postgres.SaveWebAuthnCredential"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webauthn_credentials(user_id, credential_id, name, data)
+		VALUES($1, $2, $3, $4)
+	`, cred.UserID, cred.CredentialID, cred.Name, cred.Data)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrCredentialExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) WebAuthnCredentials(ctx context.Context, userID int64) ([]models.WebAuthnCredential, error) {
+	const op = "storage.postgres.WebAuthnCredentials"
+
+	var creds []models.WebAuthnCredential
+	err := s.db.SelectContext(ctx, &creds, `
+		SELECT id, user_id, credential_id, name, data, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return creds, nil
+}
+
+// UpdateWebAuthnCredential stores the credential data changed by a login,
+// i.e. the sign counter, and records its use.
+func (s *Storage) UpdateWebAuthnCredential(ctx context.Context, id int64, data []byte) error {
+	const op = "storage.postgres.UpdateWebAuthnCredential"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE webauthn_credentials
+		SET data = $1, last_used_at = $2
+		WHERE id = $3
+	`, data, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrCredentialNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) error {
+	const op = "storage.postgres.SaveWebAuthnSession"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webauthn_sessions(id, user_id, app_id, kind, data, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+	`, session.ID, session.UserID, session.AppID, session.Kind, session.Data, session.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// TakeWebAuthnSession deletes and returns the session, so every challenge
+// can be answered only once.
+func (s *Storage) TakeWebAuthnSession(ctx context.Context, id string) (models.WebAuthnSession, error) {
+	const op = "storage.postgres.TakeWebAuthnSession"
+
+	var session models.WebAuthnSession
+	err := s.db.GetContext(ctx, &session, `
+		DELETE FROM webauthn_sessions
+		WHERE id = $1
+		RETURNING id, user_id, app_id, kind, data, expires_at, created_at
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebAuthnSession{}, fmt.Errorf("%s: %w", op, storage.ErrWebAuthnSessionNotFound)
+		}
+		return models.WebAuthnSession{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session, nil
+}
+
+// DeleteExpiredWebAuthnSessions drops abandoned ceremonies.
+func (s *Storage) DeleteExpiredWebAuthnSessions(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.DeleteExpiredWebAuthnSessions"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM webauthn_sessions WHERE expires_at < $1", time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
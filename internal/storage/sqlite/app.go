@@ -0,0 +1,285 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+const appColumns = `id, name, COALESCE(secret, '') AS secret, secret_hash, grant_types, token_ttl_seconds, logo_uri, public, created_at`
+
+func (s *Storage) App(ctx context.Context, id int64) (models.App, error) {
+	const op = "storage.sqlite.App"
+
+	var app models.App
+	err := s.db.GetContext(ctx, &app, "SELECT "+appColumns+" FROM apps WHERE id = ?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+		}
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return app, nil
+}
+
+// func (s *Storage) AppSecret(ctx context.Context, appID int) (string, error) {
+// 	const op = "storage.sqlite.AppSecret"
+
+// 	var secret string
+// 	err := s.db.GetContext(ctx, &secret, "SELECT secret FROM apps WHERE id = ?", appID)
+// 	if err != nil {
+// 		if errors.Is(err, sql.ErrNoRows) {
+// 			return "", fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+// 		}
+// 		return "", fmt.Errorf("%s: %w", op, err)
+// 	}
+
+// 	return secret, nil
+// }
+
+func (s *Storage) AppRedirectURIs(ctx context.Context, appID int64) ([]string, error) {
+	const op = "storage.sqlite.AppRedirectURIs"
+
+	var uris []string
+	err := s.db.SelectContext(ctx, &uris, "SELECT uri FROM app_redirect_uris WHERE app_id = ? ORDER BY uri", appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return uris, nil
+}
+
+// AppScopes returns the scopes the app may request with the client credentials grant.
+func (s *Storage) AppScopes(ctx context.Context, appID int64) ([]string, error) {
+	const op = "storage.sqlite.AppScopes"
+
+	var scopes []string
+	err := s.db.SelectContext(ctx, &scopes, "SELECT scope FROM app_scopes WHERE app_id = ? ORDER BY scope", appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return scopes, nil
+}
+
+// SaveApp creates the app with its redirect URIs and scopes.
+func (s *Storage) SaveApp(ctx context.Context, app models.App) (models.App, error) {
+	const op = "storage.sqlite.SaveApp"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO apps(name, secret, secret_hash, grant_types, token_ttl_seconds, logo_uri, public)
+		VALUES(?, NULLIF(?, ''), ?, ?, ?, ?, ?)
+		RETURNING id, created_at
+	`, app.Name, app.Secret, app.SecretHash, app.GrantTypes, app.TokenTTLSeconds, app.LogoURI, app.Public).Scan(&app.ID, &app.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+		}
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = saveAppSettings(ctx, tx, app); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return app, nil
+}
+
+// Apps returns all apps with their redirect URIs and scopes ordered by ID.
+func (s *Storage) Apps(ctx context.Context) ([]models.App, error) {
+	const op = "storage.sqlite.Apps"
+
+	var apps []models.App
+	if err := s.db.SelectContext(ctx, &apps, "SELECT "+appColumns+" FROM apps ORDER BY id"); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var uris []struct {
+		AppID int64  `db:"app_id"`
+		URI   string `db:"uri"`
+	}
+	if err := s.db.SelectContext(ctx, &uris, "SELECT app_id, uri FROM app_redirect_uris ORDER BY uri"); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var scopes []struct {
+		AppID int64  `db:"app_id"`
+		Scope string `db:"scope"`
+	}
+	if err := s.db.SelectContext(ctx, &scopes, "SELECT app_id, scope FROM app_scopes ORDER BY scope"); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byID := make(map[int64]*models.App, len(apps))
+	for i := range apps {
+		byID[apps[i].ID] = &apps[i]
+	}
+	for _, u := range uris {
+		if app, ok := byID[u.AppID]; ok {
+			app.RedirectURIs = append(app.RedirectURIs, u.URI)
+		}
+	}
+	for _, sc := range scopes {
+		if app, ok := byID[sc.AppID]; ok {
+			app.Scopes = append(app.Scopes, sc.Scope)
+		}
+	}
+
+	return apps, nil
+}
+
+// UpdateApp replaces the metadata, redirect URIs and scopes of the app.
+// Descriptions of scopes the app keeps are preserved.
+func (s *Storage) UpdateApp(ctx context.Context, app models.App) error {
+	const op = "storage.sqlite.UpdateApp"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE apps
+		SET name = ?2, grant_types = ?3, token_ttl_seconds = ?4, logo_uri = ?5
+		WHERE id = ?1
+	`, app.ID, app.Name, app.GrantTypes, app.TokenTTLSeconds, app.LogoURI)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = appAffected(op, res); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM app_redirect_uris WHERE app_id = ?", app.ID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// SQLite has no arrays, the scopes to keep are sent as a JSON array
+	keep, err := json.Marshal(append([]string{}, app.Scopes...))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM app_scopes WHERE app_id = ? AND scope NOT IN (SELECT value FROM json_each(?))", app.ID, string(keep))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = saveAppSettings(ctx, tx, app); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// saveAppSettings inserts the redirect URIs and scopes of app, skipping
+// the ones it already has.
+func saveAppSettings(ctx context.Context, tx *sqlx.Tx, app models.App) error {
+	for _, uri := range app.RedirectURIs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO app_redirect_uris(app_id, uri)
+			VALUES(?, ?)
+			ON CONFLICT DO NOTHING
+		`, app.ID, uri)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, scope := range app.Scopes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO app_scopes(app_id, scope)
+			VALUES(?, ?)
+			ON CONFLICT DO NOTHING
+		`, app.ID, scope)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetAppSecret replaces the secret of the app. secret is the plain secret
+// kept for HS256 signing, empty to keep only the hash.
+func (s *Storage) SetAppSecret(ctx context.Context, appID int64, secret, secretHash string) error {
+	const op = "storage.sqlite.SetAppSecret"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE apps SET secret = NULLIF(?2, ''), secret_hash = ?3 WHERE id = ?1
+	`, appID, secret, secretHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return appAffected(op, res)
+}
+
+// ClearAppSecrets drops the plain secrets of all apps, which are only
+// needed as signing keys with HS256. The hashes are kept.
+func (s *Storage) ClearAppSecrets(ctx context.Context) (int64, error) {
+	const op = "storage.sqlite.ClearAppSecrets"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET secret = NULL WHERE secret IS NOT NULL")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cleared, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cleared, nil
+}
+
+// DeleteApp deletes the app. Its sessions, codes and settings are deleted
+// by cascade.
+func (s *Storage) DeleteApp(ctx context.Context, appID int64) error {
+	const op = "storage.sqlite.DeleteApp"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM apps WHERE id = ?", appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return appAffected(op, res)
+}
+
+func appAffected(op string, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
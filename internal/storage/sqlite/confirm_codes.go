@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// SaveConfirmationCode saves the code and queues the email with it in one transaction.
+func (s *Storage) SaveConfirmationCode(ctx context.Context, userID int64, code string, email models.OutboxEmail) error {
+	const op = "storage.sqlite.SaveConfirmationCode"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err = saveConfirmationCode(ctx, tx, userID, code, email); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func saveConfirmationCode(ctx context.Context, tx *sqlx.Tx, userID int64, code string, email models.OutboxEmail) error {
+	// location, _ := time.LoadLocation("Asia/Bishkek")
+	// now := time.Now().In(location)
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO email_confirmation(user_id, code)
+		VALUES(?, ?)
+	`, userID, code)
+	if err != nil {
+		return err
+	}
+
+	return enqueueEmail(ctx, tx, email)
+}
+
+// ConfirmationCode returns the latest confirmation code of the user.
+func (s *Storage) ConfirmationCode(ctx context.Context, userID int64) (confCodeModel models.ConfirmCode, err error) {
+	const op = "storage.sqlite.ConfirmationCode"
+
+	confCode := models.ConfirmCode{}
+
+	err = s.db.GetContext(ctx, &confCode, `
+		SELECT ec.id, ec.user_id, code, email, ec.created_at
+		FROM email_confirmation ec
+		INNER JOIN users u ON ec.user_id = u.id
+		WHERE ec.user_id = ?
+		ORDER BY ec.created_at DESC, ec.id DESC
+		LIMIT 1
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ConfirmCode{}, fmt.Errorf("%s: %w", op, storage.ErrConfirmCodeNotFound)
+		}
+		return models.ConfirmCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return confCode, nil
+}
+
+// DeleteConfirmationCode deletes the confirmation codes of the user.
+func (s *Storage) DeleteConfirmationCode(ctx context.Context, user_id int64) error {
+	const op = "storage.sqlite.DeleteConfirmationCode"
+
+	_, err := s.db.ExecContext(ctx, "DELETE FROM email_confirmation WHERE user_id = ?", user_id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
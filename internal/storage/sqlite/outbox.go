@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+const outboxColumns = "id, recipient, subject, html, text_body, status, attempts, last_error, next_attempt_at, sent_at, created_at"
+
+// enqueueEmail adds the email to the outbox in the transaction saving the data it is about.
+func enqueueEmail(ctx context.Context, tx *sqlx.Tx, email models.OutboxEmail) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO email_outbox(recipient, subject, html, text_body, next_attempt_at)
+		VALUES(?, ?, ?, ?, ?)
+	`, email.Recipient, email.Subject, email.HTML, email.Text, time.Now().UTC())
+
+	return err
+}
+
+// ClaimOutboxEmails returns pending emails due for delivery and postpones them
+// by lease, so concurrent workers do not send them twice. Attempts are counted
+// when claimed, so an email crashing the worker ends up dead as well.
+// SQLite runs one write at a time, so the claims of workers do not overlap.
+func (s *Storage) ClaimOutboxEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	const op = "storage.sqlite.ClaimOutboxEmails"
+
+	now := time.Now().UTC()
+
+	var emails []models.OutboxEmail
+	err := s.db.SelectContext(ctx, &emails, `
+		UPDATE email_outbox
+		SET attempts = attempts + 1, next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = 'pending' AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+		)
+		RETURNING `+outboxColumns,
+		now.Add(lease), now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return emails, nil
+}
+
+func (s *Storage) MarkOutboxEmailSent(ctx context.Context, id int64) error {
+	const op = "storage.sqlite.MarkOutboxEmailSent"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE email_outbox
+		SET status = 'sent', sent_at = ?, last_error = ''
+		WHERE id = ?
+	`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkOutboxEmailFailed records a failed delivery. The email is retried at
+// nextAttemptAt, or never again if dead is set.
+func (s *Storage) MarkOutboxEmailFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	const op = "storage.sqlite.MarkOutboxEmailFailed"
+
+	status := models.OutboxPending
+	if dead {
+		status = models.OutboxDead
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE email_outbox
+		SET status = ?, last_error = ?, next_attempt_at = ?
+		WHERE id = ?
+	`, status, lastError, nextAttemptAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// OutboxEmails lists the latest emails, of any status if status is empty.
+func (s *Storage) OutboxEmails(ctx context.Context, status string, limit int) ([]models.OutboxEmail, error) {
+	const op = "storage.sqlite.OutboxEmails"
+
+	var emails []models.OutboxEmail
+	err := s.db.SelectContext(ctx, &emails, `
+		SELECT `+outboxColumns+`
+		FROM email_outbox
+		WHERE ?1 = '' OR status = ?1
+		ORDER BY id DESC
+		LIMIT ?2
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return emails, nil
+}
+
+// RetryOutboxEmail queues an email that was not sent for immediate delivery
+// with a fresh attempt budget.
+func (s *Storage) RetryOutboxEmail(ctx context.Context, id int64) error {
+	const op = "storage.sqlite.RetryOutboxEmail"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE email_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = ?
+		WHERE id = ? AND status <> 'sent'
+	`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrOutboxEmailNotFound)
+	}
+
+	return nil
+}
+
+// DeleteSentOutboxEmails drops emails sent before the given time,
+// they contain confirmation codes.
+func (s *Storage) DeleteSentOutboxEmails(ctx context.Context, sentBefore time.Time) (int64, error) {
+	const op = "storage.sqlite.DeleteSentOutboxEmails"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM email_outbox WHERE status = 'sent' AND sent_at < ?", sentBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	const op = "storage.sqlite.SaveRefreshToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens(user_id, app_id, family_id, token_hash, auth_methods, org_id, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?)
+	`, token.UserID, token.AppID, token.FamilyID, token.TokenHash, token.AuthMethods, token.OrgID, token.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	const op = "storage.sqlite.RefreshToken"
+
+	var token models.RefreshToken
+	err := s.db.GetContext(ctx, &token, `
+		SELECT id, user_id, app_id, family_id, token_hash, auth_methods, org_id, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshToken{}, fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotFound)
+		}
+		return models.RefreshToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken marks the token with oldID as used and saves its successor
+// in one transaction. If the old token was used or revoked concurrently,
+// storage.ErrRefreshTokenNotActive is returned and nothing is saved.
+func (s *Storage) RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error {
+	const op = "storage.sqlite.RotateRefreshToken"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = ?
+		WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL
+	`, time.Now().UTC(), oldID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRefreshTokenNotActive)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens(user_id, app_id, family_id, token_hash, auth_methods, org_id, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?)
+	`, next.UserID, next.AppID, next.FamilyID, next.TokenHash, next.AuthMethods, next.OrgID, next.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	const op = "storage.sqlite.RevokeRefreshTokenFamily"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE family_id = ? AND revoked_at IS NULL
+	`, time.Now().UTC(), familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshTokens deletes refresh tokens expired before the given
+// time, used and revoked ones included.
+func (s *Storage) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) error {
+	const op = "storage.sqlite.DeleteExpiredRefreshTokens"
+
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM refresh_tokens WHERE expires_at < ?
+	`, before.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+)
+
+// WriteRelationTuples inserts and deletes tuples in one transaction. Inserting
+// an existing tuple or deleting a missing one is a no-op.
+func (s *Storage) WriteRelationTuples(ctx context.Context, writes, deletes []models.RelationTuple) error {
+	const op = "storage.sqlite.WriteRelationTuples"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	for _, t := range deletes {
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM relation_tuples
+			WHERE namespace = ? AND object_id = ? AND relation = ?
+				AND subject_namespace = ? AND subject_id = ? AND subject_relation = ?
+		`, t.Namespace, t.ObjectID, t.Relation, t.SubjectNamespace, t.SubjectID, t.SubjectRelation)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	for _, t := range writes {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO relation_tuples(namespace, object_id, relation, subject_namespace, subject_id, subject_relation)
+			VALUES(?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, t.Namespace, t.ObjectID, t.Relation, t.SubjectNamespace, t.SubjectID, t.SubjectRelation)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RelationTuples returns the tuples of the object with the given relation.
+func (s *Storage) RelationTuples(ctx context.Context, namespace, objectID, relation string) ([]models.RelationTuple, error) {
+	const op = "storage.sqlite.RelationTuples"
+
+	var tuples []models.RelationTuple
+	err := s.db.SelectContext(ctx, &tuples, `
+		SELECT id, namespace, object_id, relation, subject_namespace, subject_id, subject_relation, created_at
+		FROM relation_tuples
+		WHERE namespace = ? AND object_id = ? AND relation = ?
+		ORDER BY id
+	`, namespace, objectID, relation)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tuples, nil
+}
+
+// RelationObjectIDs returns the IDs of the objects of the namespace having any
+// tuples, the first limit of them ordered by ID.
+func (s *Storage) RelationObjectIDs(ctx context.Context, namespace string, limit int) ([]string, error) {
+	const op = "storage.sqlite.RelationObjectIDs"
+
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT DISTINCT object_id
+		FROM relation_tuples
+		WHERE namespace = ?
+		ORDER BY object_id
+		LIMIT ?
+	`, namespace, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (s *Storage) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const op = "storage.sqlite.RevokeToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens(jti, expires_at)
+		VALUES(?, ?)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, expiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	const op = "storage.sqlite.IsTokenRevoked"
+
+	var revoked bool
+	err := s.db.GetContext(ctx, &revoked, "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = ?)", jti)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpiredRevokedTokens removes revocation entries of tokens that have
+// expired anyway and returns how many were removed.
+func (s *Storage) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	const op = "storage.sqlite.DeleteExpiredRevokedTokens"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < ?", time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+func (s *Storage) SaveSigningKey(ctx context.Context, key models.SigningKey) error {
+	const op = "storage.sqlite.SaveSigningKey"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO signing_keys(kid, alg, private_key, state)
+		VALUES(?, ?, ?, ?)
+	`, key.KID, key.Alg, key.PrivateKey, key.State)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrSigningKeyExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SigningKeys returns all signing keys, newest first.
+func (s *Storage) SigningKeys(ctx context.Context) ([]models.SigningKey, error) {
+	const op = "storage.sqlite.SigningKeys"
+
+	var keys []models.SigningKey
+	err := s.db.SelectContext(ctx, &keys, `
+		SELECT id, kid, alg, private_key, state, created_at, activated_at, rotated_at, retired_at
+		FROM signing_keys
+		ORDER BY created_at DESC, id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// ActivateSigningKey makes the pending key with the given kid the signing key.
+// The previous signing key stays active for verification, marked as rotated.
+func (s *Storage) ActivateSigningKey(ctx context.Context, kid string) error {
+	const op = "storage.sqlite.ActivateSigningKey"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE signing_keys
+		SET rotated_at = ?
+		WHERE state = 'active' AND rotated_at IS NULL
+	`, now)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE signing_keys
+		SET state = 'active', activated_at = ?
+		WHERE kid = ? AND state = 'pending'
+	`, now, kid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSigningKeyNotFound)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RetireSigningKeys retires keys rotated out before the given time
+// and returns how many were retired.
+func (s *Storage) RetireSigningKeys(ctx context.Context, rotatedBefore time.Time) (int64, error) {
+	const op = "storage.sqlite.RetireSigningKeys"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE signing_keys
+		SET state = 'retired', retired_at = ?
+		WHERE state = 'active' AND rotated_at < ?
+	`, time.Now().UTC(), rotatedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	retired, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return retired, nil
+}
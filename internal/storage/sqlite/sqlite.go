@@ -2,27 +2,61 @@ package sqlite
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/XSAM/otelsql"
+	"github.com/jmoiron/sqlx"
 	"github.com/mattn/go-sqlite3"
-	"github.com/orenvadi/auth-grpc/internal/domain/models"
-	"github.com/orenvadi/auth-grpc/internal/storage"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
+// DriverName is the database/sql driver of the storage. It is the sqlite3
+// driver with the sha256 function the migrations use.
+const DriverName = "sqlite3_sso"
+
+func init() {
+	sql.Register(DriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("sha256", func(data []byte) []byte {
+				sum := sha256.Sum256(data)
+				return sum[:]
+			}, true)
+		},
+	})
+}
+
 type Storage struct {
-	db *sql.DB
+	db *sqlx.DB
 }
 
+// New opens the database file at storagePath, every query is traced with a
+// span. Foreign keys are enforced and transactions take the write lock
+// right away, so concurrent transactions wait for each other instead of
+// failing on upgrade.
 func New(storagePath string) (*Storage, error) {
 	const op = "storage.sqlite.New"
 
-	db, err := sql.Open("sqlite3", storagePath)
+	sep := "?"
+	if strings.Contains(storagePath, "?") {
+		sep = "&"
+	}
+	dsn := storagePath + sep + "_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+
+	sqlDB, err := otelsql.Open(DriverName, dsn, otelsql.WithAttributes(semconv.DBSystemSqlite))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	db := sqlx.NewDb(sqlDB, "sqlite3")
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return &Storage{db: db}, nil
 }
 
@@ -30,118 +64,23 @@ func (s *Storage) Stop() error {
 	return s.db.Close()
 }
 
-// SaveUser saves user to db.
-
-func (s *Storage) SaveUser(ctx context.Context, firstName, lastName, phoneNumber, email string, passwordHash []byte) (uid int64, err error) {
-	const op = "storage.sqlite.SaveUser"
-
-	stmt, err := s.db.Prepare("INSERT INTO users(first_name, last_name, phone_number, email, pass_hash) VALUES(?, ?, ?, ?, ?)")
-	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
-	}
-
-	res, err := stmt.ExecContext(ctx, firstName, lastName, phoneNumber, email, passwordHash)
-	if err != nil {
-		var sqliteErr sqlite3.Error
-		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
-			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
-		}
-
-		return 0, fmt.Errorf("%s: %w", op, err)
-	}
-
-	id, err := res.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
-	}
-
-	return id, nil
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
 }
 
-// User returns user by email.
-func (s *Storage) User(ctx context.Context, email string) (models.User, error) {
-	const op = "storage.sqlite.User"
-
-	stmt, err := s.db.Prepare("SELECT id, email, pass_hash FROM users WHERE email = ?")
-	if err != nil {
-		return models.User{}, fmt.Errorf("%s: %w", op, err)
-	}
-
-	row := stmt.QueryRowContext(ctx, email)
-
-	var user models.User
-	err = row.Scan(&user.ID, &user.Email, &user.PasswordHash)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
-		}
-
-		return models.User{}, fmt.Errorf("%s: %w", op, err)
-	}
-
-	return user, nil
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
 }
 
-//func (s *Storage) SavePermission(ctx context.Context, userID int64, permission models.Permission, appID string) error {
-//	const op = "storage.sqlite.SavePermission"
-//
-//	stmt, err := s.db.Prepare("INSERT INTO permissions(user_id, permission, app_id) VALUES(?, ?, ?)")
-//	if err != nil {
-//		return fmt.Errorf("%s: %w", op, err)
-//	}
-//
-//	_, err = stmt.ExecContext(ctx, userID, permission, appID)
-//	if err != nil {
-//		return fmt.Errorf("%s: %w", op, err)
-//	}
-//
-//	return nil
-//}
-
-// App returns app by id.
-func (s *Storage) App(ctx context.Context, id int) (models.App, error) {
-	const op = "storage.sqlite.App"
-
-	stmt, err := s.db.Prepare("SELECT id, name, secret FROM apps WHERE id = ?")
-	if err != nil {
-		return models.App{}, fmt.Errorf("%s: %w", op, err)
-	}
-
-	row := stmt.QueryRowContext(ctx, id)
-
-	var app models.App
-	err = row.Scan(&app.ID, &app.Name, &app.Secret)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
-		}
-
-		return models.App{}, fmt.Errorf("%s: %w", op, err)
-	}
-
-	return app, nil
+// Ping checks that the database is reachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
 }
 
-func (s *Storage) IsAdmin(ctx context.Context, userID int64) (bool, error) {
-	const op = "storage.sqlite.IsAdmin"
-
-	stmt, err := s.db.Prepare("SELECT is_admin FROM users WHERE id = ?")
-	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
-	}
-
-	row := stmt.QueryRowContext(ctx, userID)
-
-	var isAdmin bool
-
-	err = row.Scan(&isAdmin)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
-		}
-
-		return false, fmt.Errorf("%s: %w", op, err)
-	}
-
-	return isAdmin, nil
+// Stats returns the statistics of the connection pool.
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
 }
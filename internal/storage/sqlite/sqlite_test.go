@@ -0,0 +1,55 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"github.com/orenvadi/auth-grpc/internal/storage/sqlite"
+	"github.com/orenvadi/auth-grpc/internal/storage/storagetest"
+)
+
+const migrationsPath = "../../../migrations/sqlite"
+
+func TestStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storagetest.db")
+	migrateUp(t, path)
+
+	s, err := sqlite.New(path)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { s.Stop() })
+
+	storagetest.Run(t, s)
+}
+
+// migrateUp migrates through the driver of the storage, the migrations use
+// the functions it registers.
+func migrateUp(t *testing.T, path string) {
+	t.Helper()
+
+	db, err := sql.Open(sqlite.DriverName, path)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://"+migrationsPath, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	defer m.Close()
+
+	if err = m.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// SaveUser saves the user together with the email confirmation code and
+// queues the email with the code in one transaction.
+func (s *Storage) SaveUser(ctx context.Context, user models.User, confirmCode string, confirmEmail models.OutboxEmail) (uid int64, err error) {
+	const op = "storage.sqlite.SaveUser"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users(first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, locale)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, user.FirstName, user.LastName, user.PhoneNumber, now, now, user.Email, user.PasswordHash, user.Locale).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = saveConfirmationCode(ctx, tx, id, confirmCode, confirmEmail); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+const userColumns = "id, first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, is_admin, is_email_confirmed, locale, disabled_at"
+
+func (s *Storage) User(ctx context.Context, email string) (models.User, error) {
+	const op = "storage.sqlite.User"
+
+	var user models.User
+	err := s.db.GetContext(ctx, &user, "SELECT id, first_name, email, pass_hash, locale, disabled_at FROM users WHERE email = ?", email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (s *Storage) UserAllData(ctx context.Context, uid int64) (models.User, error) {
+	const op = "storage.sqlite.UserAllData"
+
+	var user models.User
+	err := s.db.GetContext(ctx, &user, "SELECT "+userColumns+" FROM users WHERE id = ?", uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, user models.User) error {
+	const op = "storage.sqlite.UpdateUser"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE users
+		SET first_name = ?, last_name = ?, phone_number = ?, email = ?, locale = ?
+		WHERE id = ?
+	`, user.FirstName, user.LastName, user.PhoneNumber, user.Email, user.Locale, user.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) UserEmailConfirm(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.UserEmailConfirm"
+
+	_, err := s.db.ExecContext(ctx, "UPDATE users SET is_email_confirmed = TRUE WHERE id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.DeleteUser"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+// Users returns the users matching the filter ordered by ID.
+func (s *Storage) Users(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	const op = "storage.sqlite.Users"
+
+	query := "SELECT " + userColumns + " FROM users WHERE id > ?"
+	args := []any{filter.AfterID}
+
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		query += " AND " + cond
+	}
+
+	if filter.EmailPrefix != "" {
+		where(`lower(email) LIKE ? ESCAPE '\'`, escapeLike(strings.ToLower(filter.EmailPrefix))+"%")
+	}
+	if filter.Confirmed != nil {
+		where("is_email_confirmed = ?", *filter.Confirmed)
+	}
+	if filter.Admin != nil {
+		where("is_admin = ?", *filter.Admin)
+	}
+	if filter.CreatedAfter != nil {
+		where("created_at >= ?", filter.CreatedAfter.UTC())
+	}
+	if filter.CreatedBefore != nil {
+		where("created_at < ?", filter.CreatedBefore.UTC())
+	}
+
+	args = append(args, filter.Limit)
+	query += " ORDER BY id LIMIT ?"
+
+	var users []models.User
+	if err := s.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// DisableUser disables the user and revokes the refresh tokens of all sessions.
+func (s *Storage) DisableUser(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.DisableUser"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET disabled_at = COALESCE(disabled_at, ?) WHERE id = ?", now, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = userAffected(op, res); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE user_id = ? AND revoked_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) EnableUser(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.EnableUser"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET disabled_at = NULL WHERE id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+func (s *Storage) SetAdmin(ctx context.Context, userID int64, isAdmin bool) error {
+	const op = "storage.sqlite.SetAdmin"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET is_admin = ? WHERE id = ?", isAdmin, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+// userAffected returns storage.ErrUserNotFound if the statement changed no user.
+func userAffected(op string, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (s *Storage) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	const op = "storage.sqlite.IsAdmin"
+
+	var isAdmin bool
+	// disabled admins lose their permissions
+	err := s.db.GetContext(ctx, &isAdmin, "SELECT is_admin AND disabled_at IS NULL FROM users WHERE id = ?", userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return isAdmin, nil
+}
+
+func (s *Storage) ChangePassword(ctx context.Context, email string, newPasswordHash []byte) error {
+	const op = "storage.sqlite.ChangePassword"
+
+	_, err := s.db.ExecContext(ctx, "UPDATE users SET pass_hash = ? WHERE email = ?", newPasswordHash, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) IsEmailConfirmed(ctx context.Context, email string) (bool, error) {
+	const op = "storage.sqlite.IsEmailConfirmed"
+
+	var isConfirmed bool
+	err := s.db.GetContext(ctx, &isConfirmed, "SELECT is_email_confirmed FROM users WHERE email = ?", email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return isConfirmed, nil
+}
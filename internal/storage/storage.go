@@ -3,8 +3,32 @@ package storage
 import "errors"
 
 var (
-	ErrUserExists          = errors.New("user already exists")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrAppNotFound         = errors.New("app not found")
-	ErrConfirmCodeNotFound = errors.New("confirm code not found")
+	ErrUserExists              = errors.New("user already exists")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrAppNotFound             = errors.New("app not found")
+	ErrAppExists               = errors.New("app already exists")
+	ErrConfirmCodeNotFound     = errors.New("confirm code not found")
+	ErrRefreshTokenNotFound    = errors.New("refresh token not found")
+	ErrRefreshTokenNotActive   = errors.New("refresh token already used or revoked")
+	ErrSigningKeyExists        = errors.New("signing key already exists")
+	ErrSigningKeyNotFound      = errors.New("signing key not found")
+	ErrAuthCodeNotFound        = errors.New("authorization code not found")
+	ErrAuthCodeUsed            = errors.New("authorization code already used")
+	ErrTOTPNotFound            = errors.New("totp enrollment not found")
+	ErrTOTPAlreadyConfirmed    = errors.New("totp already confirmed")
+	ErrTOTPStepUsed            = errors.New("totp code already used")
+	ErrRecoveryCodeNotFound    = errors.New("recovery code not found or used")
+	ErrCredentialExists        = errors.New("webauthn credential already exists")
+	ErrCredentialNotFound      = errors.New("webauthn credential not found")
+	ErrWebAuthnSessionNotFound = errors.New("webauthn session not found")
+	ErrOutboxEmailNotFound     = errors.New("outbox email not found")
+	ErrRoleExists              = errors.New("role already exists")
+	ErrRoleNotFound            = errors.New("role not found")
+	ErrPermissionExists        = errors.New("permission already exists")
+	ErrPermissionNotFound      = errors.New("permission not found")
+	ErrOrgExists               = errors.New("organization already exists")
+	ErrOrgNotFound             = errors.New("organization not found")
+	ErrOrgMemberNotFound       = errors.New("organization member not found")
+	ErrOrgInvitationNotFound   = errors.New("organization invitation not found or accepted")
+	ErrLastOrgOwner            = errors.New("organization must keep an owner")
 )
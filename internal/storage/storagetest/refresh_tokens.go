@@ -0,0 +1,83 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// testAppID is the app the migrations create.
+const testAppID = 1
+
+// testRefreshTokens checks that tokens rotate once, that revoking a family
+// stops its rotation, and that only expired tokens are purged.
+func testRefreshTokens(t *testing.T, s Storage, prefix string) {
+	ctx := context.Background()
+	user := saveUser(t, s, prefix, "100007")
+
+	token := func(name string, expiresAt time.Time) models.RefreshToken {
+		return models.RefreshToken{
+			UserID:      user.ID,
+			AppID:       testAppID,
+			FamilyID:    prefix,
+			TokenHash:   prefix + "-" + name,
+			AuthMethods: "pwd",
+			ExpiresAt:   expiresAt,
+		}
+	}
+
+	lookup := func(name string) models.RefreshToken {
+		t.Helper()
+
+		stored, err := s.RefreshToken(ctx, prefix+"-"+name)
+		if err != nil {
+			t.Fatalf("refresh token %s: %v", name, err)
+		}
+
+		return stored
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	if err := s.SaveRefreshToken(ctx, token("first", expiresAt)); err != nil {
+		t.Fatalf("save refresh token: %v", err)
+	}
+	first := lookup("first")
+
+	if err := s.RotateRefreshToken(ctx, first.ID, token("second", expiresAt)); err != nil {
+		t.Fatalf("rotate refresh token: %v", err)
+	}
+	if lookup("first").UsedAt == nil {
+		t.Errorf("rotated token is not marked as used")
+	}
+
+	err := s.RotateRefreshToken(ctx, first.ID, token("concurrent", expiresAt))
+	wantErr(t, "rotate used token", err, storage.ErrRefreshTokenNotActive)
+	_, err = s.RefreshToken(ctx, prefix+"-concurrent")
+	wantErr(t, "token of failed rotation", err, storage.ErrRefreshTokenNotFound)
+
+	if err = s.RevokeRefreshTokenFamily(ctx, prefix); err != nil {
+		t.Fatalf("revoke family: %v", err)
+	}
+	second := lookup("second")
+	if second.RevokedAt == nil {
+		t.Errorf("token of revoked family is not revoked")
+	}
+
+	err = s.RotateRefreshToken(ctx, second.ID, token("third", expiresAt))
+	wantErr(t, "rotate revoked token", err, storage.ErrRefreshTokenNotActive)
+
+	if err = s.SaveRefreshToken(ctx, token("expired", time.Now().Add(-time.Minute))); err != nil {
+		t.Fatalf("save expired refresh token: %v", err)
+	}
+	if err = s.DeleteExpiredRefreshTokens(ctx, time.Now()); err != nil {
+		t.Fatalf("delete expired refresh tokens: %v", err)
+	}
+
+	_, err = s.RefreshToken(ctx, prefix+"-expired")
+	wantErr(t, "purged token", err, storage.ErrRefreshTokenNotFound)
+	lookup("second")
+}
@@ -0,0 +1,98 @@
+// Package storagetest is the behavior every storage backend must share:
+// the errors of duplicates and missing rows, the lifecycle of confirmation
+// codes, password changes and refresh tokens. The tests of each backend run
+// the suite.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/domain/models"
+	"github.com/orenvadi/auth-grpc/internal/services/auth"
+)
+
+// Storage is the storage of users and sessions the auth service needs.
+type Storage interface {
+	auth.UserSaver
+	auth.UserProvider
+	auth.UserUpdater
+	auth.EmailConfirmProvider
+	auth.PasswordResetter
+	auth.RefreshTokenProvider
+}
+
+// missingID is the ID of a user no storage has.
+const missingID = math.MaxInt64
+
+type testCase struct {
+	name string
+	run  func(t *testing.T, s Storage, prefix string)
+}
+
+var testCases = []testCase{
+	{"duplicates", testDuplicates},
+	{"not found", testNotFound},
+	{"confirmation codes", testConfirmationCodes},
+	{"password change", testPasswordChange},
+	{"refresh tokens", testRefreshTokens},
+}
+
+// Run runs the suite against s, every case as a subtest of t. The data of
+// every run is unique, so a shared database can be used, but it is not
+// deleted afterwards.
+func Run(t *testing.T, s Storage) {
+	run := time.Now().UnixNano()
+
+	for i, tc := range testCases {
+		prefix := fmt.Sprintf("storagetest-%d-%d", run, i)
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, s, prefix)
+		})
+	}
+}
+
+// saveUser saves a user with the email prefix@example.com.
+func saveUser(t *testing.T, s Storage, prefix, confirmCode string) models.User {
+	t.Helper()
+
+	user := models.User{
+		FirstName:    "Test",
+		LastName:     "User",
+		PhoneNumber:  "+996700000000",
+		Email:        prefix + "@example.com",
+		PasswordHash: []byte("hash of " + prefix),
+		Locale:       "en",
+	}
+
+	id, err := s.SaveUser(context.Background(), user, confirmCode, confirmEmail(user.Email))
+	if err != nil {
+		t.Fatalf("save user %s: %v", user.Email, err)
+	}
+	user.ID = id
+
+	return user
+}
+
+func confirmEmail(recipient string) models.OutboxEmail {
+	return models.OutboxEmail{
+		Recipient: recipient,
+		Subject:   "Confirm your email",
+		HTML:      "<p>storagetest</p>",
+		Text:      "storagetest",
+	}
+}
+
+// wantErr checks that err is target.
+func wantErr(t *testing.T, what string, err, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Errorf("%s: got error %v, want %v", what, err, target)
+	}
+}
@@ -0,0 +1,160 @@
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/orenvadi/auth-grpc/internal/storage"
+)
+
+// testDuplicates checks that emails are unique on save and update.
+func testDuplicates(t *testing.T, s Storage, prefix string) {
+	ctx := context.Background()
+
+	first := saveUser(t, s, prefix+"-first", "100001")
+
+	_, err := s.SaveUser(ctx, first, "100002", confirmEmail(first.Email))
+	wantErr(t, "save user with taken email", err, storage.ErrUserExists)
+
+	second := saveUser(t, s, prefix+"-second", "100003")
+
+	update := second
+	update.Email = first.Email
+	err = s.UpdateUser(ctx, update)
+	wantErr(t, "update user to taken email", err, storage.ErrUserExists)
+
+	stored, err := s.UserAllData(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("user data: %v", err)
+	}
+	if stored.Email != second.Email {
+		t.Errorf("email changed by failed update: got %q, want %q", stored.Email, second.Email)
+	}
+}
+
+// testNotFound checks the errors for users and codes that do not exist.
+func testNotFound(t *testing.T, s Storage, prefix string) {
+	ctx := context.Background()
+	email := prefix + "-missing@example.com"
+
+	_, err := s.User(ctx, email)
+	wantErr(t, "user", err, storage.ErrUserNotFound)
+
+	_, err = s.UserAllData(ctx, missingID)
+	wantErr(t, "user data", err, storage.ErrUserNotFound)
+
+	_, err = s.IsAdmin(ctx, missingID)
+	wantErr(t, "is admin", err, storage.ErrUserNotFound)
+
+	_, err = s.IsEmailConfirmed(ctx, email)
+	wantErr(t, "is email confirmed", err, storage.ErrUserNotFound)
+
+	_, err = s.ConfirmationCode(ctx, missingID)
+	wantErr(t, "confirmation code", err, storage.ErrConfirmCodeNotFound)
+}
+
+// testConfirmationCodes checks that the latest code of a user is returned
+// until the codes of the user are deleted, and the confirmation of the email.
+func testConfirmationCodes(t *testing.T, s Storage, prefix string) {
+	ctx := context.Background()
+
+	user := saveUser(t, s, prefix+"-user", "200001")
+	other := saveUser(t, s, prefix+"-other", "200002")
+
+	checkCode(t, s, user.ID, "200001", user.Email)
+
+	if err := s.SaveConfirmationCode(ctx, user.ID, "200003", confirmEmail(user.Email)); err != nil {
+		t.Fatalf("save confirmation code: %v", err)
+	}
+
+	checkCode(t, s, user.ID, "200003", user.Email)
+
+	confirmed, err := s.IsEmailConfirmed(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("is email confirmed: %v", err)
+	}
+	if confirmed {
+		t.Error("email of a new user is confirmed")
+	}
+
+	if err = s.UserEmailConfirm(ctx, user.ID); err != nil {
+		t.Fatalf("confirm email: %v", err)
+	}
+
+	confirmed, err = s.IsEmailConfirmed(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("is email confirmed: %v", err)
+	}
+	if !confirmed {
+		t.Error("email is not confirmed after confirmation")
+	}
+
+	if err = s.DeleteConfirmationCode(ctx, user.ID); err != nil {
+		t.Fatalf("delete confirmation code: %v", err)
+	}
+
+	_, err = s.ConfirmationCode(ctx, user.ID)
+	wantErr(t, "confirmation code after delete", err, storage.ErrConfirmCodeNotFound)
+
+	// the codes of other users are kept
+	checkCode(t, s, other.ID, "200002", other.Email)
+}
+
+// checkCode checks that the latest code of the user is code. The code must
+// be fresh, the auth service rejects codes older than a few minutes.
+func checkCode(t *testing.T, s Storage, userID int64, code, email string) {
+	t.Helper()
+
+	got, err := s.ConfirmationCode(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("confirmation code: %v", err)
+	}
+
+	if got.Code != code {
+		t.Errorf("confirmation code: got %q, want %q", got.Code, code)
+	}
+	if got.UserID != userID {
+		t.Errorf("confirmation code user: got %d, want %d", got.UserID, userID)
+	}
+	if got.Email != email {
+		t.Errorf("confirmation code email: got %q, want %q", got.Email, email)
+	}
+
+	if age := time.Since(got.CreatedAt); age < -time.Minute || age > time.Minute {
+		t.Errorf("confirmation code created %s ago, the time zone of the storage is wrong", age)
+	}
+}
+
+// testPasswordChange checks that the password of only the given user changes.
+func testPasswordChange(t *testing.T, s Storage, prefix string) {
+	ctx := context.Background()
+
+	user := saveUser(t, s, prefix+"-user", "300001")
+	other := saveUser(t, s, prefix+"-other", "300002")
+
+	newHash := []byte("new hash of " + prefix)
+	if err := s.ChangePassword(ctx, user.Email, newHash); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+
+	stored, err := s.User(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	if stored.ID != user.ID {
+		t.Errorf("user ID: got %d, want %d", stored.ID, user.ID)
+	}
+	if !bytes.Equal(stored.PasswordHash, newHash) {
+		t.Errorf("password hash: got %q, want %q", stored.PasswordHash, newHash)
+	}
+
+	stored, err = s.User(ctx, other.Email)
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	if !bytes.Equal(stored.PasswordHash, other.PasswordHash) {
+		t.Error("password of another user changed")
+	}
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS app_scopes (
+    app_id INT NOT NULL,
+    scope TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (app_id, scope),
+    FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials (user_id);
+
+-- challenges of registration and login ceremonies in progress
+CREATE TABLE IF NOT EXISTS webauthn_sessions (
+    id TEXT PRIMARY KEY,
+    user_id INT,
+    app_id INT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('registration', 'login')),
+    data JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+
+-- ListUsers pages by id, filtering by email prefix
+CREATE INDEX IF NOT EXISTS users_lower_email_idx ON users (lower(email) text_pattern_ops);
@@ -0,0 +1,11 @@
+-- apps without a plain secret can not authenticate after the downgrade
+-- until their secret is rotated
+UPDATE apps SET secret = secret_hash WHERE secret IS NULL;
+ALTER TABLE apps ALTER COLUMN secret SET NOT NULL;
+
+ALTER TABLE apps
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS logo_uri,
+    DROP COLUMN IF EXISTS token_ttl_seconds,
+    DROP COLUMN IF EXISTS grant_types,
+    DROP COLUMN IF EXISTS secret_hash;
@@ -0,0 +1,13 @@
+ALTER TABLE apps
+    ADD COLUMN IF NOT EXISTS secret_hash TEXT NOT NULL DEFAULT '',
+    -- space separated OAuth 2.0 grant types the app may use
+    ADD COLUMN IF NOT EXISTS grant_types TEXT NOT NULL DEFAULT 'authorization_code refresh_token client_credentials',
+    -- overrides TOKEN_TTL for access tokens of the app, 0 keeps the default
+    ADD COLUMN IF NOT EXISTS token_ttl_seconds BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS logo_uri TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE apps SET secret_hash = encode(sha256(convert_to(secret, 'UTF8')), 'hex') WHERE secret_hash = '';
+
+-- the plain secret is kept only as the signing key when SIGNING_ALG is HS256
+ALTER TABLE apps ALTER COLUMN secret DROP NOT NULL;
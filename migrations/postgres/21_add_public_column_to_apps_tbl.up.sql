@@ -0,0 +1,2 @@
+-- public clients, e.g. SPAs and mobile apps, have no secret and must use PKCE
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS public BOOLEAN NOT NULL DEFAULT FALSE;
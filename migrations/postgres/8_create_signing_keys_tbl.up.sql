@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id SERIAL PRIMARY KEY,
+    kid TEXT NOT NULL UNIQUE,
+    alg TEXT NOT NULL,
+    private_key BYTEA NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'active', 'retired')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    activated_at TIMESTAMPTZ,
+    rotated_at TIMESTAMPTZ,
+    retired_at TIMESTAMPTZ
+);
+
+-- at most one key waits for activation at a time, so concurrent rotations do not pile up keys
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_single_pending ON signing_keys (state) WHERE state = 'pending';
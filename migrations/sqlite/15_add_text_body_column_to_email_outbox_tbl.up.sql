@@ -0,0 +1,2 @@
+-- plain text alternative of the HTML body
+ALTER TABLE email_outbox ADD COLUMN text_body TEXT NOT NULL DEFAULT '';
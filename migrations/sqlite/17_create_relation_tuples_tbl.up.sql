@@ -0,0 +1,14 @@
+-- relation tuples object#relation@subject, the subject is an object or a userset object#relation
+CREATE TABLE IF NOT EXISTS relation_tuples (
+    id INTEGER PRIMARY KEY,
+    namespace TEXT NOT NULL,
+    object_id TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    subject_namespace TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    subject_relation TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (namespace, object_id, relation, subject_namespace, subject_id, subject_relation)
+);
+
+CREATE INDEX IF NOT EXISTS relation_tuples_subject_idx ON relation_tuples (subject_namespace, subject_id, subject_relation);
@@ -0,0 +1,27 @@
+-- SQLite can not drop a column with a foreign key, the table is rebuilt without it
+CREATE TABLE refresh_tokens_old (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    app_id INTEGER NOT NULL,
+    family_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    auth_methods TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+);
+
+INSERT INTO refresh_tokens_old(id, user_id, app_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at, auth_methods)
+SELECT id, user_id, app_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at, auth_methods
+FROM refresh_tokens;
+
+DROP TABLE refresh_tokens;
+ALTER TABLE refresh_tokens_old RENAME TO refresh_tokens;
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+DROP TABLE IF EXISTS organization_invitations;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_lower_email_idx;
+ALTER TABLE users DROP COLUMN disabled_at;
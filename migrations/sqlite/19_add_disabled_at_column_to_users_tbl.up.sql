@@ -0,0 +1,28 @@
+-- created_at and updated_at of the initial table are TEXT, which the driver
+-- does not read as time, so the table is rebuilt with TIMESTAMP columns
+CREATE TABLE users_new
+(
+    id                 INTEGER PRIMARY KEY,
+    first_name         TEXT NOT NULL,
+    last_name          TEXT NOT NULL,
+    phone_number       TEXT NOT NULL,
+    created_at         TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    email              TEXT NOT NULL UNIQUE,
+    pass_hash          BLOB NOT NULL,
+    is_admin           BOOLEAN NOT NULL DEFAULT FALSE,
+    is_email_confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    locale             TEXT NOT NULL DEFAULT 'en',
+    disabled_at        TIMESTAMP
+);
+
+INSERT INTO users_new(id, first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, is_admin, is_email_confirmed, locale)
+SELECT id, first_name, last_name, phone_number, created_at, updated_at, email, pass_hash, is_admin, is_email_confirmed, locale
+FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+CREATE INDEX IF NOT EXISTS idx_email ON users (email);
+
+-- ListUsers pages by id, filtering by email prefix
+CREATE INDEX IF NOT EXISTS users_lower_email_idx ON users (lower(email));
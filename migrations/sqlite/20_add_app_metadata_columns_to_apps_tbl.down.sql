@@ -0,0 +1,15 @@
+-- apps without a plain secret can not authenticate after the downgrade
+-- until their secret is rotated
+CREATE TABLE apps_old
+(
+    id     INTEGER PRIMARY KEY,
+    name   TEXT NOT NULL UNIQUE,
+    secret TEXT NOT NULL UNIQUE
+);
+
+INSERT INTO apps_old(id, name, secret)
+SELECT id, name, COALESCE(secret, secret_hash)
+FROM apps;
+
+DROP TABLE apps;
+ALTER TABLE apps_old RENAME TO apps;
@@ -0,0 +1,23 @@
+-- the plain secret is kept only as the signing key when SIGNING_ALG is HS256,
+-- SQLite can not drop NOT NULL of a column, so the table is rebuilt
+CREATE TABLE apps_new
+(
+    id                INTEGER PRIMARY KEY,
+    name              TEXT NOT NULL UNIQUE,
+    secret            TEXT UNIQUE,
+    secret_hash       TEXT NOT NULL DEFAULT '',
+    -- space separated OAuth 2.0 grant types the app may use
+    grant_types       TEXT NOT NULL DEFAULT 'authorization_code refresh_token client_credentials',
+    -- overrides TOKEN_TTL for access tokens of the app, 0 keeps the default
+    token_ttl_seconds INTEGER NOT NULL DEFAULT 0,
+    logo_uri          TEXT NOT NULL DEFAULT '',
+    created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- sha256 is registered by the sqlite storage driver the migrator uses
+INSERT INTO apps_new(id, name, secret, secret_hash)
+SELECT id, name, secret, lower(hex(sha256(secret)))
+FROM apps;
+
+DROP TABLE apps;
+ALTER TABLE apps_new RENAME TO apps;
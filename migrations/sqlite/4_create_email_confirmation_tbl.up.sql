@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS email_confirmation (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    code TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN is_email_confirmed BOOLEAN NOT NULL DEFAULT FALSE;